@@ -7,10 +7,16 @@ import (
 	"testing"
 
 	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/reporters"
 	"github.com/onsi/ginkgo/v2/types"
 
 	"github.com/onsi/gomega"
 
+	kubeCl "github.com/konflux-ci/e2e-tests/pkg/clients/kubernetes"
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/framework"
+	"github.com/konflux-ci/e2e-tests/pkg/mustgather"
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
 	_ "github.com/konflux-ci/e2e-tests/tests/build"
 	_ "github.com/konflux-ci/e2e-tests/tests/disaster-recovery"
 	_ "github.com/konflux-ci/e2e-tests/tests/enterprise-contract"
@@ -41,6 +47,38 @@ func init() {
 	}
 }
 
+// provision the pooled stage users once per suite, on the first process, before the frameworks lease them.
+// The users failing to be provisioned are retried when they are leased, they don't fail the suite.
+var _ = ginkgo.SynchronizedBeforeSuite(func() {
+	if os.Getenv(constants.SANDBOX_USER_POOL_SIZE_ENV) == "" {
+		return
+	}
+	stageOptions := utils.Options{
+		ApiUrl: os.Getenv(constants.TOOLCHAIN_API_URL_ENV),
+		Token:  os.Getenv(constants.OFFLINE_TOKEN_ENV),
+	}
+	if err := framework.PrepareSandboxUserPool(stageOptions); err != nil {
+		klog.Errorf("failed to prepare the sandbox user pool: %v", err)
+	}
+}, func() {})
+
+// snapshot the cluster state when the suite failed, the per-spec artifacts don't cover the failures of the whole suite
+var _ = ginkgo.ReportAfterSuite("cluster snapshot", func(report ginkgo.Report) {
+	if report.SuiteSucceeded || report.SuiteConfig.DryRun {
+		return
+	}
+	client, err := kubeCl.NewAdminKubernetesClient()
+	if err != nil {
+		klog.Errorf("failed to create the client for the cluster snapshot: %v", err)
+		return
+	}
+	wd, _ := os.Getwd()
+	artifactDir := utils.GetEnv("ARTIFACT_DIR", fmt.Sprintf("%s/tmp", wd))
+	if _, err := mustgather.StoreClusterSnapshot(artifactDir, client.KubeInterface(), client.DynamicClient()); err != nil {
+		klog.Errorf("failed to store the cluster snapshot: %v", err)
+	}
+})
+
 func TestE2E(t *testing.T) {
 	klog.Info("Starting Red Hat App Studio e2e tests...")
 	gomega.RegisterFailHandler(ginkgo.Fail)
@@ -51,6 +89,13 @@ func TestE2E(t *testing.T) {
 	if config.DryRun {
 		reports := ginkgo.PreviewSpecs("Red Hat App Studio E2E tests")
 
+		// the inventory of the specs, including the ones filtered out, reconciled with the reports of the runs
+		if inventory := os.Getenv("E2E_SPECS_INVENTORY"); inventory != "" {
+			if err := reporters.GenerateJSONReport(reports, inventory); err != nil {
+				t.Fatalf("failed to write the specs inventory %s: %v", inventory, err)
+			}
+		}
+
 		for _, spec := range reports.SpecReports {
 			if spec.State.Is(types.SpecStatePassed) {
 
@@ -0,0 +1,47 @@
+// pyxis-stub serves the in-memory Pyxis stand-in from pkg/utils/pyxis.
+// It is shipped in the e2e-tests image so that it can be deployed into the test cluster with pyxis.Deploy.
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/konflux-ci/e2e-tests/pkg/utils/pyxis"
+	"k8s.io/klog/v2"
+)
+
+func main() {
+	addr := flag.String("addr", ":8443", "address to listen on")
+	tlsCert := flag.String("tls-cert", "", "path to the PEM encoded server certificate, serves plain HTTP when empty")
+	tlsKey := flag.String("tls-key", "", "path to the PEM encoded server key")
+	clientCA := flag.String("client-ca", "", "path to the PEM encoded CA used to verify client certificates, enables mTLS")
+	flag.Parse()
+
+	options := pyxis.Options{}
+	for path, dest := range map[string]*[]byte{*tlsCert: &options.ServerCert, *tlsKey: &options.ServerKey, *clientCA: &options.ClientCA} {
+		if path == "" {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			klog.Fatalf("failed to read %s: %v", path, err)
+		}
+		*dest = content
+	}
+
+	server := pyxis.NewServer(options)
+	if err := server.Start(*addr); err != nil {
+		klog.Fatalf("failed to start Pyxis stand-in: %v", err)
+	}
+	klog.Infof("Pyxis stand-in listening on %s", server.URL())
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	<-sig
+
+	if err := server.Close(); err != nil {
+		klog.Errorf("failed to stop Pyxis stand-in: %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -25,21 +26,29 @@ import (
 	gh "github.com/google/go-github/v66/github"
 	"github.com/konflux-ci/e2e-tests/magefiles/installation"
 	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
+	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine/declarative"
 	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine/engine"
 	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine/repos"
 	"github.com/konflux-ci/e2e-tests/magefiles/upgrade"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/github"
 	forgejoClient "github.com/konflux-ci/e2e-tests/pkg/clients/forgejo"
+	kubeCl "github.com/konflux-ci/e2e-tests/pkg/clients/kubernetes"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/gitlab"
+	"github.com/konflux-ci/e2e-tests/pkg/clients/oras"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/slack"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/sprayproxy"
 	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/framework"
+	"github.com/konflux-ci/e2e-tests/pkg/logs"
+	"github.com/konflux-ci/e2e-tests/pkg/mustgather"
 	"github.com/konflux-ci/e2e-tests/pkg/testspecs"
 	"github.com/konflux-ci/e2e-tests/pkg/utils"
 	"github.com/konflux-ci/e2e-tests/pkg/utils/build"
 	"github.com/konflux-ci/e2e-tests/pkg/utils/tekton"
 	"github.com/konflux-ci/image-controller/pkg/quay"
 	"github.com/magefile/mage/sh"
+	"github.com/onsi/ginkgo/v2/reporters"
+	"github.com/onsi/ginkgo/v2/types"
 	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	gl "github.com/xanzy/go-gitlab"
 )
@@ -196,6 +205,76 @@ func (Local) TestE2E() error {
 	return RunE2ETests()
 }
 
+// Reruns only the failed specs of a previous ginkgo JSON report, i.e. e2e-report.json, with its label filter.
+// The specs are focused by their locations, their texts can be generated at runtime, so the report must come
+// from the same checkout of the tests. The specs are run repeat times to confirm their flakiness, together with the
+// other specs of their Ordered containers when orderedSiblings is set. The reruns are merged into e2e-rerun-report.json
+// in ARTIFACT_DIR.
+func (Local) RerunFailedSpecs(report string, repeat int, orderedSiblings bool) error {
+
+	reports, err := testspecs.LoadGinkgoReports(report)
+	if err != nil {
+		return err
+	}
+	if len(reports) != 1 {
+		return fmt.Errorf("expected a single suite in %s, got %d", report, len(reports))
+	}
+	previous := reports[0]
+
+	failed := testspecs.FailedSpecs(previous, orderedSiblings)
+	if len(failed) == 0 {
+		klog.Infof("no failed spec to rerun in %s", report)
+		return nil
+	}
+	focusFiles, err := testspecs.FocusFiles(failed)
+	if err != nil {
+		return err
+	}
+	klog.Infof("rerunning %d specs %d times with the focus files %q", len(failed), max(repeat, 1), focusFiles)
+	var focusArgs []string
+	for _, focusFile := range focusFiles {
+		focusArgs = append(focusArgs, "--focus-file="+focusFile)
+	}
+
+	var reruns []types.Report
+	for i := 1; i <= max(repeat, 1); i++ {
+		jsonReport := fmt.Sprintf("e2e-rerun-%d-report.json", i)
+		// the failures are merged into the combined report below
+		if err := runTestsWithReports(previous.SuiteConfig.LabelFilter, fmt.Sprintf("e2e-rerun-%d-report.xml", i), jsonReport, "90m", focusArgs...); err != nil {
+			klog.Warningf("rerun %d failed: %s", i, err)
+		}
+		rerun, err := testspecs.LoadGinkgoReports(filepath.Join(artifactDir, jsonReport))
+		if err != nil {
+			return fmt.Errorf("failed to read the report of rerun %d: %w", i, err)
+		}
+		reruns = append(reruns, rerun...)
+	}
+
+	merged, results := testspecs.MergeRerunReports(previous, reruns)
+	notRerun := 0
+	for _, result := range results {
+		verdict := "still failing"
+		switch {
+		case result.NotRerun:
+			verdict = "not rerun"
+			notRerun++
+		case result.Flaky:
+			verdict = "flaky"
+		}
+		klog.Infof("%s: %s [%s]", verdict, result.Spec, strings.Join(result.States, ", "))
+	}
+	if err := reporters.GenerateJSONReport(merged, filepath.Join(artifactDir, "e2e-rerun-report.json")); err != nil {
+		return err
+	}
+	if notRerun != 0 {
+		return fmt.Errorf("%d failed specs were not selected by the focus files %q of the reruns", notRerun, focusFiles)
+	}
+	if !merged.SuiteSucceeded {
+		return fmt.Errorf("failed specs are still failing after %d reruns", max(repeat, 1))
+	}
+	return nil
+}
+
 // Deletes autogenerated or test generated repositories from redhat-appstudio-qe Github org.
 // Env vars to configure this target: REPO_REGEX (optional), DRY_RUN (optional) - defaults to false
 // Remove all repos which with 1 day lifetime. By default will delete gitops repositories from redhat-appstudio-qe
@@ -328,7 +407,13 @@ func (ci CI) TestE2E() error {
 	if pr.RepoName == "e2e-tests" || pr.RepoName == "integration-service" ||
 		pr.RepoName == "release-service" || pr.RepoName == "image-controller" ||
 		pr.RepoName == "build-service" || pr.RepoName == "release-service-catalog" {
-		return engine.MageEngine.RunRulesOfCategory("ci", rctx)
+		mageEngine, err := engine.NewMageEngine()
+		if err != nil {
+			return err
+		}
+		return runRulesWithDecisionTrace(rctx, func() error {
+			return mageEngine.RunRulesOfCategory("ci", rctx)
+		})
 	}
 
 	if err := PreflightChecks(); err != nil {
@@ -375,10 +460,22 @@ func RunE2ETests() error {
 	}
 	switch rctx.RepoName {
 	case "release-service-catalog":
+		mageEngine, err := engine.NewMageEngine()
+		if err != nil {
+			return err
+		}
 		rctx.IsPaired = isPRPairingRequired("release-service")
-		return engine.MageEngine.RunRules(rctx, "tests", "release-service-catalog")
+		return runRulesWithDecisionTrace(rctx, func() error {
+			return mageEngine.RunRules(rctx, "tests", "release-service-catalog")
+		})
 	case "infra-deployments":
-		return engine.MageEngine.RunRules(rctx, "tests", "infra-deployments")
+		mageEngine, err := engine.NewMageEngine()
+		if err != nil {
+			return err
+		}
+		return runRulesWithDecisionTrace(rctx, func() error {
+			return mageEngine.RunRules(rctx, "tests", "infra-deployments")
+		})
 	default:
 		labelFilter := utils.GetEnv("E2E_TEST_SUITE_LABEL", "!upgrade-create && !upgrade-verify && !upgrade-cleanup && !release-pipelines")
 		return runTests(labelFilter, "e2e-report.xml")
@@ -1032,6 +1129,53 @@ func GenerateTeamSpecificGinkgoSpecFromTextOutline(outlinePath, teamTmplPath, de
 
 }
 
+// Generate a Gherkin feature file from a Ginkgo Spec
+func GenerateGherkinFeatureFromGinkgoSpec(source string, destination string) error {
+
+	gs := testspecs.NewGinkgoSpecTranslator()
+	gh := testspecs.NewGherkinSpecTranslator()
+
+	klog.Infof("Mapping outline from a Ginkgo test file, %s", source)
+	outline, err := gs.FromFile(source)
+	if err != nil {
+		klog.Error("Failed to map Ginkgo test file")
+		return err
+	}
+
+	klog.Infof("Mapping outline to a Gherkin feature file, %s", destination)
+	err = gh.ToFile(destination, outline)
+	if err != nil {
+		klog.Error("Failed to map Gherkin feature file")
+		return err
+	}
+
+	return err
+
+}
+
+// Generate a Ginkgo Spec file from a Gherkin feature file
+func GenerateGinkgoSpecFromGherkinFeature(source string, destination string) error {
+	gs := testspecs.NewGinkgoSpecTranslator()
+	gh := testspecs.NewGherkinSpecTranslator()
+
+	klog.Infof("Mapping outline from a Gherkin feature file, %s", source)
+	outline, err := gh.FromFile(source)
+	if err != nil {
+		klog.Error("Failed to map Gherkin feature file")
+		return err
+	}
+
+	klog.Infof("Mapping outline to a Ginkgo spec file, %s", destination)
+	err = gs.ToFile(destination, testspecs.TestFilePath, outline)
+	if err != nil {
+		klog.Error("Failed to map Ginkgo spec file")
+		return err
+	}
+
+	return err
+
+}
+
 // Print the outline of the Ginkgo spec
 func PrintOutlineOfGinkgoSpec(specFile string) error {
 
@@ -1090,6 +1234,235 @@ func PrintJsonOutlineOfGinkgoSpec(specFile string) error {
 
 }
 
+// Generate a Markdown and HTML test plan with a label × suite matrix of the specs in the tests directory.
+// Set GINKGO_JSON_REPORT to the path of a ginkgo JSON report to annotate the specs with their results
+func GenerateTestPlan(destination string) error {
+
+	klog.Info("Mapping outlines from the Ginkgo test files in tests/")
+	plan, err := testspecs.NewTestPlan("tests")
+	if err != nil {
+		klog.Errorf("failed to map the ginkgo specs to a test plan: %s", err)
+		return err
+	}
+
+	if report := os.Getenv("GINKGO_JSON_REPORT"); report != "" {
+		klog.Infof("Annotating the test plan with the ginkgo report, %s", report)
+		if err = plan.AnnotateWithReport(report); err != nil {
+			klog.Errorf("failed to annotate the test plan: %s", err)
+			return err
+		}
+	}
+
+	klog.Infof("Rendering the test plan into %s", destination)
+	return testspecs.RenderTestPlan(destination, plan)
+}
+
+// Reconcile the declared specs with the comma separated ginkgo JSON reports (globs allowed), listing the
+// never executed, always skipped and label orphaned specs. The declared specs are the outline of the tests directory
+// or, when SPECS_INVENTORY is set, the specs of the report written by `E2E_SPECS_INVENTORY=<file> ginkgo --dry-run ./cmd`.
+func ReconcileSpecs(reports string) error {
+
+	var reportFiles []string
+	for _, pattern := range strings.Split(reports, ",") {
+		matches, err := filepath.Glob(strings.TrimSpace(pattern))
+		if err != nil {
+			return err
+		}
+		reportFiles = append(reportFiles, matches...)
+	}
+
+	var plan *testspecs.TestPlan
+	var err error
+	if inventory := os.Getenv("SPECS_INVENTORY"); inventory != "" {
+		klog.Infof("Mapping the declared specs from the ginkgo report, %s", inventory)
+		plan, err = testspecs.NewTestPlanFromReport(inventory)
+	} else {
+		klog.Info("Mapping the declared specs from the Ginkgo test files in tests/")
+		plan, err = testspecs.NewTestPlan("tests")
+	}
+	if err != nil {
+		klog.Errorf("failed to map the declared specs: %s", err)
+		return err
+	}
+
+	reconciliation, err := testspecs.ReconcileReports(plan, reportFiles)
+	if err != nil {
+		klog.Errorf("failed to reconcile the specs: %s", err)
+		return err
+	}
+	fmt.Print(reconciliation.String())
+
+	data, err := json.MarshalIndent(reconciliation, "", "  ")
+	if err != nil {
+		return err
+	}
+	destination := filepath.Join(artifactDir, "spec-reconciliation.json")
+	klog.Infof("Writing the reconciliation to %s", destination)
+	return os.WriteFile(destination, data, 0644)
+}
+
+// Generate a Markdown and JSON flake report ranking the specs of the ginkgo JSON/JUnit reports in the directory.
+// When QUARANTINE_THRESHOLD is set, the label filter quarantining the specs above that flakiness is computed.
+func GenerateFlakeReport(reportsDir, destination string) error {
+
+	klog.Infof("Loading the ginkgo reports in %s", reportsDir)
+	runs, err := testspecs.LoadSpecRuns(reportsDir)
+	if err != nil {
+		klog.Errorf("failed to load the ginkgo reports: %s", err)
+		return err
+	}
+
+	report := testspecs.AnalyzeFlakes(runs)
+	if threshold := os.Getenv("QUARANTINE_THRESHOLD"); threshold != "" {
+		t, err := strconv.ParseFloat(threshold, 64)
+		if err != nil {
+			return fmt.Errorf("invalid QUARANTINE_THRESHOLD %q: %w", threshold, err)
+		}
+		report.Quarantine(t)
+		klog.Infof("Quarantine label filter: %q", report.QuarantineLabelFilter)
+		for _, spec := range report.Unquarantined {
+			klog.Warningf("spec %q is above the quarantine threshold but has no label of its own", spec)
+		}
+	}
+
+	klog.Infof("Rendering the flake report into %s", destination)
+	return testspecs.RenderFlakeReport(destination, report)
+}
+
+// Merge the ginkgo JSON reports of the shards, comma separated files or globs, into e2e-report.json and e2e-report.xml in the destination directory.
+func MergeShardReports(reports, destination string) error {
+
+	var shardReports []types.Report
+	for _, pattern := range strings.Split(reports, ",") {
+		matches, err := filepath.Glob(strings.TrimSpace(pattern))
+		if err != nil {
+			return err
+		}
+		for _, file := range matches {
+			report, err := testspecs.LoadGinkgoReports(file)
+			if err != nil {
+				return err
+			}
+			shardReports = append(shardReports, report...)
+		}
+	}
+
+	klog.Infof("Merging %d shard reports into %s", len(shardReports), destination)
+	merged, err := testspecs.MergeShardReports(shardReports)
+	if err != nil {
+		return err
+	}
+	if err := reporters.GenerateJSONReport(merged, filepath.Join(destination, "e2e-report.json")); err != nil {
+		return err
+	}
+	if err := reporters.GenerateJUnitReport(merged, filepath.Join(destination, "e2e-report.xml")); err != nil {
+		return err
+	}
+	if !merged.SuiteSucceeded {
+		klog.Warning("some of the shards failed")
+	}
+	return nil
+}
+
+// Generate index.html in ARTIFACT_DIR linking the specs of the ginkgo JSON reports, comma separated files or globs,
+// to their artifacts, i.e. the PipelineRun logs, pod logs and resource YAMLs stored by pkg/logs.
+func GenerateArtifactIndex(reports string) error {
+
+	var reportFiles []string
+	for _, pattern := range strings.Split(reports, ",") {
+		matches, err := filepath.Glob(strings.TrimSpace(pattern))
+		if err != nil {
+			return err
+		}
+		reportFiles = append(reportFiles, matches...)
+	}
+
+	klog.Infof("Writing the artifact index of %d reports into %s", len(reportFiles), artifactDir)
+	return logs.StoreArtifactIndex(artifactDir, reportFiles...)
+}
+
+// Aggregate the failure-report.json of the failed specs found in ARTIFACT_DIR into its failure-summary.json.
+func GenerateFailureSummary() error {
+
+	summary, err := framework.StoreFailureSummary(artifactDir)
+	if err != nil {
+		return err
+	}
+	klog.Infof("%d failed specs by category: %v", summary.Total, summary.Categories)
+	return nil
+}
+
+// Snapshot the state of the cluster, i.e. the ArgoCD Applications, the Konflux controllers with their logs and the recent Events,
+// into must-gather.tar.gz under ARTIFACT_DIR. Every collector times out after MUST_GATHER_COLLECTOR_TIMEOUT, 2m by default.
+func GatherClusterSnapshot() error {
+
+	client, err := kubeCl.NewAdminKubernetesClient()
+	if err != nil {
+		return err
+	}
+	path, err := mustgather.StoreClusterSnapshot(artifactDir, client.KubeInterface(), client.DynamicClient())
+	if err != nil {
+		return err
+	}
+	klog.Infof("Stored the cluster snapshot into %s", path)
+	return nil
+}
+
+// Push the artifact directory of the run, ARTIFACT_DIR, as an OCI artifact to the image reference, i.e. quay.io/<org>/e2e-artifacts:<tag>,
+// annotated with the job name, pull request, commit and result of the run so that it survives the CI environment.
+func PushTestArtifacts(imageRef string) error {
+
+	annotations := map[string]string{oras.AnnotationJobName: jobName, oras.AnnotationResult: "unknown"}
+	if jobSpec := os.Getenv("JOB_SPEC"); jobSpec != "" {
+		if err := json.Unmarshal([]byte(jobSpec), openshiftJobSpec); err != nil {
+			klog.Warningf("failed to parse JOB_SPEC: %v", err)
+		} else if len(openshiftJobSpec.Refs.Pulls) > 0 {
+			annotations[oras.AnnotationPullRequest] = strconv.Itoa(openshiftJobSpec.Refs.Pulls[0].Number)
+			annotations[oras.AnnotationCommit] = openshiftJobSpec.Refs.Pulls[0].SHA
+		}
+	}
+	if reports, err := testspecs.LoadGinkgoReports(filepath.Join(artifactDir, "e2e-report.json")); err == nil {
+		annotations[oras.AnnotationResult] = "passed"
+		for _, report := range reports {
+			if !report.SuiteSucceeded {
+				annotations[oras.AnnotationResult] = "failed"
+			}
+		}
+	}
+
+	digest, err := oras.PushArtifacts(artifactDir, imageRef, annotations)
+	if err != nil {
+		return err
+	}
+	klog.Infof("Pushed %s to %s@%s", artifactDir, imageRef, digest)
+	return nil
+}
+
+// List the artifact directories pushed with PushTestArtifacts to the repository, i.e. quay.io/<org>/e2e-artifacts.
+func ListTestArtifacts(repository string) error {
+
+	artifacts, err := oras.ListArtifacts(repository)
+	if err != nil {
+		return err
+	}
+	for _, artifact := range artifacts {
+		fmt.Printf("%s\t%s\tjob=%s pr=%s commit=%s result=%s\n", artifact.Tag, artifact.Digest,
+			artifact.Annotations[oras.AnnotationJobName], artifact.Annotations[oras.AnnotationPullRequest],
+			artifact.Annotations[oras.AnnotationCommit], artifact.Annotations[oras.AnnotationResult])
+	}
+	return nil
+}
+
+// Pull the artifact directory pushed with PushTestArtifacts to the image reference into the destination directory.
+func PullTestArtifacts(imageRef, destination string) error {
+
+	if err := oras.PullArtifactsTo(imageRef, destination); err != nil {
+		return err
+	}
+	klog.Infof("Pulled %s into %s", imageRef, filepath.Join(destination, "artifacts"))
+	return nil
+}
+
 // Append to the pkg/framework/describe.go the decorator function for new Ginkgo spec
 func AppendFrameworkDescribeGoFile(specFile string) error {
 
@@ -1351,6 +1724,7 @@ func runTests(labelsToRun string, junitReportFile string) error {
 
 // runTestsWithTimeout is like runTests but accepts a custom ginkgo timeout.
 // Use this for test suites that need longer than the default 90 minutes.
+// When E2E_SHARD is set, i.e. to 2/4, only the specs of that shard are run, see repos.ShardFocusFiles.
 func runTestsWithTimeout(labelsToRun, junitReportFile, timeout string) error {
 	extraFilter := strings.TrimSpace(os.Getenv("E2E_EXTRA_LABEL_FILTER"))
 	if extraFilter != "" {
@@ -1362,9 +1736,31 @@ func runTestsWithTimeout(labelsToRun, junitReportFile, timeout string) error {
 		klog.Infof("Extra label filter applied: running tests with label filter '%s'", labelsToRun)
 	}
 
+	var extraGinkgoArgs []string
+	if shard := os.Getenv("E2E_SHARD"); shard != "" {
+		focusFiles, err := repos.ShardFocusFiles(shard, types.SuiteConfig{LabelFilter: labelsToRun}, os.Getenv("E2E_SHARD_HISTORY"))
+		if err != nil {
+			return err
+		}
+		if len(focusFiles) == 0 {
+			klog.Infof("shard %s has no spec to run", shard)
+			return nil
+		}
+		for _, focusFile := range focusFiles {
+			extraGinkgoArgs = append(extraGinkgoArgs, "--focus-file="+focusFile)
+		}
+	}
+
+	return runTestsWithReports(labelsToRun, junitReportFile, "e2e-report.json", timeout, extraGinkgoArgs...)
+}
+
+// runTestsWithReports is like runTestsWithTimeout but writes the JSON report into jsonReportFile and passes
+// the extra arguments to ginkgo, i.e. the --focus-file filters of the specs to rerun. The label filter is used as is.
+func runTestsWithReports(labelsToRun, junitReportFile, jsonReportFile, timeout string, extraGinkgoArgs ...string) error {
 	ginkgoArgs := []string{"-p", "-v", "--output-interceptor-mode=none", "--no-color", "--fail-on-empty",
-		"--timeout=" + timeout, "--json-report=e2e-report.json", fmt.Sprintf("--output-dir=%s", artifactDir),
+		"--timeout=" + timeout, "--json-report=" + jsonReportFile, fmt.Sprintf("--output-dir=%s", artifactDir),
 		"--junit-report=" + junitReportFile, "--label-filter=" + labelsToRun}
+	ginkgoArgs = append(ginkgoArgs, extraGinkgoArgs...)
 
 	if os.Getenv("GINKGO_PROCS") != "" {
 		ginkgoArgs = append(ginkgoArgs, fmt.Sprintf("--procs=%s", os.Getenv("GINKGO_PROCS")))
@@ -1379,7 +1775,11 @@ func runTestsWithTimeout(labelsToRun, junitReportFile, timeout string) error {
 	ginkgoArgs = append(ginkgoArgs, "--")
 
 	// added --output-interceptor-mode=none to mitigate RHTAPBUGS-34
-	return sh.RunV("ginkgo", ginkgoArgs...)
+	err := sh.RunV("ginkgo", ginkgoArgs...)
+
+	repos.StoreRunSummaries(artifactDir, filepath.Join(artifactDir, jsonReportFile))
+
+	return err
 }
 
 func CleanupRegisteredPacServers() error {
@@ -1419,8 +1819,20 @@ func isValidPacHost(server string) bool {
 	return err == nil
 }
 
+// PreviewTestSelection prints the tests selected by the rules engine for the changes in the local e2e-tests branch.
+// When RELEASE_SERVICE_CATALOG_DIR points to a local release-service-catalog checkout, it prints the release
+// pipelines suites selected for the changes of its current branch instead.
 func (Local) PreviewTestSelection() error {
 
+	if catalogDir := os.Getenv("RELEASE_SERVICE_CATALOG_DIR"); catalogDir != "" {
+		selection, err := repos.PreviewReleasePipelinesSelection(catalogDir)
+		if err != nil {
+			return err
+		}
+		klog.Infof("release pipelines selection:\n%s", selection.String())
+		return nil
+	}
+
 	rctx := rulesengine.NewRuleCtx()
 	files, err := utils.GetChangedFiles("e2e-tests")
 	if err != nil {
@@ -1430,12 +1842,88 @@ func (Local) PreviewTestSelection() error {
 	rctx.DiffFiles = files
 	rctx.DryRun = true
 
-	err = engine.MageEngine.RunRules(rctx, "tests", "e2e-repo")
+	mageEngine, err := engine.NewMageEngine()
+	if err != nil {
+		return err
+	}
+	return runRulesWithDecisionTrace(rctx, func() error {
+		return mageEngine.RunRules(rctx, "tests", "e2e-repo")
+	})
+}
+
+// runRulesWithDecisionTrace runs the rules with the decision trace of the rules engine enabled.
+// The trace is logged as a tree and stored as JSON in the artifact dir. When the trace is already
+// enabled, e.g. when rules run tests which run other rules, the nested evaluation is added to it.
+func runRulesWithDecisionTrace(rctx *rulesengine.RuleCtx, run func() error) error {
+	if rctx.DecisionTrace != nil {
+		return run()
+	}
+
+	rctx.DecisionTrace = rulesengine.NewDecisionTrace()
+	defer func() {
+		klog.Infof("rules engine decision trace:\n%s", rctx.DecisionTrace.String())
+		if err := rctx.DecisionTrace.Store(artifactDir); err != nil {
+			klog.Errorf("failed to store the rules engine decision trace: %v", err)
+		}
+		rctx.DecisionTrace = nil
+	}()
+	return run()
+}
+
+// LintRuleCatalogs validates the declarative rule catalogs shipped in magefiles/rulesengine/declarative/catalogs.
+// Env vars to configure this target: RULE_CATALOGS_DIR (optional) - additional directory with catalog files to validate
+func (Local) LintRuleCatalogs() error {
+	files, err := declarative.Load(declarative.Catalogs)
+	if err != nil {
+		return err
+	}
+	klog.Infof("%d declarative rule catalogs shipped in the repository are valid", len(files))
+
+	dir := os.Getenv("RULE_CATALOGS_DIR")
+	if dir == "" {
+		return nil
+	}
+	files, err = declarative.Load(os.DirFS(dir))
+	if err != nil {
+		return err
+	}
+	// the catalogs of the directory must not collide with the ones already registered
+	if _, err := engine.NewMageEngine(); err != nil {
+		return err
+	}
+	klog.Infof("%d declarative rule catalogs in %s are valid", len(files), dir)
+	return nil
+}
+
+// LintLabels checks the labels of the specs in tests/ against the allowlist and naming rules in tests/labels.yaml
+// and against the labels referenced by the rule catalogs. It fails when any error is found.
+func (Local) LintLabels() error {
+	config, err := testspecs.LoadLabelLintConfig("tests/labels.yaml")
+	if err != nil {
+		return err
+	}
 
+	references, err := rulesengine.ExtractLabelReferences("magefiles/rulesengine/repos")
+	if err != nil {
+		return err
+	}
+	declarativeReferences, err := declarative.LabelReferences(declarative.Catalogs, "magefiles/rulesengine/declarative")
 	if err != nil {
 		return err
 	}
+	references = append(references, declarativeReferences...)
 
+	issues, err := testspecs.LintLabels("tests", config, references)
+	if err != nil {
+		return err
+	}
+	for _, i := range issues {
+		fmt.Println(i.String())
+	}
+	if errs := testspecs.LabelLintErrors(issues); errs > 0 {
+		return fmt.Errorf("found %d label lint errors", errs)
+	}
+	klog.Infof("labels are valid, found %d warnings", len(issues))
 	return nil
 }
 
@@ -1449,7 +1937,11 @@ func (Local) RunRuleDemo() error {
 	rctx.DiffFiles = files
 	rctx.DryRun = true
 
-	err = engine.MageEngine.RunRulesOfCategory("demo", rctx)
+	mageEngine, err := engine.NewMageEngine()
+	if err != nil {
+		return err
+	}
+	err = mageEngine.RunRulesOfCategory("demo", rctx)
 
 	if err != nil {
 		return err
@@ -1475,6 +1967,10 @@ func (Local) RunInfraDeploymentsRuleDemo() error {
 	}
 	rctx.DiffFiles = files
 
+	mageEngine, err := engine.NewMageEngine()
+	if err != nil {
+		return err
+	}
 	// filtering the rule engine to load only infra-deployments rule catalog within the test category
-	return engine.MageEngine.RunRules(rctx, "tests", "infra-deployments")
+	return mageEngine.RunRules(rctx, "tests", "infra-deployments")
 }
@@ -0,0 +1,513 @@
+// Package declarative loads rule catalogs of the rules engine from YAML files, so that the tests selected
+// for changes in a repository can be adjusted without writing Go code.
+//
+// A catalog file registers a catalog of rules under a category of the engine:
+//
+//	category: tests
+//	catalog: build-service
+//	rules:
+//	  - name: build templates changed
+//	    description: Run build-templates tests when the pipeline config changes
+//	    when:
+//	      all:
+//	        - paths: ["components/build-service/base/build-pipeline-config/*.yaml"]
+//	          status: [A, M]
+//	        - none:
+//	            - eventType: push
+//	        - rule: preflight
+//	    actions:
+//	      - addLabels: [build-templates]
+//	      - runTests: true
+//	definitions:
+//	  - name: preflight
+//	    when:
+//	      rule: PreflightInstallGinkgoRule
+//
+// Rules listed under rules form the catalog, rules listed under definitions are only evaluated when referenced
+// by other rules. A rule reference is resolved to a rule of the same file first and then to one of the Go rules
+// in GoRules.
+package declarative
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"go/token"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
+	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine/repos"
+	"github.com/konflux-ci/e2e-tests/pkg/testspecs"
+	"sigs.k8s.io/yaml"
+)
+
+// Catalogs contains the catalog files shipped with the e2e-tests repository
+//
+//go:embed catalogs/*.yaml
+var Catalogs embed.FS
+
+var (
+	// GoRules are the Go rules which can be referenced from catalog files by their name
+	GoRules = map[string]*rulesengine.Rule{
+		"PreflightInstallGinkgoRule":              &repos.PreflightInstallGinkgoRule,
+		"InstallKonfluxRule":                      &repos.InstallKonfluxRule,
+		"RegisterKonfluxToSprayProxyRule":         &repos.RegisterKonfluxToSprayProxyRule,
+		"SetupMultiPlatformTestsRule":             &repos.SetupMultiPlatformTestsRule,
+		"BootstrapClusterRuleChain":               &repos.BootstrapClusterRuleChain,
+		"BootstrapClusterWithSprayProxyRuleChain": &repos.BootstrapClusterWithSprayProxyRuleChain,
+	}
+
+	validJobTypes   = []string{"presubmit", "postsubmit", "periodic"}
+	validEventTypes = []string{"pull_request", "push", "incoming"}
+	// validStatuses are the statuses reported by git diff --name-status
+	validStatuses = []string{"A", "C", "D", "M", "R", "T", "U", "X", "B"}
+)
+
+// CatalogFile is the content of a YAML catalog file
+type CatalogFile struct {
+	Category    string     `json:"category"`
+	Catalog     string     `json:"catalog"`
+	Rules       []RuleSpec `json:"rules"`
+	Definitions []RuleSpec `json:"definitions,omitempty"`
+}
+
+// RuleSpec declares a rule, its condition and the actions taken when the condition is satisfied
+type RuleSpec struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	When        ConditionSpec `json:"when"`
+	Actions     []ActionSpec  `json:"actions,omitempty"`
+}
+
+// ConditionSpec is a node of the condition of a rule, exactly one of its predicates has to be set
+type ConditionSpec struct {
+	All  []ConditionSpec `json:"all,omitempty"`
+	Any  []ConditionSpec `json:"any,omitempty"`
+	None []ConditionSpec `json:"none,omitempty"`
+	// Rule references another rule which is evaluated (and its actions taken) as a part of the condition
+	Rule string `json:"rule,omitempty"`
+	// Paths is satisfied when any of the changed files matches any of the doublestar globs
+	Paths []string `json:"paths,omitempty"`
+	// Status restricts Paths to files changed with any of the given git statuses, e.g. A for added files
+	Status []string `json:"status,omitempty"`
+	// NoFilesChanged is satisfied when no files were changed
+	NoFilesChanged bool   `json:"noFilesChanged,omitempty"`
+	Repo           string `json:"repo,omitempty"`
+	JobType        string `json:"jobType,omitempty"`
+	EventType      string `json:"eventType,omitempty"`
+}
+
+// ActionSpec is an action taken by a rule, exactly one of its fields has to be set
+type ActionSpec struct {
+	// SetLabelFilter replaces the label filter
+	SetLabelFilter *string `json:"setLabelFilter,omitempty"`
+	// AddLabels adds the labels to the label filter unless it already contains them
+	AddLabels []string `json:"addLabels,omitempty"`
+	// ExcludeLabels excludes specs with any of the labels from the label filter
+	ExcludeLabels []string `json:"excludeLabels,omitempty"`
+	// RunTests runs the tests selected by the label filter and focus files
+	RunTests bool `json:"runTests,omitempty"`
+}
+
+// Parse unmarshals and validates a catalog file, unknown fields are reported as errors
+func Parse(data []byte) (*CatalogFile, error) {
+	c := &CatalogFile{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Validate checks the catalog file against the schema of catalog files described in the package documentation.
+// All found issues are returned joined in a single error.
+func (c *CatalogFile) Validate() error {
+	var errs []error
+	if c.Category == "" {
+		errs = append(errs, fmt.Errorf("category is required"))
+	}
+	if c.Catalog == "" {
+		errs = append(errs, fmt.Errorf("catalog is required"))
+	}
+	if len(c.Rules) == 0 {
+		errs = append(errs, fmt.Errorf("rules: at least one rule is required"))
+	}
+
+	names := map[string]bool{}
+	for _, l := range []struct {
+		field string
+		specs []RuleSpec
+	}{{"rules", c.Rules}, {"definitions", c.Definitions}} {
+		for i, r := range l.specs {
+			p := fmt.Sprintf("%s[%d]", l.field, i)
+			if r.Name == "" {
+				errs = append(errs, fmt.Errorf("%s.name is required", p))
+			} else if names[r.Name] {
+				errs = append(errs, fmt.Errorf("%s: duplicate rule name %q", p, r.Name))
+			}
+			names[r.Name] = true
+			errs = append(errs, r.When.validate(p+".when")...)
+			for j, a := range r.Actions {
+				errs = append(errs, a.validate(fmt.Sprintf("%s.actions[%d]", p, j))...)
+			}
+		}
+	}
+
+	for _, r := range append(slices.Clone(c.Rules), c.Definitions...) {
+		for _, ref := range r.When.references() {
+			if !names[ref] && GoRules[ref] == nil {
+				errs = append(errs, fmt.Errorf("rule %q references unknown rule %q", r.Name, ref))
+			}
+		}
+	}
+	if err := c.checkCycles(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (cs *ConditionSpec) validate(p string) []error {
+	var errs []error
+	set := 0
+	for _, isSet := range []bool{len(cs.All) > 0, len(cs.Any) > 0, len(cs.None) > 0, cs.Rule != "", len(cs.Paths) > 0, cs.NoFilesChanged, cs.Repo != "", cs.JobType != "", cs.EventType != ""} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		errs = append(errs, fmt.Errorf("%s: exactly one of all, any, none, rule, paths, noFilesChanged, repo, jobType or eventType has to be set", p))
+	}
+	for _, l := range []struct {
+		field    string
+		children []ConditionSpec
+	}{{"all", cs.All}, {"any", cs.Any}, {"none", cs.None}} {
+		for i, child := range l.children {
+			errs = append(errs, child.validate(fmt.Sprintf("%s.%s[%d]", p, l.field, i))...)
+		}
+	}
+	for _, glob := range cs.Paths {
+		if !doublestar.ValidatePattern(glob) {
+			errs = append(errs, fmt.Errorf("%s.paths: invalid glob %q", p, glob))
+		}
+	}
+	if len(cs.Status) > 0 && len(cs.Paths) == 0 {
+		errs = append(errs, fmt.Errorf("%s.status can only be used together with paths", p))
+	}
+	for _, status := range cs.Status {
+		if !slices.Contains(validStatuses, status) {
+			errs = append(errs, fmt.Errorf("%s.status: invalid status %q, expected one of %v", p, status, validStatuses))
+		}
+	}
+	if cs.JobType != "" && !slices.Contains(validJobTypes, cs.JobType) {
+		errs = append(errs, fmt.Errorf("%s.jobType: invalid job type %q, expected one of %v", p, cs.JobType, validJobTypes))
+	}
+	if cs.EventType != "" && !slices.Contains(validEventTypes, cs.EventType) {
+		errs = append(errs, fmt.Errorf("%s.eventType: invalid event type %q, expected one of %v", p, cs.EventType, validEventTypes))
+	}
+	return errs
+}
+
+// references returns the names of the rules referenced by the condition
+func (cs *ConditionSpec) references() []string {
+	var refs []string
+	if cs.Rule != "" {
+		refs = append(refs, cs.Rule)
+	}
+	for _, children := range [][]ConditionSpec{cs.All, cs.Any, cs.None} {
+		for _, child := range children {
+			refs = append(refs, child.references()...)
+		}
+	}
+	return refs
+}
+
+func (a *ActionSpec) validate(p string) []error {
+	var errs []error
+	set := 0
+	for _, isSet := range []bool{a.SetLabelFilter != nil, len(a.AddLabels) > 0, len(a.ExcludeLabels) > 0, a.RunTests} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		errs = append(errs, fmt.Errorf("%s: exactly one of setLabelFilter, addLabels, excludeLabels or runTests has to be set", p))
+	}
+	for _, label := range append(slices.Clone(a.AddLabels), a.ExcludeLabels...) {
+		if label == "" || strings.ContainsAny(label, "&|!(),/ ") {
+			errs = append(errs, fmt.Errorf("%s: invalid label %q", p, label))
+		}
+	}
+	return errs
+}
+
+// checkCycles returns an error if rules of the catalog file reference each other in a cycle
+func (c *CatalogFile) checkCycles() error {
+	specs := c.specsByName()
+	visiting, visited := map[string]bool{}, map[string]bool{}
+	var visit func(name string, chain []string) error
+	visit = func(name string, chain []string) error {
+		spec, ok := specs[name]
+		if !ok || visited[name] {
+			return nil
+		}
+		if visiting[name] {
+			return fmt.Errorf("rules reference each other in a cycle: %s", strings.Join(append(chain, name), " -> "))
+		}
+		visiting[name] = true
+		for _, ref := range spec.When.references() {
+			if err := visit(ref, append(chain, name)); err != nil {
+				return err
+			}
+		}
+		visiting[name], visited[name] = false, true
+		return nil
+	}
+	for _, list := range [][]RuleSpec{c.Rules, c.Definitions} {
+		for _, r := range list {
+			if err := visit(r.Name, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *CatalogFile) specsByName() map[string]*RuleSpec {
+	specs := map[string]*RuleSpec{}
+	for _, list := range [][]RuleSpec{c.Rules, c.Definitions} {
+		for i := range list {
+			specs[list[i].Name] = &list[i]
+		}
+	}
+	return specs
+}
+
+// RuleCatalog builds the rules of the catalog file, the catalog file has to be valid
+func (c *CatalogFile) RuleCatalog() rulesengine.RuleCatalog {
+	specs := c.specsByName()
+	built := map[string]*rulesengine.Rule{}
+	var build func(spec *RuleSpec) *rulesengine.Rule
+	var condition func(cs ConditionSpec) rulesengine.Conditional
+
+	build = func(spec *RuleSpec) *rulesengine.Rule {
+		if r, ok := built[spec.Name]; ok {
+			return r
+		}
+		r := &rulesengine.Rule{Name: spec.Name, Description: spec.Description}
+		built[spec.Name] = r
+		r.Condition = condition(spec.When)
+		for _, a := range spec.Actions {
+			r.Actions = append(r.Actions, action(a))
+		}
+		return r
+	}
+
+	condition = func(cs ConditionSpec) rulesengine.Conditional {
+		switch {
+		case len(cs.All) > 0:
+			all := rulesengine.All{}
+			for _, child := range cs.All {
+				all = append(all, condition(child))
+			}
+			return all
+		case len(cs.Any) > 0:
+			anyOf := rulesengine.Any{}
+			for _, child := range cs.Any {
+				anyOf = append(anyOf, condition(child))
+			}
+			return anyOf
+		case len(cs.None) > 0:
+			none := rulesengine.None{}
+			for _, child := range cs.None {
+				none = append(none, condition(child))
+			}
+			return none
+		case cs.Rule != "":
+			if spec, ok := specs[cs.Rule]; ok {
+				return build(spec)
+			}
+			return GoRules[cs.Rule]
+		case len(cs.Paths) > 0:
+			return pathsCondition(cs.Paths, cs.Status)
+		case cs.NoFilesChanged:
+			return rulesengine.NamedCondition("noFilesChanged", func(rctx *rulesengine.RuleCtx) (bool, error) {
+				return len(rctx.DiffFiles) == 0, nil
+			})
+		case cs.Repo != "":
+			return rulesengine.NamedCondition("repo == "+cs.Repo, func(rctx *rulesengine.RuleCtx) (bool, error) {
+				return rctx.RepoName == cs.Repo, nil
+			})
+		case cs.JobType != "":
+			return rulesengine.NamedCondition("jobType == "+cs.JobType, func(rctx *rulesengine.RuleCtx) (bool, error) {
+				return rctx.JobType == cs.JobType, nil
+			})
+		default:
+			return rulesengine.NamedCondition("eventType == "+cs.EventType, func(rctx *rulesengine.RuleCtx) (bool, error) {
+				return rctx.TektonEventType == cs.EventType, nil
+			})
+		}
+	}
+
+	catalog := rulesengine.RuleCatalog{}
+	for i := range c.Rules {
+		catalog = append(catalog, *build(&c.Rules[i]))
+	}
+	return catalog
+}
+
+func pathsCondition(globs, statuses []string) rulesengine.Conditional {
+	name := "paths " + strings.Join(globs, ", ")
+	if len(statuses) > 0 {
+		name += " with status " + strings.Join(statuses, ", ")
+	}
+	return rulesengine.NamedCondition(name, func(rctx *rulesengine.RuleCtx) (bool, error) {
+		matched := false
+		for _, glob := range globs {
+			for _, file := range rctx.DiffFilesByDirGlob(glob) {
+				if len(statuses) == 0 || slices.ContainsFunc(statuses, func(s string) bool { return strings.HasPrefix(file.Status, s) }) {
+					matched = true
+				}
+			}
+		}
+		return matched, nil
+	})
+}
+
+func action(a ActionSpec) rulesengine.Action {
+	switch {
+	case a.SetLabelFilter != nil:
+		return rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
+			rctx.LabelFilter = *a.SetLabelFilter
+			return nil
+		})
+	case len(a.AddLabels) > 0:
+		return rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
+			for _, label := range a.AddLabels {
+				repos.AddLabelToLabelFilter(rctx, label)
+			}
+			return nil
+		})
+	case len(a.ExcludeLabels) > 0:
+		return rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
+			excluded := "!" + strings.Join(a.ExcludeLabels, " && !")
+			if rctx.LabelFilter == "" {
+				rctx.LabelFilter = excluded
+			} else {
+				rctx.LabelFilter = fmt.Sprintf("(%s) && %s", rctx.LabelFilter, excluded)
+			}
+			return nil
+		})
+	default:
+		return rulesengine.ActionFunc(repos.ExecuteTestAction)
+	}
+}
+
+// Register loads all YAML catalog files from fsys and registers their catalogs in the engine.
+// It fails when a catalog is invalid or its name is already registered in its category.
+func Register(e rulesengine.RuleEngine, fsys fs.FS) error {
+	files, err := Load(fsys)
+	if err != nil {
+		return err
+	}
+	for _, c := range files {
+		if _, ok := e[c.Category][c.Catalog]; ok {
+			return fmt.Errorf("catalog %s is already registered in category %s", c.Catalog, c.Category)
+		}
+		if e[c.Category] == nil {
+			e[c.Category] = map[string]rulesengine.RuleCatalog{}
+		}
+		e[c.Category][c.Catalog] = c.RuleCatalog()
+	}
+	return nil
+}
+
+// Load parses all YAML catalog files in fsys, the returned error contains the issues of all invalid files
+func Load(fsys fs.FS) ([]*CatalogFile, error) {
+	var files []*CatalogFile
+	var errs []error
+	err := walkCatalogFiles(fsys, func(p string, data []byte) {
+		c, err := Parse(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p, err))
+			return
+		}
+		files = append(files, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, errors.Join(errs...)
+}
+
+// walkCatalogFiles calls fn with the content of every YAML file in fsys
+func walkCatalogFiles(fsys fs.FS, fn func(p string, data []byte)) error {
+	return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || (path.Ext(p) != ".yaml" && path.Ext(p) != ".yml") {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		fn(p, data)
+		return nil
+	})
+}
+
+// LabelReferences returns the labels referenced by the actions of the catalog files in fsys. The position of
+// a reference is the line of the action mentioning the label, prefixed with dir, the location of fsys.
+func LabelReferences(fsys fs.FS, dir string) ([]testspecs.LabelReference, error) {
+	var refs []testspecs.LabelReference
+	var errs []error
+	err := walkCatalogFiles(fsys, func(p string, data []byte) {
+		c, err := Parse(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p, err))
+			return
+		}
+		lines := strings.Split(string(data), "\n")
+		// the label is looked up in the lines of the actions first, rule names can mention it as well
+		position := func(label string) token.Position {
+			pos := token.Position{Filename: path.Join(dir, p)}
+			for i := len(lines) - 1; i >= 0; i-- {
+				if !strings.Contains(lines[i], label) {
+					continue
+				}
+				pos.Line = i + 1
+				if strings.Contains(lines[i], "LabelFilter:") || strings.Contains(lines[i], "Labels:") {
+					break
+				}
+			}
+			return pos
+		}
+		for _, rule := range slices.Concat(c.Rules, c.Definitions) {
+			for _, a := range rule.Actions {
+				if a.SetLabelFilter != nil {
+					for _, ref := range rulesengine.ParseLabelFilter(*a.SetLabelFilter, token.Position{}) {
+						ref.Pos = position(ref.Label)
+						refs = append(refs, ref)
+					}
+				}
+				for _, l := range a.AddLabels {
+					refs = append(refs, testspecs.LabelReference{Label: l, Pos: position(l)})
+				}
+				for _, l := range a.ExcludeLabels {
+					refs = append(refs, testspecs.LabelReference{Label: l, Excluded: true, Pos: position(l)})
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refs, errors.Join(errs...)
+}
@@ -0,0 +1,177 @@
+package declarative
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
+	"github.com/stretchr/testify/assert"
+)
+
+const labelsCatalog = `
+category: tests
+catalog: labels
+rules:
+  - name: build-service changed
+    when:
+      all:
+        - rule: build-service files
+        - none:
+            - eventType: push
+    actions:
+      - addLabels: [build-service]
+  - name: release changed
+    when:
+      paths: ["components/release/**"]
+      status: [A]
+    actions:
+      - addLabels: [release-service]
+      - excludeLabels: [release-pipelines]
+definitions:
+  - name: build-service files
+    when:
+      any:
+        - paths: ["components/build-service/**/*"]
+        - noFilesChanged: true
+`
+
+func TestShippedCatalogs(t *testing.T) {
+	files, err := Load(Catalogs)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, files)
+
+	e := rulesengine.RuleEngine{}
+	assert.NoError(t, Register(e, Catalogs))
+	assert.Error(t, Register(e, Catalogs), "registering the same catalogs twice must fail")
+}
+
+func TestRuleCatalog(t *testing.T) {
+	c, err := Parse([]byte(labelsCatalog))
+	assert.NoError(t, err)
+
+	e := rulesengine.RuleEngine{}
+	assert.NoError(t, Register(e, fstest.MapFS{"labels.yaml": {Data: []byte(labelsCatalog)}}))
+	assert.Len(t, e["tests"]["labels"], len(c.Rules))
+
+	for _, tc := range []struct {
+		name        string
+		files       rulesengine.Files
+		eventType   string
+		labelFilter string
+	}{
+		{name: "build-service file changed", files: rulesengine.Files{{Status: "M", Name: "components/build-service/base/kustomization.yaml"}}, labelFilter: "build-service"},
+		{name: "no files changed", labelFilter: "build-service"},
+		{name: "push event", files: rulesengine.Files{{Status: "M", Name: "components/build-service/base/kustomization.yaml"}}, eventType: "push", labelFilter: ""},
+		{name: "release file added", files: rulesengine.Files{{Status: "A", Name: "components/release/base/new.yaml"}}, labelFilter: "(release-service) && !release-pipelines"},
+		{name: "release file modified", files: rulesengine.Files{{Status: "M", Name: "components/release/base/new.yaml"}}, labelFilter: ""},
+		{name: "both changed", files: rulesengine.Files{{Status: "M", Name: "components/build-service/a.yaml"}, {Status: "A", Name: "components/release/b.yaml"}}, labelFilter: "(build-service,release-service) && !release-pipelines"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rctx := rulesengine.NewRuleCtx()
+			rctx.DiffFiles = tc.files
+			rctx.TektonEventType = tc.eventType
+			assert.NoError(t, e.RunRules(rctx, "tests", "labels"))
+			assert.Equal(t, tc.labelFilter, rctx.LabelFilter)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		catalog  string
+		expected []string
+	}{
+		{
+			name:     "unknown field",
+			catalog:  "category: tests\ncatalog: c\nrules:\n  - name: r\n    when:\n      path: [a]\n",
+			expected: []string{`unknown field "path"`},
+		},
+		{
+			name:     "missing fields",
+			catalog:  "rules: []\n",
+			expected: []string{"category is required", "catalog is required", "at least one rule is required"},
+		},
+		{
+			name: "invalid predicates",
+			catalog: `
+category: tests
+catalog: c
+rules:
+  - name: r
+    when:
+      all:
+        - paths: ["a/[b"]
+          status: [Z]
+        - jobType: nightly
+          eventType: push
+        - repo: e2e-tests
+          status: [A]
+    actions:
+      - addLabels: ["a || b"]
+      - {}
+`,
+			expected: []string{
+				`rules[0].when.all[0].paths: invalid glob "a/[b"`,
+				`rules[0].when.all[0].status: invalid status "Z"`,
+				"rules[0].when.all[1]: exactly one of",
+				`rules[0].when.all[1].jobType: invalid job type "nightly"`,
+				"rules[0].when.all[2].status can only be used together with paths",
+				`rules[0].actions[0]: invalid label "a || b"`,
+				"rules[0].actions[1]: exactly one of",
+			},
+		},
+		{
+			name: "references",
+			catalog: `
+category: tests
+catalog: c
+rules:
+  - name: a
+    when:
+      rule: b
+  - name: c
+    when:
+      rule: missing
+definitions:
+  - name: b
+    when:
+      any:
+        - rule: a
+        - rule: PreflightInstallGinkgoRule
+  - name: c
+    when:
+      noFilesChanged: true
+`,
+			expected: []string{
+				`definitions[1]: duplicate rule name "c"`,
+				`references unknown rule "missing"`,
+				"rules reference each other in a cycle: a -> b -> a",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.catalog))
+			for _, expected := range tc.expected {
+				assert.ErrorContains(t, err, expected)
+			}
+		})
+	}
+}
+
+func TestLabelReferences(t *testing.T) {
+	fsys := fstest.MapFS{"catalogs/labels.yaml": {Data: []byte(labelsCatalog)}}
+
+	refs, err := LabelReferences(fsys, "declarative")
+	assert.NoError(t, err)
+	var got []string
+	for _, r := range refs {
+		got = append(got, fmt.Sprintf("%s %t %s", r.Label, r.Excluded, r.Pos))
+	}
+	assert.Equal(t, []string{
+		"build-service false declarative/catalogs/labels.yaml:12",
+		"release-service false declarative/catalogs/labels.yaml:18",
+		"release-pipelines true declarative/catalogs/labels.yaml:19",
+	}, got)
+}
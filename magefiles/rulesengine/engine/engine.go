@@ -1,7 +1,12 @@
 package engine
 
 import (
+	"fmt"
+	"maps"
+	"os"
+
 	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
+	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine/declarative"
 	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine/repos"
 )
 
@@ -25,3 +30,22 @@ var MageEngine = rulesengine.RuleEngine{
 		//"infra-deployments": repos.InfraDeploymentsCIChainCatalog,
 	},
 }
+
+// NewMageEngine returns the MageEngine with the declarative catalogs registered alongside its Go catalogs: the ones
+// shipped in the repository and, when RULE_CATALOGS_DIR is set, the ones in that directory, so rules can be added
+// without a change of the e2e-tests repository. It fails when a catalog is invalid or already registered.
+func NewMageEngine() (rulesengine.RuleEngine, error) {
+	e := rulesengine.RuleEngine{}
+	for category, catalogs := range MageEngine {
+		e[category] = maps.Clone(catalogs)
+	}
+	if err := declarative.Register(e, declarative.Catalogs); err != nil {
+		return nil, fmt.Errorf("failed to register declarative rule catalogs: %w", err)
+	}
+	if dir := os.Getenv("RULE_CATALOGS_DIR"); dir != "" {
+		if err := declarative.Register(e, os.DirFS(dir)); err != nil {
+			return nil, fmt.Errorf("failed to register declarative rule catalogs of %s: %w", dir, err)
+		}
+	}
+	return e, nil
+}
@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const teamCatalog = `category: tests
+catalog: team-workflow
+rules:
+  - name: Team Rule
+    when:
+      paths: ["tests/team/**"]
+    actions:
+      - setLabelFilter: "team"
+`
+
+func TestNewMageEngine(t *testing.T) {
+	t.Setenv("RULE_CATALOGS_DIR", "")
+	e, err := NewMageEngine()
+	assert.NoError(t, err)
+	assert.Contains(t, e["demo"], "declarative-workflow")
+	assert.NotContains(t, MageEngine["demo"], "declarative-workflow", "the Go catalogs should not be changed")
+
+	dir := t.TempDir()
+	t.Setenv("RULE_CATALOGS_DIR", dir)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "team.yaml"), []byte(teamCatalog), 0o600))
+	e, err = NewMageEngine()
+	assert.NoError(t, err)
+	assert.Contains(t, e["tests"], "team-workflow")
+	assert.Contains(t, e["tests"], "e2e-repo")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "demo.yaml"), []byte(strings.Replace(teamCatalog, "category: tests\ncatalog: team-workflow", "category: demo\ncatalog: local-workflow", 1)), 0o600))
+	_, err = NewMageEngine()
+	assert.ErrorContains(t, err, "catalog local-workflow is already registered in category demo")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "demo.yaml"), []byte("category: [demo\n"), 0o600))
+	_, err = NewMageEngine()
+	assert.ErrorContains(t, err, "failed to register declarative rule catalogs of "+dir)
+}
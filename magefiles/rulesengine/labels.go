@@ -0,0 +1,160 @@
+package rulesengine
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/konflux-ci/e2e-tests/pkg/testspecs"
+)
+
+// ParseLabelFilter returns the labels of a Ginkgo label filter, i.e. `(a || b) && !c`. A label is excluded
+// when it is negated an odd number of times, counting the negations of the groups enclosing it, i.e. `!(a || b)`.
+// Regular expressions and label set queries aren't plain labels and are skipped.
+// The positions of the returned references are set to pos.
+func ParseLabelFilter(filter string, pos token.Position) []testspecs.LabelReference {
+
+	var refs []testspecs.LabelReference
+	runes := []rune(filter)
+	// groups holds the negation of the enclosing groups, the innermost one last
+	groups := []bool{false}
+	negated := false
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '!':
+			negated = !negated
+		case r == '(':
+			groups = append(groups, groups[len(groups)-1] != negated)
+			negated = false
+		case r == ')':
+			if len(groups) > 1 {
+				groups = groups[:len(groups)-1]
+			}
+		case r == '/':
+			// a regular expression, up to its closing slash
+			if end := slices.Index(runes[i+1:], '/'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(runes)
+			}
+			negated = false
+		case strings.ContainsRune("&|, \t\n", r):
+		default:
+			end := i
+			for end < len(runes) && !strings.ContainsRune("&|!(),/: \t\n", runes[end]) {
+				end++
+			}
+			if end < len(runes) && runes[end] == ':' {
+				// a label set query, i.e. `env: containsAny {prod, stage}`, up to its closing brace or operator
+				i = skipLabelSetQuery(runes, end)
+			} else {
+				refs = append(refs, testspecs.LabelReference{Label: string(runes[i:end]), Excluded: groups[len(groups)-1] != negated, Pos: pos})
+				i = end - 1
+			}
+			negated = false
+		}
+	}
+	return refs
+}
+
+// skipLabelSetQuery returns the index of the last rune of the label set query whose key ends at start
+func skipLabelSetQuery(runes []rune, start int) int {
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case '{':
+			if end := slices.Index(runes[i:], '}'); end >= 0 {
+				return i + end
+			}
+			return len(runes) - 1
+		case '&', '|', ')':
+			return i - 1
+		}
+	}
+	return len(runes) - 1
+}
+
+// ExtractLabelReferences returns the labels referenced by the Go rule catalogs in the directory:
+// the string literals and constants assigned to RuleCtx.LabelFilter or passed to label helpers,
+// i.e. AddLabelToLabelFilter(rctx, "konflux"). Labels computed at runtime are not found.
+func ExtractLabelReferences(dir string) ([]testspecs.LabelReference, error) {
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	consts := map[string]string{}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, f)
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if i < len(vs.Values) {
+						if lit, ok := vs.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+							consts[name.Name], _ = strconv.Unquote(lit.Value)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	var refs []testspecs.LabelReference
+	addFilter := func(expr ast.Expr) {
+		switch e := expr.(type) {
+		case *ast.BasicLit:
+			if filter, err := strconv.Unquote(e.Value); err == nil && e.Kind == token.STRING {
+				refs = append(refs, ParseLabelFilter(filter, fset.Position(e.Pos()))...)
+			}
+		case *ast.Ident:
+			if filter, ok := consts[e.Name]; ok {
+				refs = append(refs, ParseLabelFilter(filter, fset.Position(e.Pos()))...)
+			}
+		}
+	}
+	for _, f := range parsed {
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.AssignStmt:
+				for i, lhs := range node.Lhs {
+					if sel, ok := lhs.(*ast.SelectorExpr); ok && sel.Sel.Name == "LabelFilter" && i < len(node.Rhs) {
+						addFilter(node.Rhs[i])
+					}
+				}
+			case *ast.CallExpr:
+				var name string
+				switch fun := node.Fun.(type) {
+				case *ast.Ident:
+					name = fun.Name
+				case *ast.SelectorExpr:
+					name = fun.Sel.Name
+				}
+				if strings.Contains(name, "Label") {
+					for _, arg := range node.Args {
+						addFilter(arg)
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	return refs, nil
+}
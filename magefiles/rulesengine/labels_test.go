@@ -0,0 +1,55 @@
+package rulesengine
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLabelFilter(t *testing.T) {
+	pos := token.Position{Filename: "rules.go", Line: 3}
+	var got []string
+	for _, r := range ParseLabelFilter("(build-service || !!konflux) && !upgrade-create,ec && /re(g)ex/ && env: containsAny {prod, stage} && !integration", pos) {
+		got = append(got, fmt.Sprintf("%s %t", r.Label, r.Excluded))
+		assert.Equal(t, pos, r.Pos)
+	}
+	assert.Equal(t, []string{"build-service false", "konflux false", "upgrade-create true", "ec false", "integration true"}, got)
+
+	// the negation of a group applies to all of its labels
+	got = nil
+	for _, r := range ParseLabelFilter("!(a || (b && !c)) && d && !(!e)", pos) {
+		got = append(got, fmt.Sprintf("%s %t", r.Label, r.Excluded))
+	}
+	assert.Equal(t, []string{"a true", "b true", "c false", "d false", "e false"}, got)
+}
+
+func TestExtractLabelReferences(t *testing.T) {
+	dir := t.TempDir()
+	src := `package repos
+
+const defaultFilter = "!upgrade-create && !release-pipelines"
+
+func action(rctx *rulesengine.RuleCtx) error {
+	rctx.LabelFilter = defaultFilter
+	rctx.LabelFilter += " && !rh-advisories"
+	AddLabelToLabelFilter(rctx, "konflux")
+	klog.Infof("setting label filter %s", "not-a-label")
+	rctx.LabelFilter = strings.Split(rctx.JobName, "-")[0]
+	return nil
+}
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "rules.go"), []byte(src), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "rules_test.go"), []byte("package repos\n\nvar _ = AddLabelToLabelFilter(rctx, \"test-only\")\n"), 0644))
+
+	refs, err := ExtractLabelReferences(dir)
+	assert.NoError(t, err)
+	var got []string
+	for _, r := range refs {
+		got = append(got, fmt.Sprintf("%s %t %d", r.Label, r.Excluded, r.Pos.Line))
+	}
+	assert.Equal(t, []string{"upgrade-create true 6", "release-pipelines true 6", "rh-advisories true 7", "konflux false 8"}, got)
+}
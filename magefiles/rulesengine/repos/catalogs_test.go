@@ -0,0 +1,181 @@
+package repos
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+var updateGolden = flag.Bool("update", false, "update the expected outcomes of the rule catalogs golden fixtures")
+
+const ruleCatalogsFixtures = "testdata/rule-catalogs"
+
+// goldenCatalogs are the Go catalogs registered in engine.MageEngine, every one of them has to be
+// covered by a fixture file named <category>-<catalog>.yaml in testdata/rule-catalogs
+var goldenCatalogs = rulesengine.RuleEngine{
+	"tests": {
+		"e2e-repo":          E2ETestRulesCatalog,
+		"infra-deployments": InfraDeploymentsRulesCatalog,
+	},
+	"ci": {
+		"e2e-repo":                E2ECIChainCatalog,
+		"release-service":         ReleaseServiceCICatalog,
+		"release-service-catalog": ReleaseServiceCatalogCICatalog,
+		"integration-service":     IntegrationServiceCICatalog,
+		"image-controller":        ImageControllerCICatalog,
+		"build-service":           BuildServiceCICatalog,
+	},
+}
+
+// catalogFixture is a set of cases evaluated against a single catalog
+type catalogFixture struct {
+	Category string        `json:"category"`
+	Catalog  string        `json:"catalog"`
+	Cases    []catalogCase `json:"cases"`
+}
+
+type catalogCase struct {
+	Name string `json:"name"`
+	// Env is set for the evaluation of the case, E2E_EXTRA_LABEL_FILTER and SKIP_BOOTSTRAP are unset by default
+	Env map[string]string `json:"env,omitempty"`
+	Ctx catalogCaseCtx    `json:"ctx"`
+	// PRPairingRequired is returned by the stubbed lookup of paired PRs on GitHub
+	PRPairingRequired bool           `json:"prPairingRequired,omitempty"`
+	Expected          catalogOutcome `json:"expected"`
+}
+
+// catalogCaseCtx are the RuleCtx fields set for the evaluation of a case
+type catalogCaseCtx struct {
+	RepoName        string `json:"repoName,omitempty"`
+	JobName         string `json:"jobName,omitempty"`
+	JobType         string `json:"jobType,omitempty"`
+	TektonEventType string `json:"tektonEventType,omitempty"`
+	PrRemoteName    string `json:"prRemoteName,omitempty"`
+	PrBranchName    string `json:"prBranchName,omitempty"`
+	PrNum           int    `json:"prNum,omitempty"`
+	// DiffFiles are in the format of git diff --name-status, e.g. "M pkg/utils/util.go"
+	DiffFiles []string `json:"diffFiles,omitempty"`
+}
+
+func (c catalogCaseCtx) diffFiles() rulesengine.Files {
+	files := rulesengine.Files{}
+	for _, f := range c.DiffFiles {
+		status, name, _ := strings.Cut(f, " ")
+		files = append(files, rulesengine.File{Status: status, Name: name})
+	}
+	return files
+}
+
+// catalogOutcome is the outcome of the evaluation of a catalog compared against the fixture
+type catalogOutcome struct {
+	MatchedRules []string `json:"matchedRules,omitempty"`
+	AppliedRules []string `json:"appliedRules,omitempty"`
+	LabelFilter  string   `json:"labelFilter,omitempty"`
+	FocusFiles   []string `json:"focusFiles,omitempty"`
+	// TestRuns is the number of the (stubbed) ginkgo executions
+	TestRuns int `json:"testRuns,omitempty"`
+}
+
+// evaluateCatalogCase runs the catalog in dry run mode with the calls reaching out of the process stubbed
+func evaluateCatalogCase(t *testing.T, catalog rulesengine.RuleCatalog, c catalogCase) catalogOutcome {
+	t.Setenv("E2E_EXTRA_LABEL_FILTER", "")
+	t.Setenv("SKIP_BOOTSTRAP", "")
+	for k, v := range c.Env {
+		t.Setenv(k, v)
+	}
+
+	var outcome catalogOutcome
+	origRunGinkgo, origGetChangedFiles, origIsPRPairingRequired, origSelection, origRootDir := runGinkgo, getChangedFiles, isPRPairingRequired, releasePipelinesSelectionForPR, testImpactRootDir
+	t.Cleanup(func() {
+		runGinkgo, getChangedFiles, isPRPairingRequired, releasePipelinesSelectionForPR, testImpactRootDir = origRunGinkgo, origGetChangedFiles, origIsPRPairingRequired, origSelection, origRootDir
+	})
+	// The import graph of the test impact analysis is the one of the fixture module
+	testImpactRootDir = testImpactFixture
+	runGinkgo = func(args ...string) error {
+		outcome.TestRuns++
+		return nil
+	}
+	getChangedFiles = func(string) (rulesengine.Files, error) {
+		return c.Ctx.diffFiles(), nil
+	}
+	isPRPairingRequired = func(string, string, string) bool {
+		return c.PRPairingRequired
+	}
+	// The diff files of a release-service-catalog case are the files changed in the fixture catalog
+	releasePipelinesSelectionForPR = func(int) (*ReleasePipelinesSelection, error) {
+		var changedFiles []string
+		for _, f := range c.Ctx.diffFiles() {
+			changedFiles = append(changedFiles, f.Name)
+		}
+		return SelectReleasePipelines(releaseServiceCatalogFixture, changedFiles)
+	}
+
+	rctx := rulesengine.NewRuleCtx()
+	rctx.DryRun = true
+	rctx.DecisionTrace = rulesengine.NewDecisionTrace()
+	rctx.RepoName = c.Ctx.RepoName
+	rctx.JobName = c.Ctx.JobName
+	rctx.JobType = c.Ctx.JobType
+	rctx.TektonEventType = c.Ctx.TektonEventType
+	rctx.PrRemoteName = c.Ctx.PrRemoteName
+	rctx.PrBranchName = c.Ctx.PrBranchName
+	rctx.PrNum = c.Ctx.PrNum
+	rctx.DiffFiles = c.Ctx.diffFiles()
+
+	engine := rulesengine.RuleEngine{"golden": {"catalog": catalog}}
+	assert.NoError(t, engine.RunRules(rctx, "golden", "catalog"))
+
+	outcome.MatchedRules = rctx.DecisionTrace.MatchedRules()
+	outcome.AppliedRules = rctx.DecisionTrace.AppliedRules()
+	outcome.LabelFilter = rctx.LabelFilter
+	outcome.FocusFiles = rctx.FocusFiles
+	return outcome
+}
+
+// TestRuleCatalogsGoldenFixtures evaluates the rule catalogs against the fixtures in testdata/rule-catalogs,
+// run it with -update to record the current outcomes after an intended change of a catalog.
+func TestRuleCatalogsGoldenFixtures(t *testing.T) {
+	for category, catalogs := range goldenCatalogs {
+		for name, catalog := range catalogs {
+			path := filepath.Join(ruleCatalogsFixtures, fmt.Sprintf("%s-%s.yaml", category, name))
+			t.Run(strings.TrimSuffix(filepath.Base(path), ".yaml"), func(t *testing.T) {
+				data, err := os.ReadFile(path)
+				if !assert.NoError(t, err, "every catalog registered in the engine has to be covered by a fixture file") {
+					return
+				}
+
+				var fixture catalogFixture
+				if !assert.NoError(t, yaml.UnmarshalStrict(data, &fixture)) {
+					return
+				}
+				assert.Equal(t, category, fixture.Category)
+				assert.Equal(t, name, fixture.Catalog)
+				assert.NotEmpty(t, fixture.Cases)
+
+				for i, c := range fixture.Cases {
+					t.Run(c.Name, func(t *testing.T) {
+						outcome := evaluateCatalogCase(t, catalog, c)
+						if *updateGolden {
+							fixture.Cases[i].Expected = outcome
+							return
+						}
+						assert.Equal(t, c.Expected, outcome, "the outcome of the catalog changed, run the test with -update if the change is intended")
+					})
+				}
+
+				if *updateGolden {
+					data, err := yaml.Marshal(fixture)
+					assert.NoError(t, err)
+					assert.NoError(t, os.WriteFile(path, data, 0644))
+				}
+			})
+		}
+	}
+}
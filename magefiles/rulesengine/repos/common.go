@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -21,6 +22,8 @@ import (
 	"github.com/konflux-ci/e2e-tests/pkg/clients/slack"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/sprayproxy"
 	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/framework"
+	"github.com/konflux-ci/e2e-tests/pkg/logs"
 	"github.com/konflux-ci/e2e-tests/pkg/utils"
 	"github.com/konflux-ci/e2e-tests/pkg/utils/tekton"
 	"github.com/magefile/mage/sh"
@@ -32,6 +35,15 @@ import (
 	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 )
 
+// Calls of the rules which reach out of the process (ginkgo, git and the GitHub API) are done through
+// these variables, so that the rule catalogs can be evaluated with them stubbed by the golden fixture tests.
+var (
+	runGinkgo                      = func(args ...string) error { return sh.RunV("ginkgo", args...) }
+	getChangedFiles                = utils.GetChangedFiles
+	isPRPairingRequired            = IsPRPairingRequired
+	releasePipelinesSelectionForPR = selectReleasePipelinesForPR
+)
+
 func ExecuteTestAction(rctx *rulesengine.RuleCtx) error {
 
 	/* This is so that we don't have ginkgo add the prefixes to
@@ -54,6 +66,21 @@ func ExecuteTestAction(rctx *rulesengine.RuleCtx) error {
 
 	var suiteConfig = rctx.SuiteConfig
 	var reporterConfig = rctx.ReporterConfig
+
+	// Run only the specs of the shard of this job, i.e. E2E_SHARD=2/4
+	if shard := os.Getenv("E2E_SHARD"); shard != "" && !rctx.DryRun {
+		focusFiles, err := ShardFocusFiles(shard, suiteConfig, os.Getenv("E2E_SHARD_HISTORY"))
+		if err != nil {
+			return err
+		}
+		if len(focusFiles) == 0 {
+			klog.Infof("shard %s has no spec to run", shard)
+			return nil
+		}
+		// the specs of the shard are already the ones selected by the focus strings
+		suiteConfig.FocusStrings = nil
+		suiteConfig.FocusFiles = focusFiles
+	}
 	var cliConfig = rctx.CLIConfig
 	var goFlagsConfig = rctx.GoFlagsConfig
 
@@ -91,8 +118,33 @@ func ExecuteTestAction(rctx *rulesengine.RuleCtx) error {
 		klog.Error(err)
 	}
 	argsToRun = append(argsToRun, "./cmd", "--")
-	return sh.RunV("ginkgo", argsToRun...)
+	err = runGinkgo(argsToRun...)
 
+	if !rctx.DryRun && reporterConfig.JSONReport != "" {
+		StoreRunSummaries(cliConfig.OutputDir, filepath.Join(cliConfig.OutputDir, reporterConfig.JSONReport))
+	}
+
+	return err
+}
+
+// StoreRunSummaries writes the artifact index and the failure summary of a run into its artifact directory. They are
+// written even when some specs failed, they are most useful then, and their own failures are only logged.
+func StoreRunSummaries(artifactDir, jsonReport string) {
+	if _, err := os.Stat(jsonReport); err != nil {
+		klog.Warningf("no ginkgo JSON report to summarize the run: %v", err)
+		return
+	}
+	if err := logs.StoreArtifactIndex(artifactDir, jsonReport); err != nil {
+		klog.Warningf("failed to write the artifact index: %v", err)
+	}
+	summary, err := framework.StoreFailureSummary(artifactDir)
+	if err != nil {
+		klog.Warningf("failed to write the failure summary: %v", err)
+		return
+	}
+	if summary.Total > 0 {
+		klog.Infof("%d failed specs by category: %v", summary.Total, summary.Categories)
+	}
 }
 
 func GetPairedCommitSha(repoForPairing string, rctx *rulesengine.RuleCtx) string {
@@ -302,7 +354,7 @@ var PrepareBranchRule = rulesengine.Rule{Name: "Prepare E2E branch for CI",
 			}
 		}
 
-		return IsPRPairingRequired("e2e-tests", rctx.PrRemoteName, rctx.PrBranchName), nil
+		return isPRPairingRequired("e2e-tests", rctx.PrRemoteName, rctx.PrBranchName), nil
 	}), rulesengine.None{rulesengine.ConditionFunc(IsPeriodicJob),
 		rulesengine.ConditionFunc(IsRehearseJob)}},
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
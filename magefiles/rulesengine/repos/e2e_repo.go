@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
-	"github.com/konflux-ci/e2e-tests/pkg/utils"
 	"k8s.io/klog"
 )
 
@@ -22,11 +21,20 @@ var NonTestFilesRule = rulesengine.Rule{Name: "E2E Default PR Test Exectuion",
 			rulesengine.ConditionFunc(CheckNoFilesChanged),
 			rulesengine.ConditionFunc(CheckTektonFilesChanged),
 		},
-		rulesengine.None{rulesengine.ConditionFunc(CheckReleasePipelinesTestsChanged)},
+		rulesengine.None{
+			rulesengine.ConditionFunc(CheckReleasePipelinesTestsChanged),
+			rulesengine.ConditionFunc(IsTestImpactNarrowed),
+		},
 	},
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(ExecuteDefaultTestAction)},
 }
 
+var TestImpactRule = rulesengine.Rule{Name: "E2E PR Test Impact Execution",
+	Description: "Runs the suites depending on the changed packages when only Go packages are modified in the e2e-repo PR",
+	Condition:   rulesengine.ConditionFunc(IsTestImpactNarrowed),
+	Actions:     []rulesengine.Action{rulesengine.ActionFunc(ExecuteTestImpactAction)},
+}
+
 var NonTestFilesRuleWithReleasePipelines = rulesengine.Rule{Name: "E2E PR Test Execution including release-pipelines test suite",
 	Description: "Runs all test suites including release-pipelines test suite which is usually excluded on PRs",
 	Condition: rulesengine.All{
@@ -68,13 +76,13 @@ var TestFilesOnlyRule = rulesengine.Rule{Name: "E2E PR Test File Diff Execution"
 
 func CheckReleasePipelinesTestsChanged(rctx *rulesengine.RuleCtx) (bool, error) {
 
-	return len(rctx.DiffFiles.FilterByDirGlob("tests/release/pipelines/**/*.go")) != 0, nil
+	return len(rctx.DiffFilesByDirGlob("tests/release/pipelines/**/*.go")) != 0, nil
 
 }
 
 func CheckTektonFilesChanged(rctx *rulesengine.RuleCtx) (bool, error) {
 
-	return len(rctx.DiffFiles.FilterByDirString("integration-tests/")) != 0 || len(rctx.DiffFiles.FilterByDirString(".tekton/")) != 0, nil
+	return len(rctx.DiffFilesByDirString("integration-tests/")) != 0 || len(rctx.DiffFilesByDirString(".tekton/")) != 0, nil
 
 }
 
@@ -82,13 +90,13 @@ var BuildORBuildTemplatesTestFileChangeOnlyRule = rulesengine.Rule{Name: "E2E PR
 	Description: "Map build tests files when build.go or build_templates.go test files are only changed in the e2e-repo PR",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 
-		return len(rctx.DiffFiles.FilterByDirString("tests/build/build_templates_scenarios.go")) == 0 &&
-			len(rctx.DiffFiles.FilterByDirString("tests/build/const.go")) == 0 &&
-			len(rctx.DiffFiles.FilterByDirString("tests/build/source_build.go")) == 0, nil
+		return len(rctx.DiffFilesByDirString("tests/build/build_templates_scenarios.go")) == 0 &&
+			len(rctx.DiffFilesByDirString("tests/build/const.go")) == 0 &&
+			len(rctx.DiffFilesByDirString("tests/build/source_build.go")) == 0, nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 
-		for _, file := range rctx.DiffFiles.FilterByDirGlob("tests/build/*.go") {
+		for _, file := range rctx.DiffFilesByDirGlob("tests/build/*.go") {
 
 			rctx.FocusFiles = dedupeAppendFiles(rctx.FocusFiles, file.Name)
 
@@ -101,7 +109,7 @@ var BuildTemplateDependentFileChangeRule = rulesengine.Rule{Name: "E2E PR Build
 	Description: "Map build templates test file when build_templates_scenario.go or source_build.go file is changed in the e2e-repo PR",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 
-		return len(rctx.DiffFiles.FilterByDirString("tests/build/build_templates_scenarios.go")) != 0 || len(rctx.DiffFiles.FilterByDirString("tests/build/source_build.go")) != 0, nil
+		return len(rctx.DiffFilesByDirString("tests/build/build_templates_scenarios.go")) != 0 || len(rctx.DiffFilesByDirString("tests/build/source_build.go")) != 0, nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 
@@ -115,11 +123,11 @@ var BuildNonTestFileChangeRule = rulesengine.Rule{Name: "E2E PR Build Test Helpe
 	Description: "Map build tests files when const.go file is changed in the e2e-repo PR",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 
-		return len(rctx.DiffFiles.FilterByDirGlob("tests/build/const.go")) != 0, nil
+		return len(rctx.DiffFilesByDirGlob("tests/build/const.go")) != 0, nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 
-		for _, file := range rctx.DiffFiles.FilterByDirGlob("tests/build/*.go") {
+		for _, file := range rctx.DiffFilesByDirGlob("tests/build/*.go") {
 
 			if strings.Contains(file.Name, "source_build.go") || strings.Contains(file.Name, "const.go") || strings.Contains(file.Name, "scenarios.go") {
 				continue
@@ -136,11 +144,11 @@ var ReleaseTestTestFilesChangeRule = rulesengine.Rule{Name: "E2E PR Release Test
 	Description: "Map release test files if they are changed in the e2e-repo PR",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 
-		return len(rctx.DiffFiles.FilterByDirGlob("tests/release/*/*.go")) != 0, nil
+		return len(rctx.DiffFilesByDirGlob("tests/release/*/*.go")) != 0, nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 
-		for _, file := range rctx.DiffFiles.FilterByDirGlob("tests/release/*/*.go") {
+		for _, file := range rctx.DiffFilesByDirGlob("tests/release/*/*.go") {
 
 			rctx.FocusFiles = dedupeAppendFiles(rctx.FocusFiles, file.Name)
 
@@ -154,7 +162,7 @@ var ReleaseTestHelperFilesChangeOnlyRule = rulesengine.Rule{Name: "E2E PR Releas
 	Description: "Map release tests files when only the release helper go files in root of release directory are changed in the e2e-repo PR",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 
-		return len(rctx.DiffFiles.FilterByDirGlob("tests/release/*.go")) != 0 && len(rctx.DiffFiles.FilterByDirGlob("tests/release/*/*.go")) == 0, nil
+		return len(rctx.DiffFilesByDirGlob("tests/release/*.go")) != 0 && len(rctx.DiffFilesByDirGlob("tests/release/*/*.go")) == 0, nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 
@@ -176,11 +184,11 @@ var KonfluxDemoTestFileChangedRule = rulesengine.Rule{Name: "E2E PR Konflux-Demo
 	Description: "Map demo tests files when konflux-demo test files are changed in the e2e-repo PR",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 
-		return len(rctx.DiffFiles.FilterByDirGlob("tests/*-demo/*.go")) != 0, nil
+		return len(rctx.DiffFilesByDirGlob("tests/*-demo/*.go")) != 0, nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 
-		for _, file := range rctx.DiffFiles.FilterByDirGlob("tests/*-demo/*-demo.go") {
+		for _, file := range rctx.DiffFilesByDirGlob("tests/*-demo/*-demo.go") {
 
 			rctx.FocusFiles = dedupeAppendFiles(rctx.FocusFiles, file.Name)
 
@@ -193,7 +201,7 @@ var KonfluxDemoConfigsFileOnlyChangeRule = rulesengine.Rule{Name: "E2E PR Konflu
 	Description: "Map demo tests files when konflux-demo config.go|type.go test files are changed in the e2e-repo PR",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 
-		return len(rctx.DiffFiles.FilterByDirGlob("tests/*-demo/*.go")) == 0 && len(rctx.DiffFiles.FilterByDirGlob("tests/*-demo/*/*")) != 0, nil
+		return len(rctx.DiffFilesByDirGlob("tests/*-demo/*.go")) == 0 && len(rctx.DiffFilesByDirGlob("tests/*-demo/*/*")) != 0, nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 
@@ -215,11 +223,11 @@ var IntegrationTestsFileChangeRule = rulesengine.Rule{Name: "E2E PR Integration
 	Description: "Map integration tests files when integration test files are changed in the e2e-repo PR",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 
-		return len(rctx.DiffFiles.FilterByDirGlob("tests/integration-*/*.go")) != 0, nil
+		return len(rctx.DiffFilesByDirGlob("tests/integration-*/*.go")) != 0, nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 
-		for _, file := range rctx.DiffFiles.FilterByDirGlob("tests/integration-*/*.go") {
+		for _, file := range rctx.DiffFilesByDirGlob("tests/integration-*/*.go") {
 
 			if strings.Contains(file.Name, "const.go") {
 
@@ -239,7 +247,7 @@ var IntegrationTestsConstFileChangeRule = rulesengine.Rule{Name: "E2E PR Integra
 	Description: "Map all integration tests files when integration const files are changed in the e2e-repo PR",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 
-		return len(rctx.DiffFiles.FilterByDirGlob("tests/integration-*/const.go")) != 0, nil
+		return len(rctx.DiffFilesByDirGlob("tests/integration-*/const.go")) != 0, nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 
@@ -267,11 +275,11 @@ var IntegrationTestsConstFileChangeRule = rulesengine.Rule{Name: "E2E PR Integra
 var EcTestFileChangeRule = rulesengine.Rule{Name: "E2E PR EC Test File Change Rule",
 	Description: "Map EC tests files when EC test files are changed in the e2e-repo PR",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
-		return len(rctx.DiffFiles.FilterByDirGlob("tests/enterprise-*/*.go")) != 0, nil
+		return len(rctx.DiffFilesByDirGlob("tests/enterprise-*/*.go")) != 0, nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 
-		for _, file := range rctx.DiffFiles.FilterByDirGlob("tests/enterprise-*/*.go") {
+		for _, file := range rctx.DiffFilesByDirGlob("tests/enterprise-*/*.go") {
 
 			rctx.FocusFiles = dedupeAppendFiles(rctx.FocusFiles, file.Name)
 
@@ -294,7 +302,7 @@ var InfraDeploymentsPRPairingRule = rulesengine.Rule{Name: "Set Required Setting
 			}
 		}
 
-		return IsPRPairingRequired("infra-deployments", rctx.PrRemoteName, rctx.PrBranchName), nil
+		return isPRPairingRequired("infra-deployments", rctx.PrRemoteName, rctx.PrBranchName), nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 
@@ -319,7 +327,7 @@ var E2ERepoCIRuleChain = rulesengine.Rule{Name: "E2E Repo CI Workflow Rule Chain
 		rulesengine.Any{&InfraDeploymentsPRPairingRule, rulesengine.None{&InfraDeploymentsPRPairingRule}},
 		&PreflightInstallGinkgoRule,
 		&BootstrapClusterRuleChain,
-		rulesengine.Any{&NonTestFilesRule, &NonTestFilesRuleWithReleasePipelines, &TestFilesOnlyRule}},
+		rulesengine.Any{&NonTestFilesRule, &NonTestFilesRuleWithReleasePipelines, &TestImpactRule, &TestFilesOnlyRule}},
 }
 
 var E2ERepoSetDefaultSettingsRule = rulesengine.Rule{Name: "General Required Settings for E2E Repo Jobs",
@@ -334,14 +342,14 @@ var E2ERepoSetDefaultSettingsRule = rulesengine.Rule{Name: "General Required Set
 		rctx.RequiresSprayProxyRegistering = true
 		klog.Info("multi-platform tests and require sprayproxy registering are set to TRUE")
 
-		rctx.DiffFiles, err = utils.GetChangedFiles(rctx.RepoName)
+		rctx.DiffFiles, err = getChangedFiles(rctx.RepoName)
 		return err
 	})},
 }
 
 var E2ECIChainCatalog = rulesengine.RuleCatalog{E2ERepoCIRuleChain}
 
-var E2ETestRulesCatalog = rulesengine.RuleCatalog{NonTestFilesRule, NonTestFilesRuleWithReleasePipelines, TestFilesOnlyRule}
+var E2ETestRulesCatalog = rulesengine.RuleCatalog{NonTestFilesRule, NonTestFilesRuleWithReleasePipelines, TestImpactRule, TestFilesOnlyRule}
 
 var IsE2ETestsRepoPR = rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 	klog.Info("checking if repository is e2e-tests")
@@ -355,24 +363,27 @@ func CheckNoFilesChanged(rctx *rulesengine.RuleCtx) (bool, error) {
 
 func CheckPkgFilesChanged(rctx *rulesengine.RuleCtx) (bool, error) {
 
-	return len(rctx.DiffFiles.FilterByDirString("pkg/")) != 0, nil
+	return len(rctx.DiffFilesByDirString("pkg/")) != 0, nil
 
 }
 
 func CheckMageFilesChanged(rctx *rulesengine.RuleCtx) (bool, error) {
 
-	return len(rctx.DiffFiles.FilterByDirString("magefiles/")) != 0, nil
+	return len(rctx.DiffFilesByDirString("magefiles/")) != 0, nil
 
 }
 
 func CheckCmdFilesChanged(rctx *rulesengine.RuleCtx) (bool, error) {
 
-	return len(rctx.DiffFiles.FilterByDirString("cmd/")) != 0, nil
+	return len(rctx.DiffFilesByDirString("cmd/")) != 0, nil
 
 }
 
+// defaultPRLabelFilter selects all suites run for e2e-tests PRs
+const defaultPRLabelFilter = "!upgrade-create && !upgrade-verify && !upgrade-cleanup && !release-pipelines && !disaster-recovery"
+
 func ExecuteDefaultTestAction(rctx *rulesengine.RuleCtx) error {
-	rctx.LabelFilter = "!upgrade-create && !upgrade-verify && !upgrade-cleanup && !release-pipelines && !disaster-recovery"
+	rctx.LabelFilter = defaultPRLabelFilter
 	return ExecuteTestAction(rctx)
 
 }
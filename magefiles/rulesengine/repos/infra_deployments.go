@@ -55,7 +55,7 @@ var InfraDeploymentsIntegrationComponentChangeRule = rulesengine.Rule{Name: "Inf
 	Description: "Map Integration tests files when Integration component files are changed in the infra-deployments PR",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 
-		return len(rctx.DiffFiles.FilterByDirGlob("components/integration/**/*")) != 0, nil
+		return len(rctx.DiffFilesByDirGlob("components/integration/**/*")) != 0, nil
 
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
@@ -67,7 +67,7 @@ var InfraDeploymentsEnterpriseControllerComponentChangeRule = rulesengine.Rule{N
 	Description: "Map Enterprise Controller tests files when EC component files are changed in the infra-deployments PR",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 
-		return len(rctx.DiffFiles.FilterByDirGlob("components/enterprise-contract/**/*")) != 0, nil
+		return len(rctx.DiffFilesByDirGlob("components/enterprise-contract/**/*")) != 0, nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 		AddLabelToLabelFilter(rctx, "ec")
@@ -78,7 +78,7 @@ var InfraDeploymentsJVMComponentChangeRule = rulesengine.Rule{Name: "Infra-deplo
 	Description: "Map jvm-build-service tests files when Jvm-build-service component files are changed in the infra-deployments PR",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 
-		return len(rctx.DiffFiles.FilterByDirGlob("components/jvm-build-service/**/*")) != 0, nil
+		return len(rctx.DiffFilesByDirGlob("components/jvm-build-service/**/*")) != 0, nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 		AddLabelToLabelFilter(rctx, "jvm-build-service")
@@ -89,7 +89,7 @@ var InfraDeploymentsImageControllerComponentChangeRule = rulesengine.Rule{Name:
 	Description: "Map image-controller tests files when Image Controller component files are changed in the infra-deployments PR",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 
-		return len(rctx.DiffFiles.FilterByDirGlob("components/image-controller/**/*")) != 0, nil
+		return len(rctx.DiffFilesByDirGlob("components/image-controller/**/*")) != 0, nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 		AddLabelToLabelFilter(rctx, "image-controller")
@@ -100,7 +100,7 @@ var InfraDeploymentsMultiPlatformComponentChangeRule = rulesengine.Rule{Name: "I
 	Description: "Map multi platform tests files when Multi Controller component files are changed in the infra-deployments PR",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 
-		return len(rctx.DiffFiles.FilterByDirGlob("components/multi-platform-controller/**/*")) != 0, nil
+		return len(rctx.DiffFilesByDirGlob("components/multi-platform-controller/**/*")) != 0, nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 		AddLabelToLabelFilter(rctx, "multi-platform")
@@ -110,7 +110,7 @@ var InfraDeploymentsMultiPlatformComponentChangeRule = rulesengine.Rule{Name: "I
 var InfraDeploymentsBuildTemplatesComponentChangeRule = rulesengine.Rule{Name: "Infra-deployments PR Build-templates component File Change Rule",
 	Description: "Map build-templates tests files when build-pipeline-config.yaml is changed",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
-		return len(rctx.DiffFiles.FilterByDirGlob("components/build-service/base/build-pipeline-config/build-pipeline-config.yaml")) != 0, nil
+		return len(rctx.DiffFilesByDirGlob("components/build-service/base/build-pipeline-config/build-pipeline-config.yaml")) != 0, nil
 	}),
 	Actions: []rulesengine.Action{
 		rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
@@ -124,7 +124,7 @@ var InfraDeploymentsBuildServiceComponentChangeRule = rulesengine.Rule{Name: "In
 	Description: "Map build service tests files when files in build-service are changed except build-pipeline-config.yaml",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 
-		return len(rctx.DiffFiles.FilterByDirGlob("components/build-service/**/*")) > len(rctx.DiffFiles.FilterByDirGlob("components/build-service/base/build-pipeline-config/*")), nil
+		return len(rctx.DiffFilesByDirGlob("components/build-service/**/*")) > len(rctx.DiffFilesByDirGlob("components/build-service/base/build-pipeline-config/*")), nil
 	}),
 	Actions: []rulesengine.Action{
 		rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
@@ -137,7 +137,7 @@ var InfraDeploymentsBuildServiceComponentChangeRule = rulesengine.Rule{Name: "In
 var InfraDeploymentsPipelineServiceComponentChangeRule = rulesengine.Rule{Name: "Infra-deployments PR Pipeline Service component File Change Rule",
 	Description: "Map pipeline service tests files when files in pipeline-service are changed",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
-		return len(rctx.DiffFiles.FilterByDirGlob("components/pipeline-service/**/*")) != 0, nil
+		return len(rctx.DiffFilesByDirGlob("components/pipeline-service/**/*")) != 0, nil
 	}),
 	Actions: []rulesengine.Action{
 		rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
@@ -151,7 +151,7 @@ var InfraDeploymentsReleaseServiceComponentChangeRule = rulesengine.Rule{Name: "
 	Description: "Map release service tests files when Release service component files are changed in the infra-deployments PR",
 	Condition: rulesengine.ConditionFunc(func(rctx *rulesengine.RuleCtx) (bool, error) {
 
-		return len(rctx.DiffFiles.FilterByDirGlob("components/release/**/*")) != 0, nil
+		return len(rctx.DiffFilesByDirGlob("components/release/**/*")) != 0, nil
 	}),
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 		AddLabelToLabelFilter(rctx, "release-service")
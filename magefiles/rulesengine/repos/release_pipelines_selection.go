@@ -0,0 +1,305 @@
+package repos
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"k8s.io/klog"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	releaseServiceCatalogRepo          = "konflux-ci/release-service-catalog"
+	releaseServiceCatalogDefaultBranch = "development"
+
+	// releasePipelinesAllTestCases is the label of all release pipelines suites
+	releasePipelinesAllTestCases = "release-pipelines"
+	// releasePipelinesNoTestCase is used as the label filter when none of the suites is affected by the change
+	releasePipelinesNoTestCase = "no-test-case"
+)
+
+var (
+	managedTaskPattern      = regexp.MustCompile(`^tasks/managed/[^/]+/[^/]+\.ya?ml$`)
+	internalTaskPattern     = regexp.MustCompile(`^tasks/internal/[^/]+/[^/]+\.ya?ml$`)
+	managedPipelinePattern  = regexp.MustCompile(`^pipelines/managed/[^/]+/[^/]+\.ya?ml$`)
+	internalPipelinePattern = regexp.MustCompile(`^pipelines/internal/[^/]+/[^/]+\.ya?ml$`)
+
+	// releasePipelinesTestCases are the managed pipelines which have a Ginkgo suite labeled with the pipeline name
+	releasePipelinesTestCases = []string{"rh-advisories", "fbc-release", "release-to-github", "push-to-external-registry", "rhtap-service-push", "rh-push-to-registry-redhat-io", "rh-push-to-external-registry"}
+
+	// internalToManagedPipelines maps internal pipelines to the managed pipelines running them
+	internalToManagedPipelines = map[string][]string{
+		"create-advisory":                         {"rh-advisories"},
+		"check-embargoed-cves":                    {"rh-advisories"},
+		"get-advisory-severity":                   {"rh-advisories"},
+		"filter-already-released-advisory-images": {"rh-advisories"},
+		"update-fbc-catalog":                      {"fbc-release"},
+		"publish-index-image-pipeline":            {"fbc-release"},
+		"process-file-updates":                    {"rh-advisories", "push-to-addons-registry", "rh-push-to-external-registry", "rh-push-to-registry-redhat-io"},
+		"push-artifacts-to-cdn":                   {"push-disk-images-to-cdn"},
+		"simple-signing-pipeline":                 {"fbc-release", "rh-advisories", "rh-push-to-external-registry", "rh-push-to-registry-redhat-io"},
+		"blob-signing-pipeline":                   {"release-to-github"},
+		"push-disk-images":                        {"push-disk-images-to-cdn", "push-disk-images-to-marketplaces"},
+	}
+)
+
+// ReleasePipelinesSelection holds the result of selecting the release pipelines suites to run
+// for a change in the release-service-catalog repository, together with the intermediate
+// data the decision was based on.
+type ReleasePipelinesSelection struct {
+	// SelectAll is set when the change affects all pipelines, e.g. a stepaction or the data keys schema
+	SelectAll       bool
+	SelectAllReason string
+
+	ChangedManagedTasks      []string
+	ChangedInternalTasks     []string
+	ChangedManagedPipelines  []string
+	ChangedInternalPipelines []string
+
+	// ManagedPipelines are the names of the managed pipelines affected by the change
+	ManagedPipelines []string
+	// InternalPipelines are the names of the internal pipelines affected by the change
+	InternalPipelines []string
+	// TestCases are the affected managed pipelines which have a test suite
+	TestCases []string
+}
+
+// LabelFilter returns the Ginkgo label filter selecting the affected release pipelines suites.
+func (s *ReleasePipelinesSelection) LabelFilter() string {
+	if s.SelectAll {
+		return releasePipelinesAllTestCases
+	}
+	if len(s.TestCases) == 0 {
+		return releasePipelinesNoTestCase
+	}
+	return strings.Join(s.TestCases, "||")
+}
+
+func (s *ReleasePipelinesSelection) String() string {
+	var sb strings.Builder
+	if s.SelectAll {
+		fmt.Fprintf(&sb, "all release pipelines selected: %s\n", s.SelectAllReason)
+	}
+	for _, l := range []struct {
+		name  string
+		items []string
+	}{
+		{"changed managed tasks", s.ChangedManagedTasks},
+		{"changed internal tasks", s.ChangedInternalTasks},
+		{"changed managed pipelines", s.ChangedManagedPipelines},
+		{"changed internal pipelines", s.ChangedInternalPipelines},
+		{"affected internal pipelines", s.InternalPipelines},
+		{"affected managed pipelines", s.ManagedPipelines},
+		{"selected test cases", s.TestCases},
+	} {
+		if len(l.items) > 0 {
+			fmt.Fprintf(&sb, "%s:\n  %s\n", l.name, strings.Join(l.items, "\n  "))
+		}
+	}
+	fmt.Fprintf(&sb, "label filter: %s", s.LabelFilter())
+	return sb.String()
+}
+
+// tektonResource contains the fields of Tekton Tasks and Pipelines needed for the selection
+type tektonResource struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Tasks   []tektonPipelineTask `json:"tasks"`
+		Finally []tektonPipelineTask `json:"finally"`
+	} `json:"spec"`
+}
+
+type tektonPipelineTask struct {
+	TaskRef *struct {
+		Params []struct {
+			Name  string `json:"name"`
+			Value any    `json:"value"`
+		} `json:"params"`
+	} `json:"taskRef"`
+}
+
+// referencesTask returns true if any of the pipeline tasks resolves the task from the given path in the repository
+func (r *tektonResource) referencesTask(taskPath string) bool {
+	for _, t := range append(r.Spec.Tasks, r.Spec.Finally...) {
+		if t.TaskRef == nil {
+			continue
+		}
+		for _, p := range t.TaskRef.Params {
+			if value, ok := p.Value.(string); ok && value == taskPath {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func readTektonResource(path string) (*tektonResource, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &tektonResource{}
+	if err := yaml.Unmarshal(content, r); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// SelectReleasePipelines decides which release pipelines suites to run for the files changed in
+// the release-service-catalog checkout in catalogDir. Paths of changedFiles are relative to catalogDir,
+// files which don't exist in the checkout (i.e. were removed) are ignored.
+func SelectReleasePipelines(catalogDir string, changedFiles []string) (*ReleasePipelinesSelection, error) {
+	s := &ReleasePipelinesSelection{}
+
+	for _, file := range changedFiles {
+		if strings.HasPrefix(file, "stepactions/") {
+			s.SelectAll, s.SelectAllReason = true, fmt.Sprintf("stepaction %s changed", file)
+			return s, nil
+		}
+		path := filepath.Join(catalogDir, file)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if file == "schema/dataKeys.json" {
+			s.SelectAll, s.SelectAllReason = true, fmt.Sprintf("%s changed", file)
+			return s, nil
+		}
+
+		var changed *[]string
+		var kind string
+		switch {
+		case managedTaskPattern.MatchString(file):
+			changed, kind = &s.ChangedManagedTasks, "Task"
+		case internalTaskPattern.MatchString(file):
+			changed, kind = &s.ChangedInternalTasks, "Task"
+		case managedPipelinePattern.MatchString(file):
+			changed, kind = &s.ChangedManagedPipelines, "Pipeline"
+		case internalPipelinePattern.MatchString(file):
+			changed, kind = &s.ChangedInternalPipelines, "Pipeline"
+		default:
+			continue
+		}
+		r, err := readTektonResource(path)
+		if err != nil {
+			return nil, err
+		}
+		if r.Kind == kind {
+			*changed = append(*changed, file)
+		}
+	}
+
+	var err error
+	if s.ManagedPipelines, err = findPipelinesUsingTasks(filepath.Join(catalogDir, "pipelines", "managed"), s.ChangedManagedTasks); err != nil {
+		return nil, err
+	}
+	if s.InternalPipelines, err = findPipelinesUsingTasks(filepath.Join(catalogDir, "pipelines", "internal"), s.ChangedInternalTasks); err != nil {
+		return nil, err
+	}
+	for _, l := range []struct {
+		files     []string
+		pipelines *[]string
+	}{
+		{s.ChangedManagedPipelines, &s.ManagedPipelines},
+		{s.ChangedInternalPipelines, &s.InternalPipelines},
+	} {
+		for _, file := range l.files {
+			r, err := readTektonResource(filepath.Join(catalogDir, file))
+			if err != nil {
+				return nil, err
+			}
+			*l.pipelines = appendUnique(*l.pipelines, r.Metadata.Name)
+		}
+	}
+
+	for _, internalPipeline := range s.InternalPipelines {
+		for _, name := range internalToManagedPipelines[internalPipeline] {
+			s.ManagedPipelines = appendUnique(s.ManagedPipelines, name)
+		}
+	}
+
+	for _, name := range s.ManagedPipelines {
+		if slices.Contains(releasePipelinesTestCases, name) {
+			s.TestCases = append(s.TestCases, name)
+		}
+	}
+	return s, nil
+}
+
+// findPipelinesUsingTasks returns the names of the pipelines in pipelinesDir referencing any of the given task paths
+func findPipelinesUsingTasks(pipelinesDir string, taskPaths []string) ([]string, error) {
+	if len(taskPaths) == 0 {
+		return nil, nil
+	}
+	files, err := filepath.Glob(filepath.Join(pipelinesDir, "*", "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, file := range files {
+		r, err := readTektonResource(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, taskPath := range taskPaths {
+			if !r.referencesTask(taskPath) {
+				continue
+			}
+			if r.Metadata.Name == "" {
+				return nil, fmt.Errorf("could not extract pipeline name from %s", file)
+			}
+			names = appendUnique(names, r.Metadata.Name)
+		}
+	}
+	return names, nil
+}
+
+func appendUnique(s []string, v string) []string {
+	if slices.Contains(s, v) {
+		return s
+	}
+	return append(s, v)
+}
+
+// selectReleasePipelinesForPR clones the release-service-catalog repository, checks out the given PR
+// and selects the release pipelines suites for the files changed by it.
+func selectReleasePipelinesForPR(prNum int) (*ReleasePipelinesSelection, error) {
+	dir, err := os.MkdirTemp("", "release-service-catalog-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	for _, args := range [][]string{
+		{"clone", "--quiet", fmt.Sprintf("https://github.com/%s.git", releaseServiceCatalogRepo), dir},
+		{"-C", dir, "fetch", "--quiet", "origin", fmt.Sprintf("pull/%d/head:pr_%d", prNum, prNum)},
+		{"-C", dir, "checkout", "--quiet", fmt.Sprintf("pr_%d", prNum)},
+	} {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("failed to run git %s: %w: %s", strings.Join(args, " "), err, string(output))
+		}
+	}
+
+	return PreviewReleasePipelinesSelection(dir)
+}
+
+// PreviewReleasePipelinesSelection selects the release pipelines suites for the changes of the currently
+// checked out branch of the release-service-catalog repository in catalogDir compared to its development branch.
+func PreviewReleasePipelinesSelection(catalogDir string) (*ReleasePipelinesSelection, error) {
+	output, err := exec.Command("git", "-C", catalogDir, "diff", "--name-only", fmt.Sprintf("origin/%s...HEAD", releaseServiceCatalogDefaultBranch)).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files in %s: %w", catalogDir, err)
+	}
+	changedFiles := strings.Fields(string(output))
+	klog.Infof("files changed in release-service-catalog: %v", changedFiles)
+
+	return SelectReleasePipelines(catalogDir, changedFiles)
+}
@@ -0,0 +1,85 @@
+package repos
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const releaseServiceCatalogFixture = "testdata/release-service-catalog"
+
+func TestSelectReleasePipelines(t *testing.T) {
+	for _, tc := range []struct {
+		name              string
+		changedFiles      []string
+		managedPipelines  []string
+		internalPipelines []string
+		labelFilter       string
+	}{
+		{
+			name:         "no tekton resources changed",
+			changedFiles: []string{"README.md", "tasks/managed/apply-mapping/README.md"},
+			labelFilter:  "no-test-case",
+		},
+		{
+			name:         "stepaction changed",
+			changedFiles: []string{"tasks/managed/apply-mapping/apply-mapping.yaml", "stepactions/skip-trusted-artifact-operations/skip.yaml"},
+			labelFilter:  "release-pipelines",
+		},
+		{
+			name:         "data keys schema changed",
+			changedFiles: []string{"schema/dataKeys.json"},
+			labelFilter:  "release-pipelines",
+		},
+		{
+			name:             "managed task used by several pipelines changed",
+			changedFiles:     []string{"tasks/managed/apply-mapping/apply-mapping.yaml"},
+			managedPipelines: []string{"push-to-addons-registry", "rh-advisories"},
+			labelFilter:      "rh-advisories",
+		},
+		{
+			name:             "managed pipeline and task changed",
+			changedFiles:     []string{"pipelines/managed/rh-advisories/rh-advisories.yaml", "tasks/managed/sign-index-image/sign-index-image.yaml"},
+			managedPipelines: []string{"fbc-release", "rh-advisories"},
+			labelFilter:      "fbc-release||rh-advisories",
+		},
+		{
+			name:              "internal task changed",
+			changedFiles:      []string{"tasks/internal/create-advisory-task/create-advisory-task.yaml"},
+			managedPipelines:  []string{"rh-advisories"},
+			internalPipelines: []string{"create-advisory"},
+			labelFilter:       "rh-advisories",
+		},
+		{
+			name:              "internal pipeline changed",
+			changedFiles:      []string{"pipelines/internal/create-advisory/create-advisory.yaml"},
+			managedPipelines:  []string{"rh-advisories"},
+			internalPipelines: []string{"create-advisory"},
+			labelFilter:       "rh-advisories",
+		},
+		{
+			name:         "removed files are ignored",
+			changedFiles: []string{"tasks/managed/removed/removed.yaml", "schema/removed.json"},
+			labelFilter:  "no-test-case",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := SelectReleasePipelines(releaseServiceCatalogFixture, tc.changedFiles)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tc.managedPipelines, s.ManagedPipelines)
+			assert.ElementsMatch(t, tc.internalPipelines, s.InternalPipelines)
+			assert.Equal(t, tc.labelFilter, s.LabelFilter())
+		})
+	}
+}
+
+func TestSelectReleasePipelinesInvalidResource(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "tasks", "managed", "broken"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "tasks", "managed", "broken", "broken.yaml"), []byte("kind: Task\n  metadata: [\n"), 0644))
+
+	_, err := SelectReleasePipelines(dir, []string{"tasks/managed/broken/broken.yaml"})
+	assert.ErrorContains(t, err, "failed to parse")
+}
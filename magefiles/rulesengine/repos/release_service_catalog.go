@@ -3,7 +3,6 @@ package repos
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 
@@ -51,12 +50,13 @@ var ReleaseServiceCatalogRepoSetDefaultSettingsRule = rulesengine.Rule{Name: "Ge
 	Actions: []rulesengine.Action{rulesengine.ActionFunc(func(rctx *rulesengine.RuleCtx) error {
 		// The job may come from different ITS
 		if strings.Contains(rctx.JobName, "konflux-e2e-tests-catalog") {
-			testcases, err := selectReleasePipelinesTestCases(rctx.PrNum)
+			selection, err := releasePipelinesSelectionForPR(rctx.PrNum)
 			if err != nil {
-				rctx.LabelFilter = "release-pipelines"
-				klog.Errorf("an error occurred in selectReleasePipelinesTestCases: %s", err)
+				rctx.LabelFilter = releasePipelinesAllTestCases
+				klog.Errorf("failed to select release pipelines test cases for PR %d: %s", rctx.PrNum, err)
 			} else {
-				rctx.LabelFilter = strings.ReplaceAll(testcases, " ", "||")
+				klog.Infof("release pipelines selection for PR %d:\n%s", rctx.PrNum, selection.String())
+				rctx.LabelFilter = selection.LabelFilter()
 			}
 		} else {
 			parts := strings.Split(rctx.JobName, "-e2e-test")
@@ -111,7 +111,7 @@ var isRehearse = func(rctx *rulesengine.RuleCtx) (bool, error) {
 }
 
 var isPaired = func(rctx *rulesengine.RuleCtx) (bool, error) {
-	rctx.IsPaired = IsPRPairingRequired("release-service", rctx.PrRemoteName, rctx.PrBranchName)
+	rctx.IsPaired = isPRPairingRequired("release-service", rctx.PrRemoteName, rctx.PrBranchName)
 	return rctx.IsPaired, nil
 }
 
@@ -124,15 +124,3 @@ func ExecuteReleaseCatalogAction(rctx *rulesengine.RuleCtx) error {
 	rctx.Timeout = 2*time.Hour + 30*time.Minute
 	return ExecuteTestAction(rctx)
 }
-
-func selectReleasePipelinesTestCases(prNum int) (string, error) {
-	command := fmt.Sprintf("%s %s %d", "magefiles/rulesengine/scripts/find_release_pipelines_from_pr.sh", "konflux-ci/release-service-catalog", prNum)
-	cmd := exec.Command("bash", "-c", command)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		klog.Errorf("failed in selectReleasePipelinesTestCases function for PR: %d", prNum)
-		klog.Infof("the output from find_release_pipelines_from_pr: %s", string(output))
-		return "", err
-	}
-	return string(output), nil
-}
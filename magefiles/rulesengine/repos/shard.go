@@ -0,0 +1,70 @@
+package repos
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/konflux-ci/e2e-tests/pkg/testspecs"
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
+	gtypes "github.com/onsi/ginkgo/v2/types"
+	"k8s.io/klog"
+)
+
+// ShardFocusFiles lists the specs selected by the label filter, focus and skip strings of the suite config
+// with a dry run of the e2e tests and returns the `ginkgo --focus-file` filters of the specs of the shard, i.e. `2/4`.
+// The specs are selected by their locations, since their texts can be generated at runtime and differ from the dry run.
+// The shards are balanced by the durations of the ginkgo JSON/JUnit reports found in historyDir, if set. No filter
+// means that the shard has no spec to run.
+func ShardFocusFiles(shard string, suiteConfig gtypes.SuiteConfig, historyDir string) ([]string, error) {
+
+	index, total, err := testspecs.ParseShard(shard)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "e2e-shard-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	inventoryFile := filepath.Join(dir, "specs-inventory.json")
+
+	args := []string{"--dry-run", "--no-color", "--label-filter=" + suiteConfig.LabelFilter}
+	for _, focus := range suiteConfig.FocusStrings {
+		args = append(args, "--focus="+focus)
+	}
+	for _, skip := range suiteConfig.SkipStrings {
+		args = append(args, "--skip="+skip)
+	}
+	args = append(args, utils.GetEnv("E2E_BIN_PATH", "./cmd"), "--")
+
+	// the e2e tests write the specs they would run into E2E_SPECS_INVENTORY when run with --dry-run
+	os.Setenv("E2E_SPECS_INVENTORY", inventoryFile)
+	defer os.Unsetenv("E2E_SPECS_INVENTORY")
+	if err := runGinkgo(args...); err != nil {
+		return nil, fmt.Errorf("failed to list the specs to shard: %w", err)
+	}
+	inventory, err := testspecs.LoadGinkgoReports(inventoryFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(inventory) != 1 {
+		return nil, fmt.Errorf("expected a single suite in the specs inventory, got %d", len(inventory))
+	}
+
+	var history []testspecs.SpecRun
+	if historyDir != "" {
+		if history, err = testspecs.LoadSpecRuns(historyDir); err != nil {
+			return nil, fmt.Errorf("failed to load the reports of the previous runs: %w", err)
+		}
+	}
+
+	shards, err := testspecs.ShardSpecs(inventory[0], history, total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to shard the specs: %w", err)
+	}
+	selected := shards[index-1]
+	klog.Infof("shard %d/%d runs %d specs expected to last %s", index, total, len(selected.Specs), selected.Duration)
+	return selected.FocusFiles, nil
+}
@@ -0,0 +1,94 @@
+package repos
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
+	"github.com/onsi/ginkgo/v2/reporters"
+	gtypes "github.com/onsi/ginkgo/v2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func shardSpecReport(leaf string, line int) gtypes.SpecReport {
+	return gtypes.SpecReport{
+		ContainerHierarchyTexts:     []string{"[build-templates-suite Build templates]"},
+		ContainerHierarchyLocations: []gtypes.CodeLocation{{FileName: "build_templates.go", LineNumber: 1}},
+		LeafNodeType:                gtypes.NodeTypeIt,
+		LeafNodeText:                leaf,
+		LeafNodeLocation:            gtypes.CodeLocation{FileName: "build_templates.go", LineNumber: line},
+		State:                       gtypes.SpecStatePassed,
+	}
+}
+
+// stubShardDryRun stubs the dry run of the e2e tests, writing the inventory of the specs, and records the ginkgo runs
+func stubShardDryRun(t *testing.T) *[][]string {
+	var runs [][]string
+	origRunGinkgo := runGinkgo
+	t.Cleanup(func() { runGinkgo = origRunGinkgo })
+	runGinkgo = func(args ...string) error {
+		runs = append(runs, args)
+		if !slices.Contains(args, "--dry-run") {
+			return nil
+		}
+		return reporters.GenerateJSONReport(gtypes.Report{SuiteDescription: "E2E", SpecReports: gtypes.SpecReports{
+			shardSpecReport("builds docker-build", 10),
+			shardSpecReport("builds docker-build-oci-ta", 11),
+			shardSpecReport("builds fbc-builder", 12),
+		}}, os.Getenv("E2E_SPECS_INVENTORY"))
+	}
+	return &runs
+}
+
+func TestShardFocusFiles(t *testing.T) {
+	runs := stubShardDryRun(t)
+
+	focusFiles, err := ShardFocusFiles("2/2", gtypes.SuiteConfig{LabelFilter: "build-templates", SkipStrings: []string{"fbc"}}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"--dry-run", "--no-color", "--label-filter=build-templates", "--skip=fbc", "./cmd", "--"}}, *runs)
+	assert.Empty(t, os.Getenv("E2E_SPECS_INVENTORY"))
+	assert.Equal(t, []string{`^build_templates\.go$:11`}, focusFiles)
+
+	filters, err := gtypes.ParseFileFilters(focusFiles)
+	assert.NoError(t, err)
+	// the text of the spec generated at runtime doesn't matter
+	generated := shardSpecReport("builds docker-build-oci-ta-1234", 11)
+	assert.True(t, filters.Matches(append(generated.ContainerHierarchyLocations, generated.LeafNodeLocation)))
+	other := shardSpecReport("builds docker-build", 10)
+	assert.False(t, filters.Matches(append(other.ContainerHierarchyLocations, other.LeafNodeLocation)))
+
+	focusFiles, err = ShardFocusFiles("4/4", gtypes.SuiteConfig{}, "")
+	assert.NoError(t, err)
+	assert.Empty(t, focusFiles)
+
+	_, err = ShardFocusFiles("5/4", gtypes.SuiteConfig{}, "")
+	assert.Error(t, err)
+}
+
+func TestExecuteTestActionShard(t *testing.T) {
+	runs := stubShardDryRun(t)
+	t.Setenv("E2E_SHARD", "1/2")
+	t.Setenv("GINKGO_PROCS", "4")
+
+	rctx := rulesengine.NewRuleCtx()
+	rctx.LabelFilter = "build-templates"
+	rctx.FocusStrings = []string{"docker"}
+	assert.NoError(t, ExecuteTestAction(rctx))
+
+	assert.Len(t, *runs, 2)
+	assert.Contains(t, (*runs)[0], "--focus=docker")
+	var focus []string
+	for _, arg := range (*runs)[1] {
+		if strings.HasPrefix(arg, "--focus") {
+			focus = append(focus, arg)
+		}
+	}
+	assert.Equal(t, []string{`--focus-file=^build_templates\.go$:10,12`}, focus)
+	assert.Equal(t, []string{"docker"}, rctx.FocusStrings)
+
+	t.Setenv("E2E_SHARD", "4/4")
+	assert.NoError(t, ExecuteTestAction(rctx))
+	assert.Len(t, *runs, 3, "an empty shard should not run ginkgo")
+}
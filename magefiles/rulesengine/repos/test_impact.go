@@ -0,0 +1,457 @@
+package repos
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/konflux-ci/e2e-tests/magefiles/rulesengine"
+	"github.com/onsi/ginkgo/v2/types"
+	"k8s.io/klog"
+)
+
+const (
+	// testImpactSuitesPackage is the package importing all the Ginkgo suites
+	testImpactSuitesPackage = "cmd"
+	// testImpactSuitesDir is the directory of the suite packages, the other packages cmd imports are test bootstrap code
+	testImpactSuitesDir = "tests"
+	// testImpactRuleDataKey is the key the TestImpact of the diff files is cached under in the RuleData
+	testImpactRuleDataKey = "testImpact"
+)
+
+// testImpactFullRunPatterns are the files bootstrapping the test run, a change of them requires to run all suites
+var testImpactFullRunPatterns = []string{"cmd/**", "pkg/framework/describe.go", "pkg/framework/framework.go", "go.mod", "go.sum"}
+
+// testImpactRootDir is the root of the Go module analyzed by the test impact analysis
+var testImpactRootDir = "."
+
+// TestImpact holds the Ginkgo suites affected by the changed files of a PR, determined by the
+// import graph of the packages of the repository, together with the data the decision was based on.
+type TestImpact struct {
+	// FullRun is set when the suites to run can't be narrowed, e.g. when the test bootstrap code changed
+	FullRun       bool
+	FullRunReason string
+
+	// ChangedPackages are the import paths of the packages containing the changed files
+	ChangedPackages []string
+	// Suites are the import paths of the suite packages which depend on any of the changed packages
+	Suites []string
+	// ContainerLabels are the label sets of the top level containers of the affected suites which are selected by the
+	// default PR label filter, a container is selected by all the labels of its set
+	ContainerLabels [][]string
+}
+
+// LabelFilter returns the Ginkgo label filter selecting the top level containers of the affected suites
+func (ti *TestImpact) LabelFilter() string {
+	if ti.FullRun {
+		return defaultPRLabelFilter
+	}
+	return fmt.Sprintf("(%s) && %s", strings.Join(ti.containerFilters(), " || "), defaultPRLabelFilter)
+}
+
+// labelSetFilter returns the label filter selecting the specs having all the labels
+func labelSetFilter(labels []string) string {
+	return strings.Join(labels, " && ")
+}
+
+func (ti *TestImpact) String() string {
+	var sb strings.Builder
+	if ti.FullRun {
+		fmt.Fprintf(&sb, "all suites are selected: %s\n", ti.FullRunReason)
+	}
+	for _, l := range []struct {
+		title string
+		items []string
+	}{
+		{"changed packages", ti.ChangedPackages},
+		{"affected suites", ti.Suites},
+		{"containers", ti.containerFilters()},
+	} {
+		if len(l.items) > 0 {
+			fmt.Fprintf(&sb, "%s: %s\n", l.title, strings.Join(l.items, ", "))
+		}
+	}
+	fmt.Fprintf(&sb, "label filter: %s", ti.LabelFilter())
+	return sb.String()
+}
+
+// containerFilters returns the label filters selecting each of the containers
+func (ti *TestImpact) containerFilters() []string {
+	var filters []string
+	for _, labels := range ti.ContainerLabels {
+		filter := labelSetFilter(labels)
+		if len(labels) > 1 && len(ti.ContainerLabels) > 1 {
+			filter = "(" + filter + ")"
+		}
+		filters = append(filters, filter)
+	}
+	return filters
+}
+
+func (ti *TestImpact) fullRun(format string, args ...any) *TestImpact {
+	ti.FullRun = true
+	ti.FullRunReason = fmt.Sprintf(format, args...)
+	return ti
+}
+
+// goPackage is a package of the analyzed module
+type goPackage struct {
+	dir string
+	// imports are the import paths of the packages of the module imported by the package, including its test files
+	imports []string
+}
+
+// AnalyzeTestImpact determines the suites, i.e. the packages under tests/ imported by the cmd package of the Go module
+// in rootDir, which transitively depend on the packages containing the changed files (paths relative to rootDir).
+// Non Go files are attributed to the package in their directory or its closest parent, e.g. embedded testdata.
+// All suites are selected when the test bootstrap code changed or when the impact can't be determined.
+func AnalyzeTestImpact(rootDir string, changedFiles []string) (*TestImpact, error) {
+	impact := &TestImpact{}
+
+	modulePath, err := readModulePath(filepath.Join(rootDir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	packages, err := loadModulePackages(rootDir, modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range changedFiles {
+		for _, pattern := range testImpactFullRunPatterns {
+			if matched, _ := doublestar.Match(pattern, file); matched {
+				return impact.fullRun("test bootstrap file %s changed", file), nil
+			}
+		}
+	}
+	for _, file := range changedFiles {
+		importPath, ok := owningPackage(packages, modulePath, file)
+		if !ok {
+			if strings.HasSuffix(file, ".go") {
+				return impact.fullRun("package of Go file %s not found", file), nil
+			}
+			continue
+		}
+		impact.ChangedPackages = appendUnique(impact.ChangedPackages, importPath)
+	}
+	sort.Strings(impact.ChangedPackages)
+
+	suitesPackage, ok := packages[path.Join(modulePath, testImpactSuitesPackage)]
+	if !ok {
+		return nil, fmt.Errorf("package %s importing the suites not found in %s", testImpactSuitesPackage, rootDir)
+	}
+	for _, suite := range suitesPackage.imports {
+		if !strings.HasPrefix(suite, path.Join(modulePath, testImpactSuitesDir)+"/") {
+			continue
+		}
+		deps := packageDeps(packages, suite)
+		if slices.ContainsFunc(impact.ChangedPackages, func(p string) bool { return deps[p] }) {
+			impact.Suites = append(impact.Suites, suite)
+		}
+	}
+	sort.Strings(impact.Suites)
+	if len(impact.Suites) == 0 {
+		return impact.fullRun("none of the suites depends on the changed files"), nil
+	}
+
+	prLabelFilter, err := types.ParseLabelFilter(defaultPRLabelFilter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the default PR label filter: %w", err)
+	}
+	for _, suite := range impact.Suites {
+		containers, err := suiteContainerLabels(filepath.Join(rootDir, packages[suite].dir))
+		if err != nil {
+			return impact.fullRun("can't determine labels of suite %s: %s", suite, err), nil
+		}
+		for _, labels := range containers {
+			// the containers excluded from the PR runs, e.g. the release pipelines, would make the label filter contradictory
+			if !prLabelFilter(labels) || slices.ContainsFunc(impact.ContainerLabels, func(l []string) bool { return slices.Equal(l, labels) }) {
+				continue
+			}
+			impact.ContainerLabels = append(impact.ContainerLabels, labels)
+		}
+	}
+	if len(impact.ContainerLabels) == 0 {
+		return impact.fullRun("none of the affected suites is selected by the default PR label filter"), nil
+	}
+	slices.SortFunc(impact.ContainerLabels, func(a, b []string) int { return strings.Compare(labelSetFilter(a), labelSetFilter(b)) })
+
+	return impact, nil
+}
+
+// readModulePath returns the module path declared in the go.mod file
+func readModulePath(goModPath string) (string, error) {
+	f, err := os.Open(goModPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if modulePath, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(modulePath), `"`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("module directive not found in %s", goModPath)
+}
+
+// loadModulePackages parses the imports of the Go files of the module and returns its packages by import path
+func loadModulePackages(rootDir, modulePath string) (map[string]*goPackage, error) {
+	packages := map[string]*goPackage{}
+	fset := token.NewFileSet()
+
+	err := filepath.WalkDir(rootDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != rootDir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") {
+			return nil
+		}
+
+		f, err := parser.ParseFile(fset, p, nil, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+		dir, err := filepath.Rel(rootDir, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		dir = filepath.ToSlash(dir)
+		importPath := path.Join(modulePath, dir)
+		pkg, ok := packages[importPath]
+		if !ok {
+			pkg = &goPackage{dir: dir}
+			packages[importPath] = pkg
+		}
+		for _, imp := range f.Imports {
+			imported, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(imported, modulePath+"/") {
+				pkg.imports = appendUnique(pkg.imports, imported)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the packages of %s: %w", rootDir, err)
+	}
+	return packages, nil
+}
+
+// owningPackage returns the import path of the package in the directory of the file or its closest parent directory
+func owningPackage(packages map[string]*goPackage, modulePath, file string) (string, bool) {
+	dir := path.Dir(filepath.ToSlash(file))
+	if strings.HasSuffix(file, ".go") {
+		importPath := path.Join(modulePath, dir)
+		_, ok := packages[importPath]
+		return importPath, ok
+	}
+	for {
+		if importPath := path.Join(modulePath, dir); packages[importPath] != nil {
+			return importPath, true
+		}
+		if dir == "." || dir == "/" {
+			return "", false
+		}
+		dir = path.Dir(dir)
+	}
+}
+
+// packageDeps returns the set of the packages of the module the package transitively depends on, including itself
+func packageDeps(packages map[string]*goPackage, importPath string) map[string]bool {
+	deps := map[string]bool{}
+	var visit func(p string)
+	visit = func(p string) {
+		if deps[p] {
+			return
+		}
+		deps[p] = true
+		if pkg, ok := packages[p]; ok {
+			for _, imp := range pkg.imports {
+				visit(imp)
+			}
+		}
+	}
+	visit(importPath)
+	return deps
+}
+
+// suiteContainerLabels returns the labels of every top level container of the suite in dir, which are inherited by all
+// its specs. Labels referenced by constants of the package are resolved.
+func suiteContainerLabels(dir string) ([][]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") || strings.HasSuffix(e.Name(), "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, e.Name()), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	consts := map[string]string{}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if i >= len(vs.Values) {
+						continue
+					}
+					if lit, ok := vs.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						consts[name.Name], _ = strconv.Unquote(lit.Value)
+					}
+				}
+			}
+		}
+	}
+
+	var containers [][]string
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if len(vs.Names) != 1 || vs.Names[0].Name != "_" || len(vs.Values) != 1 {
+					continue
+				}
+				container, ok := vs.Values[0].(*ast.CallExpr)
+				if !ok {
+					continue
+				}
+				labels, err := containerLabels(container, consts)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", fset.Position(container.Pos()), err)
+				}
+				containers = append(containers, labels)
+			}
+		}
+	}
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("no top level containers found in %s", dir)
+	}
+	return containers, nil
+}
+
+// containerLabels returns the sorted labels of the Label decorators of the container
+func containerLabels(container *ast.CallExpr, consts map[string]string) ([]string, error) {
+	var labels []string
+	for _, arg := range container.Args {
+		call, ok := arg.(*ast.CallExpr)
+		if !ok || calledFuncName(call) != "Label" {
+			continue
+		}
+		for _, labelArg := range call.Args {
+			label, ok := "", false
+			switch v := labelArg.(type) {
+			case *ast.BasicLit:
+				if v.Kind == token.STRING {
+					unquoted, err := strconv.Unquote(v.Value)
+					label, ok = unquoted, err == nil
+				}
+			case *ast.Ident:
+				label, ok = consts[v.Name]
+			}
+			if !ok {
+				return nil, fmt.Errorf("label of container %s can't be resolved", calledFuncName(container))
+			}
+			labels = appendUnique(labels, label)
+		}
+	}
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("container %s has no label", calledFuncName(container))
+	}
+	sort.Strings(labels)
+	return labels, nil
+}
+
+// calledFuncName returns the name of the called function without the package qualifier
+func calledFuncName(call *ast.CallExpr) string {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	}
+	return ""
+}
+
+// testImpactOf returns the TestImpact of the diff files of the rule context, it is cached in the RuleData
+func testImpactOf(rctx *rulesengine.RuleCtx) *TestImpact {
+	if impact, ok := rctx.GetRuleData(testImpactRuleDataKey).(*TestImpact); ok {
+		return impact
+	}
+
+	var changedFiles []string
+	for _, f := range rctx.DiffFiles {
+		changedFiles = append(changedFiles, f.Name)
+	}
+	impact, err := AnalyzeTestImpact(testImpactRootDir, changedFiles)
+	if err != nil {
+		klog.Errorf("failed to analyze the test impact of the changed files: %s", err)
+		impact = (&TestImpact{}).fullRun("test impact analysis failed: %s", err)
+	}
+	klog.Infof("test impact of the changed files:\n%s", impact.String())
+	_ = rctx.AddRuleData(testImpactRuleDataKey, impact)
+	return impact
+}
+
+// IsTestImpactNarrowed checks that only Go packages (besides the test bootstrap code) of the e2e-tests repository
+// changed and the suites depending on them were determined from the import graph of the repository
+func IsTestImpactNarrowed(rctx *rulesengine.RuleCtx) (bool, error) {
+	for _, check := range []rulesengine.ConditionFunc{CheckMageFilesChanged, CheckTektonFilesChanged, CheckReleasePipelinesTestsChanged} {
+		if ok, err := check(rctx); ok || err != nil {
+			return false, err
+		}
+	}
+	if ok, err := CheckPkgFilesChanged(rctx); !ok || err != nil {
+		return false, err
+	}
+
+	return !testImpactOf(rctx).FullRun, nil
+}
+
+// ExecuteTestImpactAction runs the suites which depend on the changed packages
+func ExecuteTestImpactAction(rctx *rulesengine.RuleCtx) error {
+	rctx.LabelFilter = testImpactOf(rctx).LabelFilter()
+	return ExecuteTestAction(rctx)
+}
@@ -0,0 +1,150 @@
+package repos
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testImpactFixture = "testdata/test-impact"
+
+func TestAnalyzeTestImpact(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		changedFiles    []string
+		fullRunReason   string
+		changedPackages []string
+		suites          []string
+		labelFilter     string
+	}{
+		{
+			name:            "utils package used by a single suite changed",
+			changedFiles:    []string{"pkg/utils/build/sbom.go"},
+			changedPackages: []string{"github.com/example/impact/pkg/utils/build"},
+			suites:          []string{"github.com/example/impact/tests/build"},
+			labelFilter:     "((HACBS && build-templates) || (build-service && github)) && " + defaultPRLabelFilter,
+		},
+		{
+			name:            "testdata of a package and docs changed",
+			changedFiles:    []string{"pkg/utils/build/testdata/sbom.json", "docs/README.md"},
+			changedPackages: []string{"github.com/example/impact/pkg/utils/build"},
+			suites:          []string{"github.com/example/impact/tests/build"},
+			labelFilter:     "((HACBS && build-templates) || (build-service && github)) && " + defaultPRLabelFilter,
+		},
+		{
+			name:            "package used through a test helper package changed",
+			changedFiles:    []string{"pkg/utils/release/release.go"},
+			changedPackages: []string{"github.com/example/impact/pkg/utils/release"},
+			suites:          []string{"github.com/example/impact/tests/release/pipelines", "github.com/example/impact/tests/release/service"},
+			labelFilter:     "(happy-path && release-service) && " + defaultPRLabelFilter,
+		},
+		{
+			name:            "package used only by suites excluded from the PR runs changed",
+			changedFiles:    []string{"pkg/utils/fbc/fbc.go"},
+			changedPackages: []string{"github.com/example/impact/pkg/utils/fbc"},
+			suites:          []string{"github.com/example/impact/tests/release/pipelines"},
+			fullRunReason:   "none of the affected suites is selected by the default PR label filter",
+			labelFilter:     defaultPRLabelFilter,
+		},
+		{
+			name:            "package used by the framework changed",
+			changedFiles:    []string{"pkg/utils/util.go", "tests/konflux-demo/const.go"},
+			changedPackages: []string{"github.com/example/impact/pkg/utils", "github.com/example/impact/tests/konflux-demo"},
+			suites: []string{
+				"github.com/example/impact/tests/build",
+				"github.com/example/impact/tests/konflux-demo",
+				"github.com/example/impact/tests/release/pipelines",
+				"github.com/example/impact/tests/release/service",
+			},
+			labelFilter: "((HACBS && build-templates) || (build-service && github) || (happy-path && release-service) || konflux) && " + defaultPRLabelFilter,
+		},
+		{
+			name:          "framework bootstrap changed",
+			changedFiles:  []string{"pkg/utils/build/sbom.go", "pkg/framework/framework.go"},
+			fullRunReason: "test bootstrap file pkg/framework/framework.go changed",
+			labelFilter:   defaultPRLabelFilter,
+		},
+		{
+			name:          "cmd changed",
+			changedFiles:  []string{"cmd/e2e_test.go"},
+			fullRunReason: "test bootstrap file cmd/e2e_test.go changed",
+			labelFilter:   defaultPRLabelFilter,
+		},
+		{
+			name:          "go file outside of the packages changed",
+			changedFiles:  []string{"hack/tool.go"},
+			fullRunReason: "package of Go file hack/tool.go not found",
+			labelFilter:   defaultPRLabelFilter,
+		},
+		{
+			name:            "package not used by the suites changed",
+			changedFiles:    []string{"pkg/utils/pyxis/pyxis.go"},
+			changedPackages: []string{"github.com/example/impact/pkg/utils/pyxis"},
+			fullRunReason:   "none of the suites depends on the changed files",
+			labelFilter:     defaultPRLabelFilter,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			impact, err := AnalyzeTestImpact(testImpactFixture, tc.changedFiles)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.fullRunReason != "", impact.FullRun)
+			assert.Equal(t, tc.fullRunReason, impact.FullRunReason)
+			assert.Equal(t, tc.changedPackages, impact.ChangedPackages)
+			assert.Equal(t, tc.suites, impact.Suites)
+			assert.Equal(t, tc.labelFilter, impact.LabelFilter())
+		})
+	}
+}
+
+func TestAnalyzeTestImpactOfRepository(t *testing.T) {
+	// the framework is imported by cmd and by all the suites, but it isn't a suite itself
+	impact, err := AnalyzeTestImpact("../../..", []string{"pkg/framework/failure_report.go"})
+	assert.NoError(t, err)
+	assert.False(t, impact.FullRun, impact.FullRunReason)
+	assert.Equal(t, []string{"github.com/konflux-ci/e2e-tests/pkg/framework"}, impact.ChangedPackages)
+	assert.Contains(t, impact.Suites, "github.com/konflux-ci/e2e-tests/tests/build")
+	assert.Contains(t, impact.Suites, "github.com/konflux-ci/e2e-tests/tests/release/pipelines")
+	for _, suite := range impact.Suites {
+		assert.True(t, strings.HasPrefix(suite, "github.com/konflux-ci/e2e-tests/tests/"), suite)
+	}
+
+	filter, err := types.ParseLabelFilter(impact.LabelFilter())
+	if !assert.NoError(t, err, impact.LabelFilter()) {
+		return
+	}
+	assert.True(t, filter([]string{"build", "build-templates", "HACBS", "pipeline-service"}))
+	assert.True(t, filter([]string{"release-service", "happy-path"}))
+	assert.True(t, filter([]string{"integration-service", "gitlab-status-reporting"}))
+	assert.False(t, filter([]string{"release-pipelines", "fbc-release"}))
+	assert.False(t, filter([]string{"upgrade-verify"}))
+	assert.NotContains(t, impact.LabelFilter(), "(release-pipelines")
+}
+
+func TestAnalyzeTestImpactUnlabeledContainer(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod": "module example.com/unlabeled\n",
+		"cmd/e2e_test.go": `package cmd
+
+import _ "example.com/unlabeled/tests/upgrade"
+`,
+		"tests/upgrade/upgrade.go": `package upgrade
+
+import "github.com/onsi/ginkgo/v2"
+
+var _ = ginkgo.Describe("Upgrade", ginkgo.Ordered, func() {})
+`,
+	} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	impact, err := AnalyzeTestImpact(dir, []string{"tests/upgrade/upgrade.go"})
+	assert.NoError(t, err)
+	assert.True(t, impact.FullRun)
+	assert.Contains(t, impact.FullRunReason, "container Describe has no label")
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/example/impact/pkg/framework"
+	"github.com/example/impact/pkg/utils"
+	_ "github.com/example/impact/tests/build"
+	_ "github.com/example/impact/tests/konflux-demo"
+	_ "github.com/example/impact/tests/release/pipelines"
+	_ "github.com/example/impact/tests/release/service"
+)
+
+var _ = utils.GetEnv
+
+func TestE2E(t *testing.T) { _ = framework.Framework{} }
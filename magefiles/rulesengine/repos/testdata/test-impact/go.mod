@@ -0,0 +1,3 @@
+module github.com/example/impact
+
+go 1.25.0
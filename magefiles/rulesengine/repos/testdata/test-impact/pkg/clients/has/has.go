@@ -0,0 +1,7 @@
+package has
+
+import "github.com/example/impact/pkg/utils"
+
+type HasController struct{}
+
+var _ = utils.GetEnv
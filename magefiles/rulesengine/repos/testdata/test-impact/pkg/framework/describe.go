@@ -0,0 +1,9 @@
+package framework
+
+func BuildSuiteDescribe(text string, args ...interface{}) bool { return true }
+
+func ReleaseServiceSuiteDescribe(text string, args ...interface{}) bool { return true }
+
+func KonfluxDemoSuiteDescribe(args ...interface{}) bool { return true }
+
+func ReleasePipelinesSuiteDescribe(text string, args ...interface{}) bool { return true }
@@ -0,0 +1,7 @@
+package framework
+
+import "github.com/example/impact/pkg/clients/has"
+
+type Framework struct {
+	HasController *has.HasController
+}
@@ -0,0 +1,5 @@
+package build
+
+import "github.com/example/impact/pkg/utils"
+
+func ValidateSbom() string { return utils.GetEnv("SBOM", "") }
@@ -0,0 +1,3 @@
+package fbc
+
+func BuildFragment() {}
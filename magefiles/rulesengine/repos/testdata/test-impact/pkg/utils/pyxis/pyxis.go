@@ -0,0 +1,3 @@
+package pyxis
+
+func Serve() {}
@@ -0,0 +1,3 @@
+package release
+
+func NewReleasePlan() {}
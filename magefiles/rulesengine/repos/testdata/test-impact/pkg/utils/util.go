@@ -0,0 +1,3 @@
+package utils
+
+func GetEnv(key, defaultValue string) string { return defaultValue }
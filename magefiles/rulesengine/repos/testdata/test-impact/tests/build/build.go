@@ -0,0 +1,11 @@
+package build
+
+import (
+	"github.com/example/impact/pkg/framework"
+	"github.com/example/impact/pkg/utils/build"
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = build.ValidateSbom
+
+var _ = framework.BuildSuiteDescribe("Build service E2E tests", Label("build-service", "github"), func() {})
@@ -0,0 +1,10 @@
+package build
+
+import (
+	"github.com/example/impact/pkg/framework"
+	ginkgo "github.com/onsi/ginkgo/v2"
+)
+
+const buildTemplatesTestLabel = "build-templates"
+
+var _ = framework.BuildSuiteDescribe("Build templates E2E test", ginkgo.Label(buildTemplatesTestLabel, "HACBS"), func() {})
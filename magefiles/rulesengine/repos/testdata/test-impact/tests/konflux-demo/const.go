@@ -0,0 +1,3 @@
+package konflux_demo
+
+const devEnvTestLabel = "konflux"
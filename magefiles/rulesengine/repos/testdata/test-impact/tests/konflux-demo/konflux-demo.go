@@ -0,0 +1,8 @@
+package konflux_demo
+
+import (
+	"github.com/example/impact/pkg/framework"
+	"github.com/onsi/ginkgo/v2"
+)
+
+var _ = framework.KonfluxDemoSuiteDescribe(ginkgo.Label(devEnvTestLabel), func() {})
@@ -0,0 +1,14 @@
+package pipelines
+
+import (
+	"github.com/example/impact/pkg/framework"
+	"github.com/example/impact/pkg/utils/fbc"
+	releasecommon "github.com/example/impact/tests/release"
+	"github.com/onsi/ginkgo/v2"
+)
+
+var _ = releasecommon.NewReleasePlan
+
+var _ = fbc.BuildFragment
+
+var _ = framework.ReleasePipelinesSuiteDescribe("FBC e2e-tests", ginkgo.Pending, ginkgo.Label("release-pipelines", "fbc-release"), func() {})
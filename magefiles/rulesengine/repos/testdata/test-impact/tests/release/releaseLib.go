@@ -0,0 +1,5 @@
+package release
+
+import "github.com/example/impact/pkg/utils/release"
+
+var NewReleasePlan = release.NewReleasePlan
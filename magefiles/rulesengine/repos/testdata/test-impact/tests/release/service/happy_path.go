@@ -0,0 +1,11 @@
+package service
+
+import (
+	"github.com/example/impact/pkg/framework"
+	releasecommon "github.com/example/impact/tests/release"
+	"github.com/onsi/ginkgo/v2"
+)
+
+var _ = releasecommon.NewReleasePlan
+
+var _ = framework.ReleaseServiceSuiteDescribe("Release service happy path", ginkgo.Label("release-service", "happy-path"), func() {})
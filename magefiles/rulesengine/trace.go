@@ -0,0 +1,248 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// DecisionTraceFileName is the name of the file the decision trace is stored in by DecisionTrace.Store
+const DecisionTraceFileName = "rules-engine-decision-trace.json"
+
+// Types of the nodes recorded in a DecisionTrace
+const (
+	TraceNodeRule      = "Rule"
+	TraceNodeAll       = "All"
+	TraceNodeAny       = "Any"
+	TraceNodeNone      = "None"
+	TraceNodeCondition = "Condition"
+	TraceNodeActions   = "Actions"
+)
+
+// DecisionTrace records the evaluation tree of the rules run by the engine, it is enabled by setting
+// RuleCtx.DecisionTrace before running the rules.
+type DecisionTrace struct {
+	Nodes []*TraceNode `json:"nodes"`
+	stack []*TraceNode
+}
+
+// TraceNode is a single evaluated Rule, filter (All, Any, None), condition or the executed actions of a rule.
+type TraceNode struct {
+	Type   string `json:"type"`
+	Name   string `json:"name,omitempty"`
+	Result bool   `json:"result"`
+	Error  string `json:"error,omitempty"`
+	// MatchedFiles are the diff files matched by the Files filters during the evaluation of the node
+	MatchedFiles []string `json:"matchedFiles,omitempty"`
+	// LabelFilter is set on Actions nodes which changed the label filter
+	LabelFilter *LabelFilterChange `json:"labelFilter,omitempty"`
+	// AddedFocusFiles are the focus files added by Actions nodes
+	AddedFocusFiles []string     `json:"addedFocusFiles,omitempty"`
+	Children        []*TraceNode `json:"children,omitempty"`
+}
+
+// LabelFilterChange describes a mutation of the label filter by the actions of a rule
+type LabelFilterChange struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// NewDecisionTrace returns an empty DecisionTrace
+func NewDecisionTrace() *DecisionTrace {
+	return &DecisionTrace{}
+}
+
+// begin adds a new node as a child of the node being evaluated and makes it the node being evaluated
+func (t *DecisionTrace) begin(nodeType, name string) *TraceNode {
+	if t == nil {
+		return nil
+	}
+	node := &TraceNode{Type: nodeType, Name: name}
+	t.push(node)
+	return node
+}
+
+// push makes an already recorded node the node being evaluated, so that nodes evaluated later
+// (e.g. the actions of a matched rule) are attached to it
+func (t *DecisionTrace) push(node *TraceNode) {
+	if t == nil || node == nil {
+		return
+	}
+	if len(t.stack) == 0 {
+		if !slices.Contains(t.Nodes, node) {
+			t.Nodes = append(t.Nodes, node)
+		}
+	} else if parent := t.stack[len(t.stack)-1]; !slices.Contains(parent.Children, node) {
+		parent.Children = append(parent.Children, node)
+	}
+	t.stack = append(t.stack, node)
+}
+
+// pop returns to the parent of the node being evaluated without changing its result
+func (t *DecisionTrace) pop() {
+	if t == nil || len(t.stack) == 0 {
+		return
+	}
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+// end records the result of the node being evaluated and returns to its parent
+func (t *DecisionTrace) end(node *TraceNode, result bool, err error) {
+	if t == nil || node == nil {
+		return
+	}
+	node.Result = result
+	if err != nil {
+		node.Error = err.Error()
+	}
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+// recordFiles adds the matched files to the node being evaluated
+func (t *DecisionTrace) recordFiles(files Files) {
+	if t == nil || len(t.stack) == 0 {
+		return
+	}
+	node := t.stack[len(t.stack)-1]
+	for _, f := range files {
+		if !slices.Contains(node.MatchedFiles, f.Name) {
+			node.MatchedFiles = append(node.MatchedFiles, f.Name)
+		}
+	}
+}
+
+// traceActions executes the given actions as an Actions node recording the mutations of the label filter and focus files
+func traceActions(rctx *RuleCtx, actions []Action) error {
+	node := rctx.DecisionTrace.begin(TraceNodeActions, "")
+	labelFilter, focusFiles := rctx.LabelFilter, slices.Clone(rctx.FocusFiles)
+
+	var err error
+	for _, action := range actions {
+		if err = action.Execute(rctx); err != nil {
+			break
+		}
+	}
+
+	if node != nil {
+		if rctx.LabelFilter != labelFilter {
+			node.LabelFilter = &LabelFilterChange{Before: labelFilter, After: rctx.LabelFilter}
+		}
+		for _, f := range rctx.FocusFiles {
+			if !slices.Contains(focusFiles, f) {
+				node.AddedFocusFiles = append(node.AddedFocusFiles, f)
+			}
+		}
+	}
+	rctx.DecisionTrace.end(node, err == nil, err)
+	return err
+}
+
+// namedCondition is a ConditionFunc recorded in the DecisionTrace under a given name
+type namedCondition struct {
+	name string
+	cf   ConditionFunc
+}
+
+// NamedCondition returns a Conditional evaluating the given function which is recorded in the DecisionTrace
+// under the given name instead of the name of the function, e.g. for conditions created from closures.
+func NamedCondition(name string, cf ConditionFunc) Conditional {
+	return &namedCondition{name: name, cf: cf}
+}
+
+func (nc *namedCondition) Check(rctx *RuleCtx) (bool, error) {
+	node := rctx.DecisionTrace.begin(TraceNodeCondition, nc.name)
+	ok, err := nc.cf(rctx)
+	rctx.DecisionTrace.end(node, ok, err)
+	return ok, err
+}
+
+// conditionName returns the name of the function implementing a ConditionFunc, e.g. repos.CheckPkgFilesChanged
+func conditionName(cf ConditionFunc) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(cf).Pointer())
+	if fn == nil {
+		return ""
+	}
+	name := fn.Name()
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// MatchedRules returns the names of the rules of the evaluated catalogs whose condition was met
+func (t *DecisionTrace) MatchedRules() []string {
+	var names []string
+	for _, node := range t.Nodes {
+		if node.Type == TraceNodeRule && node.Result {
+			names = append(names, node.Name)
+		}
+	}
+	return names
+}
+
+// AppliedRules returns the names of the rules, including the rules chained in conditions,
+// whose actions were executed successfully, in the order of their execution
+func (t *DecisionTrace) AppliedRules() []string {
+	var names []string
+	var walk func(node *TraceNode)
+	walk = func(node *TraceNode) {
+		for _, child := range node.Children {
+			if node.Type == TraceNodeRule && child.Type == TraceNodeActions && child.Result {
+				names = append(names, node.Name)
+			}
+			walk(child)
+		}
+	}
+	for _, node := range t.Nodes {
+		walk(node)
+	}
+	return names
+}
+
+// JSON returns the trace serialized as indented JSON
+func (t *DecisionTrace) JSON() ([]byte, error) {
+	return json.MarshalIndent(t, "", "  ")
+}
+
+// String renders the trace as a tree
+func (t *DecisionTrace) String() string {
+	var sb strings.Builder
+	for _, node := range t.Nodes {
+		writeTraceNode(&sb, node, "")
+	}
+	return sb.String()
+}
+
+func writeTraceNode(sb *strings.Builder, node *TraceNode, indent string) {
+	fmt.Fprintf(sb, "%s[%t] %s", indent, node.Result, node.Type)
+	if node.Name != "" {
+		fmt.Fprintf(sb, " %s", node.Name)
+	}
+	if node.Error != "" {
+		fmt.Fprintf(sb, " error: %s", node.Error)
+	}
+	sb.WriteString("\n")
+	if len(node.MatchedFiles) > 0 {
+		fmt.Fprintf(sb, "%s    files: %s\n", indent, strings.Join(node.MatchedFiles, ", "))
+	}
+	if node.LabelFilter != nil {
+		fmt.Fprintf(sb, "%s    label filter: %q -> %q\n", indent, node.LabelFilter.Before, node.LabelFilter.After)
+	}
+	if len(node.AddedFocusFiles) > 0 {
+		fmt.Fprintf(sb, "%s    focus files: +%s\n", indent, strings.Join(node.AddedFocusFiles, ", +"))
+	}
+	for _, child := range node.Children {
+		writeTraceNode(sb, child, indent+"  ")
+	}
+}
+
+// Store writes the trace as JSON into the given directory
+func (t *DecisionTrace) Store(dir string) error {
+	data, err := t.JSON()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, DecisionTraceFileName), data, 0644)
+}
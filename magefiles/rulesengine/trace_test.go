@@ -0,0 +1,135 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func checkBuildFilesChanged(rctx *RuleCtx) (bool, error) {
+	return len(rctx.DiffFilesByDirGlob("tests/build/*.go")) != 0, nil
+}
+
+func checkPkgFilesChanged(rctx *RuleCtx) (bool, error) {
+	return len(rctx.DiffFilesByDirString("pkg/")) != 0, nil
+}
+
+var buildLabelRule = Rule{Name: "build label rule",
+	Condition: ConditionFunc(checkBuildFilesChanged),
+	Actions: []Action{ActionFunc(func(rctx *RuleCtx) error {
+		rctx.LabelFilter = "build"
+		return nil
+	})},
+}
+
+var traceTestCatalog = RuleCatalog{
+	{Name: "pkg rule",
+		Condition: Any{ConditionFunc(checkPkgFilesChanged)},
+		Actions: []Action{ActionFunc(func(rctx *RuleCtx) error {
+			rctx.LabelFilter = "all"
+			return nil
+		})},
+	},
+	{Name: "build rule",
+		Condition: All{
+			None{ConditionFunc(checkPkgFilesChanged)},
+			&buildLabelRule,
+		},
+		Actions: []Action{ActionFunc(func(rctx *RuleCtx) error {
+			rctx.FocusFiles = append(rctx.FocusFiles, "tests/build/build.go")
+			return nil
+		})},
+	},
+}
+
+func TestDecisionTrace(t *testing.T) {
+	engine := RuleEngine{"tests": {"trace": traceTestCatalog}}
+	rctx := NewRuleCtx()
+	rctx.DryRun = true
+	rctx.DiffFiles = Files{{Status: "M", Name: "tests/build/build.go"}, {Status: "M", Name: "README.md"}}
+	rctx.DecisionTrace = NewDecisionTrace()
+
+	assert.NoError(t, engine.RunRules(rctx, "tests", "trace"))
+	assert.Equal(t, "build", rctx.LabelFilter)
+
+	nodes := rctx.DecisionTrace.Nodes
+	assert.Len(t, nodes, 2)
+	assert.Equal(t, TraceNodeRule, nodes[0].Type)
+	assert.False(t, nodes[0].Result)
+	assert.Equal(t, "rulesengine.checkPkgFilesChanged", nodes[0].Children[0].Children[0].Name)
+
+	buildRule := nodes[1]
+	assert.True(t, buildRule.Result)
+	all := buildRule.Children[0]
+	assert.Equal(t, TraceNodeAll, all.Type)
+	assert.Equal(t, TraceNodeNone, all.Children[0].Type)
+	assert.True(t, all.Children[0].Result)
+
+	chained := all.Children[1]
+	assert.Equal(t, "build label rule", chained.Name)
+	assert.Equal(t, []string{"tests/build/build.go"}, chained.Children[0].MatchedFiles)
+	assert.Equal(t, &LabelFilterChange{Before: "", After: "build"}, chained.Children[1].LabelFilter)
+
+	// The actions of the matched rule are recorded under its node
+	actions := buildRule.Children[1]
+	assert.Equal(t, TraceNodeActions, actions.Type)
+	assert.Equal(t, []string{"tests/build/build.go"}, actions.AddedFocusFiles)
+
+	assert.Equal(t, []string{"build rule"}, rctx.DecisionTrace.MatchedRules())
+	assert.Equal(t, []string{"build label rule", "build rule"}, rctx.DecisionTrace.AppliedRules())
+
+	data, err := rctx.DecisionTrace.JSON()
+	assert.NoError(t, err)
+	assert.True(t, json.Valid(data))
+	assert.Contains(t, rctx.DecisionTrace.String(), "[true] Rule build rule\n")
+}
+
+func TestDecisionTraceOfConcurrentEngines(t *testing.T) {
+	engine := RuleEngine{"tests": {"trace": traceTestCatalog}}
+	done := make(chan *RuleCtx)
+	for _, file := range []string{"tests/build/build.go", "pkg/utils/util.go"} {
+		go func() {
+			rctx := NewRuleCtx()
+			rctx.DryRun = true
+			rctx.DiffFiles = Files{{Status: "M", Name: file}}
+			rctx.DecisionTrace = NewDecisionTrace()
+			assert.NoError(t, engine.RunRules(rctx, "tests", "trace"))
+			done <- rctx
+		}()
+	}
+
+	// every trace only records the files of its own context
+	for range 2 {
+		rctx := <-done
+		data, err := rctx.DecisionTrace.JSON()
+		assert.NoError(t, err)
+		for _, other := range []string{"tests/build/build.go", "pkg/utils/util.go"} {
+			if other != rctx.DiffFiles[0].Name {
+				assert.NotContains(t, string(data), other)
+			} else {
+				assert.Contains(t, string(data), other)
+			}
+		}
+	}
+}
+
+func TestDryRunAppliesAllMatchedRules(t *testing.T) {
+	var applied []string
+	catalog := RuleCatalog{}
+	for _, name := range []string{"first", "second"} {
+		catalog = append(catalog, Rule{Name: name,
+			Condition: ConditionFunc(func(rctx *RuleCtx) (bool, error) { return true, nil }),
+			Actions: []Action{ActionFunc(func(rctx *RuleCtx) error {
+				applied = append(applied, name)
+				return nil
+			})},
+		})
+	}
+	engine := RuleEngine{"tests": {"dry-run": catalog}}
+	rctx := NewRuleCtx()
+	rctx.DryRun = true
+
+	assert.NoError(t, engine.RunRules(rctx, "tests", "dry-run"))
+	assert.Equal(t, []string{"first", "second"}, applied)
+}
@@ -125,8 +125,11 @@ func (e *RuleEngine) RunRulesOfCategory(cat string, rctx *RuleCtx) error {
 func (e *RuleEngine) runLoadedCatalog(loaded RuleCatalog, rctx *RuleCtx) error {
 
 	var matched RuleCatalog
+	var matchedNodes []*TraceNode
 	for _, rule := range loaded {
+		node := rctx.DecisionTrace.begin(TraceNodeRule, rule.Name)
 		ok, err := rule.Eval(rctx)
+		rctx.DecisionTrace.end(node, ok, err)
 		if err != nil {
 			return err
 		}
@@ -144,6 +147,7 @@ func (e *RuleEngine) runLoadedCatalog(loaded RuleCatalog, rctx *RuleCtx) error {
 		}
 		if ok {
 			matched = append(matched, rule)
+			matchedNodes = append(matchedNodes, node)
 		}
 	}
 
@@ -155,32 +159,40 @@ func (e *RuleEngine) runLoadedCatalog(loaded RuleCatalog, rctx *RuleCtx) error {
 	klog.Infof("The following rules have matched %s.", matched.String())
 	if rctx.DryRun {
 
-		return e.dryRun(matched, rctx)
+		return e.dryRun(matched, matchedNodes, rctx)
 
 	}
 
-	return e.run(matched, rctx)
+	return e.run(matched, matchedNodes, rctx)
 
 }
 
-func (e *RuleEngine) dryRun(matched RuleCatalog, rctx *RuleCtx) error {
+func (e *RuleEngine) dryRun(matched RuleCatalog, nodes []*TraceNode, rctx *RuleCtx) error {
 
 	klog.Info("DryRun has been enabled will apply them in dry run mode")
-	for _, rule := range matched {
+	for i, rule := range matched {
 
-		return rule.DryRun(rctx)
+		rctx.DecisionTrace.push(nodes[i])
+		err := rule.DryRun(rctx)
+		rctx.DecisionTrace.pop()
+
+		if err != nil {
+			return err
+		}
 
 	}
 
 	return nil
 }
 
-func (e *RuleEngine) run(matched RuleCatalog, rctx *RuleCtx) error {
+func (e *RuleEngine) run(matched RuleCatalog, nodes []*TraceNode, rctx *RuleCtx) error {
 
 	klog.Info("Will apply rules")
-	for _, rule := range matched {
+	for i, rule := range matched {
 
+		rctx.DecisionTrace.push(nodes[i])
 		err := rule.Apply(rctx)
+		rctx.DecisionTrace.pop()
 
 		if err != nil {
 			klog.Errorf("Failed to execute rule: %s", rule.String())
@@ -217,6 +229,14 @@ type Any []Conditional
 
 func (a Any) Check(rctx *RuleCtx) (bool, error) {
 
+	node := rctx.DecisionTrace.begin(TraceNodeAny, "")
+	ok, err := a.check(rctx)
+	rctx.DecisionTrace.end(node, ok, err)
+	return ok, err
+}
+
+func (a Any) check(rctx *RuleCtx) (bool, error) {
+
 	// Initial logic was to pass on the first
 	// eval to true but that might not be the
 	// case. So not eval all and as long as any
@@ -240,6 +260,14 @@ type All []Conditional
 
 func (a All) Check(rctx *RuleCtx) (bool, error) {
 
+	node := rctx.DecisionTrace.begin(TraceNodeAll, "")
+	ok, err := a.check(rctx)
+	rctx.DecisionTrace.end(node, ok, err)
+	return ok, err
+}
+
+func (a All) check(rctx *RuleCtx) (bool, error) {
+
 	for _, c := range a {
 
 		ok, err := c.Check(rctx)
@@ -258,6 +286,14 @@ type None []Conditional
 
 func (a None) Check(rctx *RuleCtx) (bool, error) {
 
+	node := rctx.DecisionTrace.begin(TraceNodeNone, "")
+	ok, err := a.check(rctx)
+	rctx.DecisionTrace.end(node, ok, err)
+	return ok, err
+}
+
+func (a None) check(rctx *RuleCtx) (bool, error) {
+
 	for _, c := range a {
 
 		ok, err := c.Check(rctx)
@@ -282,7 +318,14 @@ func (af ActionFunc) Execute(rctx *RuleCtx) error {
 type ConditionFunc func(rctx *RuleCtx) (bool, error)
 
 func (cf ConditionFunc) Check(rctx *RuleCtx) (bool, error) {
-	return cf(rctx)
+	if rctx.DecisionTrace == nil {
+		return cf(rctx)
+	}
+
+	node := rctx.DecisionTrace.begin(TraceNodeCondition, conditionName(cf))
+	ok, err := cf(rctx)
+	rctx.DecisionTrace.end(node, ok, err)
+	return ok, err
 }
 
 type Rule struct {
@@ -310,32 +353,24 @@ func (r *Rule) Eval(rctx *RuleCtx) (bool, error) {
 
 func (r *Rule) Apply(rctx *RuleCtx) error {
 
-	for _, action := range r.Actions {
-
-		err := action.Execute(rctx)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return traceActions(rctx, r.Actions)
 }
 
 func (r *Rule) DryRun(rctx *RuleCtx) error {
 
 	rctx.DryRun = true
-	for _, action := range r.Actions {
+	return traceActions(rctx, r.Actions)
+}
 
-		err := action.Execute(rctx)
-		if err != nil {
-			return err
-		}
-	}
+func (r *Rule) Check(rctx *RuleCtx) (bool, error) {
 
-	return nil
+	node := rctx.DecisionTrace.begin(TraceNodeRule, r.Name)
+	ok, err := r.check(rctx)
+	rctx.DecisionTrace.end(node, ok, err)
+	return ok, err
 }
 
-func (r *Rule) Check(rctx *RuleCtx) (bool, error) {
+func (r *Rule) check(rctx *RuleCtx) (bool, error) {
 
 	ok, err := r.Eval(rctx)
 	if err != nil {
@@ -373,7 +408,6 @@ func (cfs *Files) FilterByDirString(filter string) Files {
 
 		subfiles = append(subfiles, file)
 	}
-
 	return subfiles
 
 }
@@ -391,7 +425,6 @@ func (cfs *Files) FilterByDirGlob(filter string) Files {
 
 		subfiles = append(subfiles, file)
 	}
-
 	return subfiles
 
 }
@@ -451,6 +484,22 @@ type RuleCtx struct {
 	TektonEventType               string
 	RequiresMultiPlatformTests    bool
 	RequiresSprayProxyRegistering bool
+	// DecisionTrace records the evaluation of the rules when set
+	DecisionTrace *DecisionTrace
+}
+
+// DiffFilesByDirString returns the diff files whose name contains the filter, recording them in the DecisionTrace
+func (rctx *RuleCtx) DiffFilesByDirString(filter string) Files {
+	files := rctx.DiffFiles.FilterByDirString(filter)
+	rctx.DecisionTrace.recordFiles(files)
+	return files
+}
+
+// DiffFilesByDirGlob returns the diff files matching the glob, recording them in the DecisionTrace
+func (rctx *RuleCtx) DiffFilesByDirGlob(filter string) Files {
+	files := rctx.DiffFiles.FilterByDirGlob(filter)
+	rctx.DecisionTrace.recordFiles(files)
+	return files
 }
 
 func NewRuleCtx() *RuleCtx {
@@ -480,7 +529,8 @@ func NewRuleCtx() *RuleCtx {
 		0,
 		"",
 		false,
-		false}
+		false,
+		nil}
 
 	//init defaults we've used so far
 	t, _ := time.ParseDuration("90m")
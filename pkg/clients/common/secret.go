@@ -63,6 +63,28 @@ func (s *SuiteController) DeleteSecretsByLabel(ns string, labelKey string, label
 	return nil
 }
 
+// DeleteAllUnmanagedSecretsInASpecificNamespace deletes the secrets of a namespace created by the tests: the service
+// account tokens and dockercfg secrets, and the secrets owned by another resource, are left to their controllers
+func (s *SuiteController) DeleteAllUnmanagedSecretsInASpecificNamespace(ns string) error {
+	secretList, err := s.KubeInterface().CoreV1().Secrets(ns).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range secretList.Items {
+		if secret.Type == corev1.SecretTypeServiceAccountToken || len(secret.OwnerReferences) > 0 {
+			continue
+		}
+		if _, ok := secret.Annotations[corev1.ServiceAccountNameKey]; ok {
+			continue
+		}
+		if err := s.DeleteSecret(ns, secret.Name); err != nil && !k8sErrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
 // Links a secret to a specified serviceaccount, if argument addImagePullSecrets is true secret will be added also to ImagePullSecrets of SA.
 func (s *SuiteController) LinkSecretToServiceAccount(ns, secret, serviceaccount string, addImagePullSecrets bool) error {
 	timeout := 20 * time.Second
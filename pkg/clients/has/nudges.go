@@ -0,0 +1,115 @@
+package has
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/konflux-ci/e2e-tests/pkg/clients/git"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+// GitClient returns a provider-agnostic git client for the given git provider backed by the HasController clients.
+func (h *HasController) GitClient(gitProvider git.GitProvider) (git.Client, error) {
+	switch gitProvider {
+	case git.GitHubProvider:
+		return git.NewGitHubClient(h.Github), nil
+	case git.GitLabProvider:
+		return git.NewGitlabClient(h.GitLab), nil
+	case git.ForgejoProvider:
+		if h.Forgejo == nil {
+			return nil, fmt.Errorf("forgejo client is not initialized, check that the codeberg token is set")
+		}
+		return git.NewForgejoClient(h.Forgejo), nil
+	}
+	return nil, fmt.Errorf("unsupported git provider %d", gitProvider)
+}
+
+// AddComponentNudges declares that a successful push build of the given component nudges the dependent components,
+// i.e. the build-service creates pull requests updating references to the built image in their repositories.
+func (h *HasController) AddComponentNudges(componentName, namespace string, nudgedComponentNames ...string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		component, err := h.GetComponent(componentName, namespace)
+		if err != nil {
+			return fmt.Errorf("error when getting component %s/%s: %w", namespace, componentName, err)
+		}
+		for _, name := range nudgedComponentNames {
+			if !slices.Contains(component.Spec.BuildNudgesRef, name) {
+				component.Spec.BuildNudgesRef = append(component.Spec.BuildNudgesRef, name)
+			}
+		}
+		return h.UpdateComponent(component)
+	})
+}
+
+// WaitForComponentNudgedBy waits until the build-service records in the status of the given component
+// that it is nudged by the nudging component.
+func (h *HasController) WaitForComponentNudgedBy(componentName, nudgingComponentName, namespace string, timeout time.Duration) error {
+	return wait.PollUntilContextTimeout(context.Background(), 5*time.Second, timeout, true, func(ctx context.Context) (done bool, err error) {
+		component, err := h.GetComponent(componentName, namespace)
+		if err != nil {
+			ginkgo.GinkgoWriter.Printf("failed to get component %s/%s: %v\n", namespace, componentName, err)
+			return false, nil
+		}
+		return slices.Contains(component.Status.BuildNudgedBy, nudgingComponentName), nil
+	})
+}
+
+// WaitForNudgePullRequest waits for the pull request created in the repository of a nudged component after a push build
+// of the nudging component, and for its files to reference the image repositories with the expected digest.
+// files maps paths of files in the repository to the image repository they reference, e.g. the build repository
+// of the nudging component in a Dockerfile and its distribution repository in a manifest.
+// The nudge pull request can be updated by subsequent builds of the nudging component, so the files are checked until they
+// contain the expected digest or the timeout is reached.
+func (h *HasController) WaitForNudgePullRequest(gitClient git.Client, repository, nudgingComponentName, expectedDigest string, files map[string]string, timeout time.Duration) (*git.PullRequest, error) {
+	var nudgePR *git.PullRequest
+	var lastErr error
+	err := wait.PollUntilContextTimeout(context.Background(), 10*time.Second, timeout, true, func(ctx context.Context) (done bool, err error) {
+		prs, err := git.ListPullRequestsWithRetry(gitClient, repository)
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		for _, pr := range prs {
+			if !strings.Contains(pr.SourceBranch, nudgingComponentName) {
+				continue
+			}
+			if lastErr = VerifyNudgedFiles(gitClient, repository, pr.SourceBranch, expectedDigest, files); lastErr != nil {
+				ginkgo.GinkgoWriter.Printf("nudge PR #%d in %s is not updated yet: %v\n", pr.Number, repository, lastErr)
+				return false, nil
+			}
+			nudgePR = pr
+			return true, nil
+		}
+		lastErr = fmt.Errorf("no PR with source branch containing %s found", nudgingComponentName)
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("timed out waiting for nudge PR from component %s in repository %s: %w", nudgingComponentName, repository, lastErr)
+	}
+	return nudgePR, nil
+}
+
+// VerifyNudgedFiles checks that the files on the branch of the repository reference their image repository
+// with the expected digest and don't reference it with any other digest. files maps paths of files in the repository
+// to the image repository they reference.
+func VerifyNudgedFiles(gitClient git.Client, repository, branch, expectedDigest string, files map[string]string) error {
+	for path, imageRepository := range files {
+		expectedRef := imageRepository + "@" + expectedDigest
+		file, err := gitClient.GetFile(repository, path, branch)
+		if err != nil {
+			return fmt.Errorf("failed to get file %s from branch %s of repository %s: %w", path, branch, repository, err)
+		}
+		if !strings.Contains(file.Content, expectedRef) {
+			return fmt.Errorf("file %s doesn't reference %s, content: %s", path, expectedRef, file.Content)
+		}
+		if strings.Count(file.Content, imageRepository+"@") != strings.Count(file.Content, expectedRef) {
+			return fmt.Errorf("file %s references %s with a digest other than %s, content: %s", path, imageRepository, expectedDigest, file.Content)
+		}
+	}
+	return nil
+}
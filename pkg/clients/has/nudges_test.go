@@ -0,0 +1,64 @@
+package has
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/konflux-ci/e2e-tests/pkg/clients/git"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeGitClient serves the files of the branches of a single repository, keyed by branch and path
+type fakeGitClient struct {
+	git.Client
+	files map[string]map[string]string
+}
+
+func (f *fakeGitClient) GetFile(repository, pathToFile, branchName string) (*git.RepositoryFile, error) {
+	content, ok := f.files[branchName][pathToFile]
+	if !ok {
+		return nil, fmt.Errorf("file %s not found in branch %s of %s", pathToFile, branchName, repository)
+	}
+	return &git.RepositoryFile{Content: content}, nil
+}
+
+func TestVerifyNudgedFiles(t *testing.T) {
+	const (
+		buildRepository        = "quay.io/tenant/nudging-component"
+		distributionRepository = "registry.example.com/product/nudging-component"
+		digest                 = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		otherDigest            = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+	files := map[string]string{
+		"Dockerfile":    buildRepository,
+		"manifest.yaml": distributionRepository,
+	}
+	gitClient := &fakeGitClient{files: map[string]map[string]string{
+		"nudged": {
+			"Dockerfile":    "FROM " + buildRepository + "@" + digest + "\n",
+			"manifest.yaml": "image: " + distributionRepository + "@" + digest + "\n",
+		},
+		"missing": {
+			"Dockerfile": "FROM " + buildRepository + "@" + digest + "\n",
+		},
+		"not-nudged": {
+			"Dockerfile":    "FROM " + buildRepository + "@" + digest + "\n",
+			"manifest.yaml": "image: " + distributionRepository + "@" + otherDigest + "\n",
+		},
+		"partially-nudged": {
+			"Dockerfile":    "FROM " + buildRepository + "@" + digest + "\nFROM " + buildRepository + "@" + otherDigest + "\n",
+			"manifest.yaml": "image: " + distributionRepository + "@" + digest + "\n",
+		},
+	}}
+
+	assert.NoError(t, VerifyNudgedFiles(gitClient, "nudged-component", "nudged", digest, files))
+
+	err := VerifyNudgedFiles(gitClient, "nudged-component", "missing", digest, files)
+	assert.ErrorContains(t, err, "failed to get file manifest.yaml from branch missing of repository nudged-component")
+
+	err = VerifyNudgedFiles(gitClient, "nudged-component", "not-nudged", digest, files)
+	assert.ErrorContains(t, err, "file manifest.yaml doesn't reference "+distributionRepository+"@"+digest)
+
+	err = VerifyNudgedFiles(gitClient, "nudged-component", "partially-nudged", digest, files)
+	assert.ErrorContains(t, err, "file Dockerfile references "+buildRepository+" with a digest other than "+digest)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -23,27 +24,24 @@ func PullArtifacts(imagePullSpec string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	fs, err := file.New(storePath)
-	if err != nil {
+	if err := PullArtifactsTo(imagePullSpec, storePath); err != nil {
 		return "", err
 	}
-	defer fs.Close()
+	return storePath, nil
+}
 
-	imageRef, err := reference.Parse(imagePullSpec)
+// PullArtifactsTo pulls artifacts from the given imagePullSpec into the given directory.
+// The directories pushed with PushArtifacts are unpacked.
+func PullArtifactsTo(imagePullSpec, storePath string) error {
+	fs, err := file.New(storePath)
 	if err != nil {
-		return "", fmt.Errorf("cannot parse %s: %w", imagePullSpec, err)
+		return err
 	}
+	defer fs.Close()
 
-	repo, err := remote.NewRepository(imagePullSpec)
+	repo, imageRef, err := newRepository(imagePullSpec)
 	if err != nil {
-		return "", fmt.Errorf("cannot get repository from %s: %w", imagePullSpec, err)
-	}
-	repo.Client = &auth.Client{
-		Client: retry.DefaultClient,
-		Cache:  auth.NewCache(),
-		Credential: auth.StaticCredential(imageRef.Registry, auth.Credential{
-			AccessToken: os.Getenv("QUAY_TOKEN"),
-		}),
+		return err
 	}
 
 	ctx := context.Background()
@@ -58,10 +56,40 @@ func PullArtifacts(imagePullSpec string) (string, error) {
 	opts.FindSuccessors = noSuccessors
 
 	if _, err := oras.Copy(ctx, repo, srcRef, fs, dstRef, opts); err != nil {
-		return "", fmt.Errorf("copying %s: %w", imagePullSpec, err)
+		return fmt.Errorf("copying %s: %w", imagePullSpec, err)
 	}
 
-	return storePath, nil
+	return nil
+}
+
+// newRepository returns the repository of the given image reference, authenticated with QUAY_TOKEN.
+// Registries on the loopback interface, i.e. the ones started by the tests, are reached over plain HTTP.
+func newRepository(imageReference string) (*remote.Repository, reference.DockerImageReference, error) {
+	imageRef, err := reference.Parse(imageReference)
+	if err != nil {
+		return nil, imageRef, fmt.Errorf("cannot parse %s: %w", imageReference, err)
+	}
+
+	repo, err := remote.NewRepository(imageRef.AsRepository().Exact())
+	if err != nil {
+		return nil, imageRef, fmt.Errorf("cannot get repository from %s: %w", imageReference, err)
+	}
+	repo.Client = &auth.Client{
+		Client: retry.DefaultClient,
+		Cache:  auth.NewCache(),
+		Credential: auth.StaticCredential(imageRef.Registry, auth.Credential{
+			AccessToken: os.Getenv("QUAY_TOKEN"),
+		}),
+	}
+	host, _, err := net.SplitHostPort(imageRef.Registry)
+	if err != nil {
+		host = imageRef.Registry
+	}
+	if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		repo.PlainHTTP = true
+	}
+
+	return repo, imageRef, nil
 }
 
 // noSuccessors returns the nodes directly pointed by the current node. By default oras will follow
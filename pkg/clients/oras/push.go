@@ -0,0 +1,122 @@
+package oras
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	oras "oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/file"
+)
+
+const (
+	// TestArtifactsType is the artifact type of the artifact directories of the e2e test runs
+	TestArtifactsType = "application/vnd.konflux-ci.e2e-tests.artifacts.v1"
+	// testArtifactsLayer is the name of the layer holding the artifact directory, it is unpacked into that directory when pulled
+	testArtifactsLayer = "artifacts"
+
+	AnnotationJobName     = "org.konflux-ci.e2e-tests.job-name"
+	AnnotationPullRequest = "org.konflux-ci.e2e-tests.pull-request"
+	AnnotationCommit      = "org.konflux-ci.e2e-tests.commit"
+	AnnotationResult      = "org.konflux-ci.e2e-tests.result"
+)
+
+// TestArtifacts is an artifact directory pushed with PushArtifacts
+type TestArtifacts struct {
+	Tag         string
+	Digest      string
+	Annotations map[string]string
+}
+
+// PushArtifacts pushes the directory, i.e. the artifact directory of a run with its reports, logs and PipelineRun YAMLs,
+// as an OCI artifact with the given annotations, i.e. AnnotationJobName or AnnotationResult, to the given image reference.
+// The digest of the pushed manifest is returned.
+func PushArtifacts(dir, imageReference string, annotations map[string]string) (string, error) {
+	repo, imageRef, err := newRepository(imageReference)
+	if err != nil {
+		return "", err
+	}
+	if imageRef.Tag == "" {
+		return "", fmt.Errorf("%s has no tag to push the artifacts to", imageReference)
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	fs, err := file.New(absDir)
+	if err != nil {
+		return "", err
+	}
+	defer fs.Close()
+
+	ctx := context.Background()
+	layer, err := fs.Add(ctx, testArtifactsLayer, "", absDir)
+	if err != nil {
+		return "", fmt.Errorf("packing %s: %w", dir, err)
+	}
+	manifest, err := oras.PackManifest(ctx, fs, oras.PackManifestVersion1_1, TestArtifactsType, oras.PackManifestOptions{
+		Layers:              []ocispec.Descriptor{layer},
+		ManifestAnnotations: annotations,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := fs.Tag(ctx, manifest, imageRef.Tag); err != nil {
+		return "", err
+	}
+
+	if _, err := oras.Copy(ctx, fs, imageRef.Tag, repo, imageRef.Tag, oras.DefaultCopyOptions); err != nil {
+		return "", fmt.Errorf("pushing %s to %s: %w", dir, imageReference, err)
+	}
+
+	return manifest.Digest.String(), nil
+}
+
+// ListArtifacts lists the artifact directories pushed with PushArtifacts to the given repository, sorted by tag.
+// The other images of the repository are ignored.
+func ListArtifacts(repository string) ([]TestArtifacts, error) {
+	repo, _, err := newRepository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	var tags []string
+	if err := repo.Tags(ctx, "", func(page []string) error {
+		tags = append(tags, page...)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("listing the tags of %s: %w", repository, err)
+	}
+	sort.Strings(tags)
+
+	var artifacts []TestArtifacts
+	for _, tag := range tags {
+		desc, err := repo.Resolve(ctx, tag)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s:%s: %w", repository, tag, err)
+		}
+		if desc.MediaType != ocispec.MediaTypeImageManifest {
+			continue
+		}
+		data, err := content.FetchAll(ctx, repo, desc)
+		if err != nil {
+			return nil, fmt.Errorf("fetching %s:%s: %w", repository, tag, err)
+		}
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, err
+		}
+		if manifest.ArtifactType != TestArtifactsType {
+			continue
+		}
+		artifacts = append(artifacts, TestArtifacts{Tag: tag, Digest: desc.Digest.String(), Annotations: manifest.Annotations})
+	}
+
+	return artifacts, nil
+}
@@ -0,0 +1,69 @@
+package oras
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeRunArtifacts writes the artifact directory of a run
+func writeRunArtifacts(t *testing.T) string {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"e2e-report.json": `[{"SuiteSucceeded": false}]`,
+		"[ Build service] builds/pipelineRun-build.log":  "step-build: building",
+		"[ Build service] builds/pipelineRun-build.yaml": "kind: PipelineRun\n",
+	} {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0775))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestPushListPullArtifacts(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	repository := strings.TrimPrefix(server.URL, "http://") + "/konflux-qe/e2e-artifacts"
+
+	dir := writeRunArtifacts(t)
+	annotations := map[string]string{
+		AnnotationJobName:     "pull-ci-konflux-ci-e2e-tests-main-konflux-e2e",
+		AnnotationPullRequest: "1234",
+		AnnotationCommit:      "0123456789abcdef",
+		AnnotationResult:      "failed",
+	}
+	digest, err := PushArtifacts(dir, repository+":run-1", annotations)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(digest, "sha256:"))
+	_, err = PushArtifacts(dir, repository+":run-2", map[string]string{AnnotationResult: "passed"})
+	assert.NoError(t, err)
+
+	artifacts, err := ListArtifacts(repository)
+	assert.NoError(t, err)
+	if assert.Len(t, artifacts, 2) {
+		assert.Equal(t, "run-1", artifacts[0].Tag)
+		assert.Equal(t, digest, artifacts[0].Digest)
+		for key, value := range annotations {
+			assert.Equal(t, value, artifacts[0].Annotations[key])
+		}
+		assert.Equal(t, "run-2", artifacts[1].Tag)
+		assert.Equal(t, "passed", artifacts[1].Annotations[AnnotationResult])
+	}
+
+	pulled := t.TempDir()
+	assert.NoError(t, PullArtifactsTo(repository+":run-1", pulled))
+	log, err := os.ReadFile(filepath.Join(pulled, testArtifactsLayer, "[ Build service] builds", "pipelineRun-build.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "step-build: building", string(log))
+	_, err = os.Stat(filepath.Join(pulled, testArtifactsLayer, "e2e-report.json"))
+	assert.NoError(t, err)
+
+	_, err = PushArtifacts(dir, repository, annotations)
+	assert.Error(t, err, "pushing without a tag should fail")
+	assert.Error(t, PullArtifactsTo(repository+":missing", t.TempDir()))
+}
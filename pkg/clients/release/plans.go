@@ -12,6 +12,7 @@ import (
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // CreateReleasePlan creates a new ReleasePlan using the given parameters.
@@ -124,3 +125,13 @@ func (r *ReleaseController) DeleteReleasePlanAdmission(name, namespace string, f
 	}
 	return err
 }
+
+// DeleteAllReleasePlansInASpecificNamespace deletes all the ReleasePlans in the given namespace.
+func (r *ReleaseController) DeleteAllReleasePlansInASpecificNamespace(namespace string) error {
+	return r.KubeRest().DeleteAllOf(context.Background(), &releaseApi.ReleasePlan{}, crclient.InNamespace(namespace))
+}
+
+// DeleteAllReleasePlanAdmissionsInASpecificNamespace deletes all the ReleasePlanAdmissions in the given namespace.
+func (r *ReleaseController) DeleteAllReleasePlanAdmissionsInASpecificNamespace(namespace string) error {
+	return r.KubeRest().DeleteAllOf(context.Background(), &releaseApi.ReleasePlanAdmission{}, crclient.InNamespace(namespace))
+}
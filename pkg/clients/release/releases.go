@@ -228,3 +228,8 @@ func (r *ReleaseController) WaitForReleasePipelineToBeFinished(release *releaseA
 		return false, nil
 	})
 }
+
+// DeleteAllReleasesInASpecificNamespace deletes all the Releases in the given namespace.
+func (r *ReleaseController) DeleteAllReleasesInASpecificNamespace(namespace string) error {
+	return r.KubeRest().DeleteAllOf(context.Background(), &releaseApi.Release{}, client.InNamespace(namespace))
+}
@@ -12,10 +12,15 @@ package release
 
 import (
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"regexp"
+
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
 )
 
 // Defines a struct Links with fields for various types of links including artifacts, requests, RPM manifests,
@@ -95,24 +100,40 @@ type Image struct {
 }
 
 // GetPyxisImageByImageID makes a GET request to stage Pyxis to get an image
-// and returns it.
+// and returns it. The client certificate is omitted when pyxisCertDecoded and pyxisKeyDecoded
+// are empty, which allows querying a Pyxis stand-in running without mTLS.
 func (r *ReleaseController) GetPyxisImageByImageID(pyxisStageImagesApiEndpoint, imageID string,
 	pyxisCertDecoded, pyxisKeyDecoded []byte) ([]byte, error) {
 
 	url := fmt.Sprintf("%s%s", pyxisStageImagesApiEndpoint, imageID)
 
-	// Create a TLS configuration with the key and certificate
-	cert, err := tls.X509KeyPair(pyxisCertDecoded, pyxisKeyDecoded)
-	if err != nil {
-		return nil, fmt.Errorf("error creating TLS certificate and key: %s", err)
+	tlsConfig := &tls.Config{}
+	if len(pyxisCertDecoded) > 0 || len(pyxisKeyDecoded) > 0 {
+		// Create a TLS configuration with the key and certificate
+		cert, err := tls.X509KeyPair(pyxisCertDecoded, pyxisKeyDecoded)
+		if err != nil {
+			return nil, fmt.Errorf("error creating TLS certificate and key: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	// Trust the CA of the Pyxis stand-in in case it is used
+	if caCert := os.Getenv(constants.PYXIS_STAND_IN_CA_CERT_ENV); caCert != "" {
+		caCertDecoded, err := base64.StdEncoding.DecodeString(caCert)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding %s: %s", constants.PYXIS_STAND_IN_CA_CERT_ENV, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCertDecoded) {
+			return nil, fmt.Errorf("error parsing %s", constants.PYXIS_STAND_IN_CA_CERT_ENV)
+		}
+		tlsConfig.RootCAs = pool
 	}
 
 	// Create a client with the custom TLS configuration
 	client := &http.Client{
 		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				Certificates: []tls.Certificate{cert},
-			},
+			TLSClientConfig: tlsConfig,
 		},
 	}
 
@@ -0,0 +1,366 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/logs"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/tekton"
+	releaseApi "github.com/konflux-ci/release-service/api/v1alpha1"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/yaml"
+)
+
+// ReleasePhase identifies a single step of the Release lifecycle.
+type ReleasePhase string
+
+const (
+	ReleasePhaseValidation      ReleasePhase = "Validation"
+	ReleasePhaseTenantPipeline  ReleasePhase = "TenantPipeline"
+	ReleasePhaseManagedPipeline ReleasePhase = "ManagedPipeline"
+	ReleasePhaseFinalPipeline   ReleasePhase = "FinalPipeline"
+	ReleasePhasePostActions     ReleasePhase = "PostActions"
+)
+
+// ReleasePhaseState is the observed outcome of a ReleasePhase.
+type ReleasePhaseState string
+
+const (
+	ReleasePhaseStatePending     ReleasePhaseState = "Pending"
+	ReleasePhaseStateProgressing ReleasePhaseState = "Progressing"
+	ReleasePhaseStateSucceeded   ReleasePhaseState = "Succeeded"
+	ReleasePhaseStateFailed      ReleasePhaseState = "Failed"
+	ReleasePhaseStateSkipped     ReleasePhaseState = "Skipped"
+)
+
+// Condition types set by the release-service for every phase. The release-service has no condition of its own
+// for the post-actions, which run once the pipelines are processed, the post-actions phase follows the "Released"
+// condition set once they are done.
+const (
+	validatedConditionType        = "Validated"
+	tenantProcessedConditionType  = "TenantPipelineProcessed"
+	managedProcessedConditionType = "ManagedPipelineProcessed"
+	finalProcessedConditionType   = "FinalPipelineProcessed"
+	releasedConditionType         = "Released"
+)
+
+// ReleasePhaseStatus records the condition, timing and PipelineRun of a single ReleasePhase.
+type ReleasePhaseStatus struct {
+	Phase          ReleasePhase           `json:"phase"`
+	State          ReleasePhaseState      `json:"state"`
+	ConditionType  string                 `json:"conditionType"`
+	Status         metav1.ConditionStatus `json:"status,omitempty"`
+	Reason         string                 `json:"reason,omitempty"`
+	Message        string                 `json:"message,omitempty"`
+	StartTime      *metav1.Time           `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time           `json:"completionTime,omitempty"`
+	Duration       time.Duration          `json:"duration"`
+	PipelineRun    string                 `json:"pipelineRun,omitempty"`
+}
+
+// ReleaseTrackingResult is the outcome of following a Release through all of its phases.
+type ReleaseTrackingResult struct {
+	ReleaseName          string                           `json:"releaseName"`
+	ReleaseNamespace     string                           `json:"releaseNamespace"`
+	Succeeded            bool                             `json:"succeeded"`
+	FailedPhase          ReleasePhase                     `json:"failedPhase,omitempty"`
+	Duration             time.Duration                    `json:"duration"`
+	Phases               []ReleasePhaseStatus             `json:"phases"`
+	PipelineRunLogs      map[string]string                `json:"pipelineRunLogs,omitempty"`
+	ReleasePlanAdmission *releaseApi.ReleasePlanAdmission `json:"-"`
+}
+
+// Phase returns the status of the given phase, or nil when it was not recorded.
+func (t *ReleaseTrackingResult) Phase(phase ReleasePhase) *ReleasePhaseStatus {
+	for i := range t.Phases {
+		if t.Phases[i].Phase == phase {
+			return &t.Phases[i]
+		}
+	}
+	return nil
+}
+
+// String returns a human readable summary of the tracked phases.
+func (t *ReleaseTrackingResult) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Release %s/%s succeeded: %t (took %s)\n", t.ReleaseNamespace, t.ReleaseName, t.Succeeded, t.Duration)
+	for _, p := range t.Phases {
+		fmt.Fprintf(&sb, "  %-16s %-12s %-10s %s", p.Phase, p.State, p.Duration, p.Reason)
+		if p.PipelineRun != "" {
+			fmt.Fprintf(&sb, " (PipelineRun: %s)", p.PipelineRun)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// releasePhaseChecks are the helpers of the release API telling the state of a phase from the conditions of a Release
+type releasePhaseChecks struct {
+	skipped     func(*releaseApi.Release) bool
+	progressing func(*releaseApi.Release) bool
+	succeeded   func(*releaseApi.Release) bool
+	finished    func(*releaseApi.Release) bool
+}
+
+var (
+	validationChecks = releasePhaseChecks{
+		succeeded: (*releaseApi.Release).IsValid,
+		finished: func(release *releaseApi.Release) bool {
+			return meta.FindStatusCondition(release.Status.Conditions, validatedConditionType) != nil
+		},
+	}
+	tenantPipelineChecks = releasePhaseChecks{
+		skipped:     (*releaseApi.Release).IsTenantPipelineSkipped,
+		progressing: (*releaseApi.Release).IsTenantPipelineProcessing,
+		succeeded:   (*releaseApi.Release).IsTenantPipelineProcessedSuccessfully,
+		finished:    (*releaseApi.Release).HasTenantPipelineProcessingFinished,
+	}
+	managedPipelineChecks = releasePhaseChecks{
+		skipped:     (*releaseApi.Release).IsManagedPipelineSkipped,
+		progressing: (*releaseApi.Release).IsManagedPipelineProcessing,
+		succeeded:   (*releaseApi.Release).IsManagedPipelineProcessedSuccessfully,
+		finished:    (*releaseApi.Release).HasManagedPipelineProcessingFinished,
+	}
+	finalPipelineChecks = releasePhaseChecks{
+		skipped:     (*releaseApi.Release).IsFinalPipelineSkipped,
+		progressing: (*releaseApi.Release).IsFinalPipelineProcessing,
+		succeeded:   (*releaseApi.Release).IsFinalPipelineProcessedSuccessfully,
+		finished:    (*releaseApi.Release).HasFinalPipelineProcessingFinished,
+	}
+	// The post-actions never run when a Release fails before its pipelines are processed, they are skipped then
+	postActionsChecks = releasePhaseChecks{
+		skipped: func(release *releaseApi.Release) bool {
+			return release.HasReleaseFinished() && !release.IsReleased() && !havePipelinesSucceeded(release)
+		},
+		progressing: func(release *releaseApi.Release) bool {
+			return release.IsReleasing() && havePipelinesSucceeded(release)
+		},
+		succeeded: (*releaseApi.Release).IsReleased,
+		finished:  (*releaseApi.Release).HasReleaseFinished,
+	}
+)
+
+// havePipelinesSucceeded returns whether the pipelines of the Release were processed successfully, or skipped
+func havePipelinesSucceeded(release *releaseApi.Release) bool {
+	return release.IsValid() && release.IsTenantPipelineProcessedSuccessfully() &&
+		release.IsManagedPipelineProcessedSuccessfully() && release.IsFinalPipelineProcessedSuccessfully()
+}
+
+// GetReleasePhases returns the status of every phase of the given Release based on its conditions
+// and processing info.
+func GetReleasePhases(release *releaseApi.Release) []ReleasePhaseStatus {
+	status := release.Status
+	validationStart := release.CreationTimestamp.DeepCopy()
+	if status.StartTime != nil {
+		validationStart = status.StartTime
+	}
+
+	postActionsStart := status.FinalProcessing.CompletionTime
+	if postActionsStart == nil {
+		postActionsStart = status.ManagedProcessing.CompletionTime
+	}
+
+	return []ReleasePhaseStatus{
+		newReleasePhaseStatus(release, ReleasePhaseValidation, validatedConditionType, validationChecks, validationStart, status.Validation.Time, ""),
+		newReleasePhaseStatus(release, ReleasePhaseTenantPipeline, tenantProcessedConditionType, tenantPipelineChecks, status.TenantProcessing.StartTime, status.TenantProcessing.CompletionTime, status.TenantProcessing.PipelineRun),
+		newReleasePhaseStatus(release, ReleasePhaseManagedPipeline, managedProcessedConditionType, managedPipelineChecks, status.ManagedProcessing.StartTime, status.ManagedProcessing.CompletionTime, status.ManagedProcessing.PipelineRun),
+		newReleasePhaseStatus(release, ReleasePhaseFinalPipeline, finalProcessedConditionType, finalPipelineChecks, status.FinalProcessing.StartTime, status.FinalProcessing.CompletionTime, status.FinalProcessing.PipelineRun),
+		newReleasePhaseStatus(release, ReleasePhasePostActions, releasedConditionType, postActionsChecks, postActionsStart, status.CompletionTime, ""),
+	}
+}
+
+func newReleasePhaseStatus(release *releaseApi.Release, phase ReleasePhase, conditionType string, checks releasePhaseChecks, start, end *metav1.Time, pipelineRun string) ReleasePhaseStatus {
+	p := ReleasePhaseStatus{
+		Phase:          phase,
+		State:          ReleasePhaseStatePending,
+		ConditionType:  conditionType,
+		StartTime:      start,
+		CompletionTime: end,
+		PipelineRun:    pipelineRun,
+	}
+
+	switch {
+	case checks.skipped != nil && checks.skipped(release):
+		p.State = ReleasePhaseStateSkipped
+	case checks.succeeded(release):
+		p.State = ReleasePhaseStateSucceeded
+	case checks.progressing != nil && checks.progressing(release):
+		p.State = ReleasePhaseStateProgressing
+	case checks.finished(release):
+		p.State = ReleasePhaseStateFailed
+	}
+
+	if condition := meta.FindStatusCondition(release.Status.Conditions, conditionType); condition != nil {
+		p.Status = condition.Status
+		p.Reason = condition.Reason
+		p.Message = condition.Message
+		if p.CompletionTime == nil && p.State != ReleasePhaseStateProgressing && p.State != ReleasePhaseStatePending {
+			p.CompletionTime = condition.LastTransitionTime.DeepCopy()
+		}
+	}
+	// the post-actions skipped by a failed Release haven't run
+	if p.State == ReleasePhaseStateSkipped && phase == ReleasePhasePostActions {
+		p.Reason = releaseApi.SkippedReason.String()
+		p.StartTime, p.CompletionTime = nil, nil
+	}
+
+	if p.StartTime != nil && p.CompletionTime != nil {
+		p.Duration = p.CompletionTime.Sub(p.StartTime.Time)
+	}
+	return p
+}
+
+// TrackRelease follows the given Release through validation, tenant, managed and final pipelines and
+// post-actions until it finishes or the timeout is reached. On failure it collects the logs of the
+// failed PipelineRuns and the ReleasePlanAdmission that was used.
+func (r *ReleaseController) TrackRelease(name, namespace string, timeout time.Duration) (*ReleaseTrackingResult, error) {
+	var release *releaseApi.Release
+	lastStates := map[ReleasePhase]ReleasePhaseState{}
+
+	err := wait.PollUntilContextTimeout(context.Background(), constants.PipelineRunPollingInterval, timeout, true, func(ctx context.Context) (done bool, err error) {
+		release, err = r.GetRelease(name, "", namespace)
+		if err != nil {
+			ginkgo.GinkgoWriter.Printf("failed to get Release %s/%s: %v\n", namespace, name, err)
+			return false, nil
+		}
+		for _, p := range GetReleasePhases(release) {
+			if lastStates[p.Phase] != p.State {
+				ginkgo.GinkgoWriter.Printf("Release %s/%s phase %s is %s\n", namespace, name, p.Phase, p.State)
+				lastStates[p.Phase] = p.State
+			}
+		}
+		return release.HasReleaseFinished(), nil
+	})
+	if release == nil {
+		return nil, fmt.Errorf("failed to get Release %s/%s: %w", namespace, name, err)
+	}
+
+	result := r.GetReleaseTrackingResult(release)
+	if err != nil {
+		return result, fmt.Errorf("timed out tracking Release %s/%s:\n%s", namespace, name, result)
+	}
+	return result, nil
+}
+
+// GetReleaseTrackingResult builds a ReleaseTrackingResult from the current state of the given Release.
+// When the Release did not succeed, logs of failed PipelineRuns and the ReleasePlanAdmission are collected.
+func (r *ReleaseController) GetReleaseTrackingResult(release *releaseApi.Release) *ReleaseTrackingResult {
+	result := &ReleaseTrackingResult{
+		ReleaseName:      release.Name,
+		ReleaseNamespace: release.Namespace,
+		Succeeded:        release.IsReleased(),
+		Phases:           GetReleasePhases(release),
+		PipelineRunLogs:  map[string]string{},
+	}
+	if release.Status.StartTime != nil && release.Status.CompletionTime != nil {
+		result.Duration = release.Status.CompletionTime.Sub(release.Status.StartTime.Time)
+	}
+
+	if result.Succeeded {
+		return result
+	}
+
+	for _, p := range result.Phases {
+		if p.State != ReleasePhaseStateFailed {
+			continue
+		}
+		if result.FailedPhase == "" {
+			result.FailedPhase = p.Phase
+		}
+		if p.PipelineRun == "" {
+			continue
+		}
+		prLogs, err := r.getReleasePipelineRunLogs(p.PipelineRun)
+		if err != nil {
+			prLogs = fmt.Sprintf("failed to get logs of PipelineRun %s: %v", p.PipelineRun, err)
+		}
+		result.PipelineRunLogs[p.PipelineRun] = prLogs
+	}
+
+	rpa, err := r.GetReleasePlanAdmissionForRelease(release)
+	if err != nil {
+		ginkgo.GinkgoWriter.Printf("failed to get ReleasePlanAdmission for Release %s/%s: %v\n", release.Namespace, release.Name, err)
+	}
+	result.ReleasePlanAdmission = rpa
+
+	return result
+}
+
+// GetReleasePlanAdmissionForRelease returns the ReleasePlanAdmission matched by the ReleasePlan of the given Release.
+func (r *ReleaseController) GetReleasePlanAdmissionForRelease(release *releaseApi.Release) (*releaseApi.ReleasePlanAdmission, error) {
+	releasePlan, err := r.GetReleasePlan(release.Spec.ReleasePlan, release.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ReleasePlan %s/%s: %w", release.Namespace, release.Spec.ReleasePlan, err)
+	}
+	rpaName := releasePlan.Status.ReleasePlanAdmission.Name
+	if rpaName == "" {
+		return nil, fmt.Errorf("ReleasePlan %s/%s is not matched to any ReleasePlanAdmission", releasePlan.Namespace, releasePlan.Name)
+	}
+	namespacedName, err := parseNamespacedName(rpaName)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetReleasePlanAdmission(namespacedName.Name, namespacedName.Namespace)
+}
+
+func (r *ReleaseController) getReleasePipelineRunLogs(pipelineRunName string) (string, error) {
+	namespacedName, err := parseNamespacedName(pipelineRunName)
+	if err != nil {
+		return "", err
+	}
+	pipelineRun := &pipeline.PipelineRun{}
+	if err := r.KubeRest().Get(context.Background(), namespacedName, pipelineRun); err != nil {
+		return "", err
+	}
+	return tekton.GetFailedPipelineRunLogs(r.KubeRest(), r.KubeInterface(), pipelineRun)
+}
+
+func parseNamespacedName(name string) (types.NamespacedName, error) {
+	parts := strings.Split(name, "/")
+	if len(parts) != 2 {
+		return types.NamespacedName{}, fmt.Errorf("'%s' is not a valid namespaced name", name)
+	}
+	return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+}
+
+// StoreReleaseTrackingResult stores the given ReleaseTrackingResult, the collected PipelineRun logs and
+// the ReleasePlanAdmission as artifacts.
+func (r *ReleaseController) StoreReleaseTrackingResult(result *ReleaseTrackingResult) error {
+	if result == nil {
+		return fmt.Errorf("release tracking result is nil")
+	}
+
+	artifacts := make(map[string][]byte)
+	resultJson, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal release tracking result: %w", err)
+	}
+	artifacts["release-tracking-"+result.ReleaseName+".json"] = resultJson
+
+	for prName, prLogs := range result.PipelineRunLogs {
+		artifacts["release-pipelinerun-"+strings.ReplaceAll(prName, "/", "-")+".log"] = []byte(prLogs)
+	}
+
+	if result.ReleasePlanAdmission != nil {
+		rpaYaml, err := yaml.Marshal(result.ReleasePlanAdmission)
+		if err != nil {
+			return fmt.Errorf("failed to marshal ReleasePlanAdmission YAML: %w", err)
+		}
+		artifacts["releaseplanadmission-"+result.ReleasePlanAdmission.Name+".yaml"] = rpaYaml
+	}
+
+	if err := logs.StoreArtifacts(artifacts); err != nil {
+		return fmt.Errorf("failed to store artifacts: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	releaseApi "github.com/konflux-ci/release-service/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetReleasePhases(t *testing.T) {
+	start := metav1.NewTime(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))
+	validated := metav1.NewTime(start.Add(5 * time.Second))
+	managedStart := metav1.NewTime(start.Add(10 * time.Second))
+	managedEnd := metav1.NewTime(start.Add(10 * time.Minute))
+
+	release := &releaseApi.Release{
+		ObjectMeta: metav1.ObjectMeta{Name: "release", Namespace: "tenant"},
+		Status: releaseApi.ReleaseStatus{
+			StartTime:  &start,
+			Validation: releaseApi.ValidationInfo{Time: &validated},
+			ManagedProcessing: releaseApi.PipelineInfo{
+				StartTime:      &managedStart,
+				CompletionTime: &managedEnd,
+				PipelineRun:    "managed/managed-abcde",
+			},
+			Conditions: []metav1.Condition{
+				{Type: "Validated", Status: metav1.ConditionTrue, Reason: "Succeeded"},
+				{Type: "TenantPipelineProcessed", Status: metav1.ConditionTrue, Reason: "Skipped"},
+				{Type: "ManagedPipelineProcessed", Status: metav1.ConditionFalse, Reason: "Failed", Message: "task failed"},
+				{Type: "Released", Status: metav1.ConditionFalse, Reason: "Failed", LastTransitionTime: managedEnd},
+			},
+		},
+	}
+
+	phases := GetReleasePhases(release)
+	result := &ReleaseTrackingResult{Phases: phases}
+
+	assert.Len(t, phases, 5)
+	assert.Equal(t, ReleasePhaseStateSucceeded, result.Phase(ReleasePhaseValidation).State)
+	assert.Equal(t, 5*time.Second, result.Phase(ReleasePhaseValidation).Duration)
+	assert.Equal(t, ReleasePhaseStateSkipped, result.Phase(ReleasePhaseTenantPipeline).State)
+
+	managed := result.Phase(ReleasePhaseManagedPipeline)
+	assert.Equal(t, ReleasePhaseStateFailed, managed.State)
+	assert.Equal(t, "task failed", managed.Message)
+	assert.Equal(t, "managed/managed-abcde", managed.PipelineRun)
+	assert.Equal(t, managedEnd.Sub(managedStart.Time), managed.Duration)
+
+	assert.Equal(t, ReleasePhaseStatePending, result.Phase(ReleasePhaseFinalPipeline).State)
+
+	// the post-actions never ran after the failure of the managed pipeline
+	postActions := result.Phase(ReleasePhasePostActions)
+	assert.Equal(t, "Released", postActions.ConditionType)
+	assert.Equal(t, ReleasePhaseStateSkipped, postActions.State)
+	assert.Equal(t, "Skipped", postActions.Reason)
+	assert.Nil(t, postActions.StartTime)
+	assert.Zero(t, postActions.Duration)
+}
+
+func TestGetReleasePhasesPostActions(t *testing.T) {
+	start := metav1.NewTime(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))
+	managedEnd := metav1.NewTime(start.Add(10 * time.Minute))
+	completion := metav1.NewTime(start.Add(11 * time.Minute))
+
+	release := &releaseApi.Release{
+		Status: releaseApi.ReleaseStatus{
+			StartTime:         &start,
+			ManagedProcessing: releaseApi.PipelineInfo{CompletionTime: &managedEnd},
+			Conditions: []metav1.Condition{
+				{Type: "Validated", Status: metav1.ConditionTrue, Reason: "Succeeded"},
+				{Type: "TenantPipelineProcessed", Status: metav1.ConditionTrue, Reason: "Skipped"},
+				{Type: "ManagedPipelineProcessed", Status: metav1.ConditionTrue, Reason: "Succeeded"},
+				{Type: "FinalPipelineProcessed", Status: metav1.ConditionTrue, Reason: "Skipped"},
+				{Type: "Released", Status: metav1.ConditionFalse, Reason: "Progressing"},
+			},
+		},
+	}
+	postActions := func() ReleasePhaseStatus { return GetReleasePhases(release)[4] }
+	assert.Equal(t, ReleasePhaseStateProgressing, postActions().State)
+
+	release.Status.Conditions[4] = metav1.Condition{Type: "Released", Status: metav1.ConditionFalse, Reason: "Failed", Message: "post-actions failed"}
+	release.Status.CompletionTime = &completion
+	assert.Equal(t, ReleasePhaseStateFailed, postActions().State)
+	assert.Equal(t, time.Minute, postActions().Duration)
+
+	release.Status.Conditions[4] = metav1.Condition{Type: "Released", Status: metav1.ConditionTrue, Reason: "Succeeded"}
+	assert.Equal(t, ReleasePhaseStateSucceeded, postActions().State)
+	assert.Equal(t, ReleasePhaseStateSkipped, GetReleasePhases(release)[3].State)
+
+	// the pipelines are still running
+	release.Status.Conditions[2] = metav1.Condition{Type: "ManagedPipelineProcessed", Status: metav1.ConditionFalse, Reason: "Progressing"}
+	release.Status.Conditions[4] = metav1.Condition{Type: "Released", Status: metav1.ConditionFalse, Reason: "Progressing"}
+	release.Status.CompletionTime = nil
+	assert.Equal(t, ReleasePhaseStateProgressing, GetReleasePhases(release)[2].State)
+	assert.Equal(t, ReleasePhaseStatePending, postActions().State)
+}
+
+func TestParseNamespacedName(t *testing.T) {
+	nn, err := parseNamespacedName("managed/rpa")
+	assert.NoError(t, err)
+	assert.Equal(t, "managed", nn.Namespace)
+	assert.Equal(t, "rpa", nn.Name)
+
+	_, err = parseNamespacedName("rpa")
+	assert.Error(t, err)
+}
@@ -91,3 +91,8 @@ func (t *TektonController) DeleteEnterpriseContractPolicy(name string, namespace
 	}
 	return err
 }
+
+// DeleteAllEnterpriseContractPoliciesInASpecificNamespace deletes all the EnterpriseContractPolicies in a specified namespace.
+func (t *TektonController) DeleteAllEnterpriseContractPoliciesInASpecificNamespace(namespace string) error {
+	return t.KubeRest().DeleteAllOf(context.Background(), &ecp.EnterpriseContractPolicy{}, crclient.InNamespace(namespace))
+}
@@ -68,6 +68,12 @@ const (
 	// Cert auth for accessing Pyxis stage external registry
 	PYXIS_STAGE_CERT_ENV string = "PYXIS_STAGE_CERT"
 
+	// Base64 encoded CA certificate used to verify the Pyxis stand-in server certificate
+	PYXIS_STAND_IN_CA_CERT_ENV string = "PYXIS_STAND_IN_CA_CERT"
+
+	// Image containing the pyxis-stub binary used when deploying the Pyxis stand-in into the cluster
+	PYXIS_STAND_IN_IMAGE_ENV string = "PYXIS_STAND_IN_IMAGE"
+
 	// SSO user for accessing the Atlas stage release instance
 	ATLAS_STAGE_ACCOUNT_ENV string = "ATLAS_STAGE_ACCOUNT" // #nosec
 
@@ -90,6 +96,15 @@ const (
 	// Toolchain API URL used for authentication against stage/prod cluster
 	TOOLCHAIN_API_URL_ENV = "TOOLCHAIN_API_URL"
 
+	// Number of Dev Sandbox users of the pool the stage frameworks lease their user from, the pool is disabled when unset
+	SANDBOX_USER_POOL_SIZE_ENV = "SANDBOX_USER_POOL_SIZE"
+
+	// Duration of the leases of the pooled Dev Sandbox users, i.e. 2h, after which an unreleased user is leased again
+	SANDBOX_USER_POOL_TTL_ENV = "SANDBOX_USER_POOL_TTL"
+
+	// Namespace, in the cluster of KUBECONFIG, of the ConfigMap holding the leases of the pooled Dev Sandbox users
+	SANDBOX_USER_POOL_NAMESPACE_ENV = "SANDBOX_USER_POOL_NAMESPACE"
+
 	// Dev workspace for release pipelines tests
 	RELEASE_DEV_WORKSPACE_ENV = "RELEASE_DEV_WORKSPACE"
 
@@ -1,11 +1,13 @@
 package framework
 
 import (
-	"regexp"
-	"strings"
+	"encoding/json"
+	"os"
 	"time"
 
 	"github.com/konflux-ci/e2e-tests/pkg/logs"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/tekton"
+	"knative.dev/pkg/apis"
 
 	ginkgo "github.com/onsi/ginkgo/v2"
 )
@@ -31,6 +33,12 @@ func ReportFailure(f **Framework) func() {
 			ginkgo.GinkgoWriter.Printf("failed to store test timing: %v\n", err)
 		}
 
+		report := newFailureReport(ginkgo.CurrentSpecReport())
+		report.PipelineRuns = failedPipelineRuns(fwk, report.StartTime)
+		defer storeFailureReport(report)
+
+		logFilter := controllerLogFilter(fwk, report)
+
 		allPodLogs := make(map[string][]byte)
 		for _, namespace := range namespaces {
 			podList, err := fwk.AsKubeAdmin.CommonController.ListAllPods(namespace)
@@ -43,8 +51,9 @@ func ReportFailure(f **Framework) func() {
 				podLogs := fwk.AsKubeAdmin.CommonController.GetPodLogs(&pod)
 
 				for podName, log := range podLogs {
-					if filteredLogs := FilterLogs(string(log), ginkgo.CurrentSpecReport().StartTime); filteredLogs != "" {
+					if filteredLogs := logFilter.Filter(string(log)); filteredLogs != "" {
 						allPodLogs[podName] = []byte(filteredLogs)
+						report.ControllerErrors = append(report.ControllerErrors, controllerErrors(podName, filteredLogs)...)
 					}
 				}
 			}
@@ -56,30 +65,86 @@ func ReportFailure(f **Framework) func() {
 	}
 }
 
-func FilterLogs(logs string, start time.Time) string {
+// controllerLogFilter keeps the lines of the controller logs logged while the spec ran about the resources of
+// the namespace of the framework
+func controllerLogFilter(fwk *Framework, report *FailureReport) LogFilter {
+	logFilter := LogFilter{Start: report.StartTime, End: report.EndTime}
+	if fwk.UserNamespace != "" {
+		logFilter.Namespaces = []string{fwk.UserNamespace}
+	}
+	return logFilter
+}
 
-	//bit of a hack, the logs are in different formats and are not always valid JSON
-	//just look for RFC 3339 dates line by line, once we find one after the start time dump the
-	//rest of the lines
-	lines := strings.Split(logs, "\n")
-	ret := []string{}
-	rfc3339Pattern := `(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2}))`
+// newFailureReport returns the failure report of a failed spec, without the details of the cluster
+func newFailureReport(sr ginkgo.SpecReport) *FailureReport {
+	return &FailureReport{
+		Spec:        sr.FullText(),
+		Labels:      sr.Labels(),
+		State:       sr.State.String(),
+		FailureNode: sr.Failure.FailureNodeType.String(),
+		Message:     sr.Failure.Message,
+		Location:    sr.Failure.Location.String(),
+		StartTime:   sr.StartTime,
+		EndTime:     time.Now(),
+	}
+}
 
-	re := regexp.MustCompile(rfc3339Pattern)
-	for pos, i := range lines {
-		match := re.FindStringSubmatch(i)
+// failedPipelineRuns returns the PipelineRuns of the user namespace which failed since the start of the spec
+func failedPipelineRuns(fwk *Framework, start time.Time) []FailedPipelineRun {
+	if fwk.UserNamespace == "" || fwk.AsKubeAdmin.TektonController == nil {
+		return nil
+	}
+	pipelineRuns, err := fwk.AsKubeAdmin.TektonController.ListAllPipelineRuns(fwk.UserNamespace)
+	if err != nil {
+		ginkgo.GinkgoWriter.Printf("failed to list PipelineRuns in namespace %s: %v\n", fwk.UserNamespace, err)
+		return nil
+	}
 
-		if match != nil {
-			dateString := match[1]
-			ts, err := time.Parse(time.RFC3339, dateString)
-			if err != nil {
-				ret = append(ret, "Invalid Time, unable to parse date: "+i)
-			} else if ts.Equal(start) || ts.After(start) {
-				ret = append(ret, lines[pos:]...)
-				break
-			}
+	var failed []FailedPipelineRun
+	for _, pr := range pipelineRuns.Items {
+		if !tekton.HasPipelineRunFailed(&pr) || pr.Status.CompletionTime == nil || pr.Status.CompletionTime.Time.Before(start) {
+			continue
+		}
+		failedPipelineRun := FailedPipelineRun{Name: pr.Name, Namespace: pr.Namespace}
+		if condition := pr.GetStatusCondition().GetCondition(apis.ConditionSucceeded); condition != nil {
+			failedPipelineRun.Reason, failedPipelineRun.Message = condition.Reason, condition.Message
 		}
+		details, err := tekton.GetFailedPipelineRunDetails(fwk.AsKubeAdmin.CommonController.KubeRest(), &pr)
+		if err != nil {
+			ginkgo.GinkgoWriter.Printf("failed to get the details of the failed PipelineRun %s: %v\n", pr.Name, err)
+		} else {
+			failedPipelineRun.FailedTaskRun, failedPipelineRun.PodName, failedPipelineRun.FailedContainer = details.FailedTaskRunName, details.PodName, details.FailedContainerName
+		}
+		failed = append(failed, failedPipelineRun)
+	}
+	return failed
+}
+
+// storeFailureReport categorizes the failure report with the rules of E2E_FAILURE_TAXONOMY, or the default ones, and stores it
+func storeFailureReport(report *FailureReport) {
+	if len(report.ControllerErrors) > maxControllerErrors {
+		report.ControllerErrors = report.ControllerErrors[len(report.ControllerErrors)-maxControllerErrors:]
+	}
+
+	rules, err := LoadFailureRules(os.Getenv("E2E_FAILURE_TAXONOMY"))
+	if err == nil {
+		err = report.Categorize(rules)
 	}
+	if err != nil {
+		ginkgo.GinkgoWriter.Printf("failed to categorize the failure: %v\n", err)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		ginkgo.GinkgoWriter.Printf("failed to marshal the failure report: %v\n", err)
+		return
+	}
+	if err := logs.StoreArtifacts(map[string][]byte{FailureReportFile: data}); err != nil {
+		ginkgo.GinkgoWriter.Printf("failed to store the failure report: %v\n", err)
+	}
+}
 
-	return strings.Join(ret, "\n")
+// FilterLogs returns the lines of the logs logged since start, see LogFilter
+func FilterLogs(logs string, start time.Time) string {
+	return LogFilter{Start: start}.Filter(logs)
 }
@@ -1,6 +1,7 @@
 package framework
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -51,3 +52,17 @@ func TestJsonLogParsing(t *testing.T) {
 {"level":"info","ts":"2023-08-18T01:34:06Z","logger":"artifactbuild","caller":"artifactbuild/artifactbuild.go:530","msg":"Found community dependency, creating ArtifactBuild","namespace":"konflux-demo-afcg-tenant","resource":"hacbs-test-project-jyxg-on-push-vxwtr","kind":"PipelineRun","gav":"io.github.stuartwdouglas.hacbs-test.shaded:shaded-jdk11:1.9","artifactbuild":"shaded.jdk11.1.9-c65abf6b","action":"ADD"}
 {"level":"info","ts":"2023-08-18T01:35:06Z","logger":"artifactbuild","caller":"artifactbuild/artifactbuild.go:530","msg":"Found community dependency, creating ArtifactBuild","namespace":"konflux-demo-afcg-tenant","resource":"hacbs-test-project-jyxg-on-push-vxwtr","kind":"PipelineRun","gav":"io.github.stuartwdouglas.hacbs-test.simple:simple-jdk17:0.1.2","artifactbuild":"simple.jdk17.0.1.2-22fafbfd","action":"ADD"}`, filtered)
 }
+
+func TestControllerLogFilter(t *testing.T) {
+
+	start, _ := time.Parse(time.RFC3339, "2023-08-18T01:18:58.100Z")
+	end, _ := time.Parse(time.RFC3339, "2023-08-18T01:19:57.300Z")
+	report := &FailureReport{StartTime: start, EndTime: end}
+
+	// only the lines of the spec namespace logged while the spec ran are kept
+	filtered := controllerLogFilter(&Framework{UserNamespace: "build-e2e-bslz-tenant"}, report).Filter(plainLogs)
+	assert.Equal(t, `2023-08-18T01:19:57.257Z	INFO	ComponentImageRepository	controllers/component_image_controller.go:170	Waiting for devfile model in component	{"controller": "component", "controllerGroup": "appstudio.redhat.com", "controllerKind": "Component", "Component": {"name":"devfile-sample-hello-world-0dwf","namespace":"build-e2e-bslz-tenant"}, "namespace": "build-e2e-bslz-tenant", "name": "devfile-sample-hello-world-0dwf", "reconcileID": "188862ec-a820-473e-a3c1-a2bb87031138"}`, filtered)
+
+	// without a namespace, all the lines logged while the spec ran are kept
+	assert.Equal(t, 4, strings.Count(controllerLogFilter(&Framework{}, report).Filter(plainLogs), "\n")+1)
+}
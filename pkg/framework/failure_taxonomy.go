@@ -0,0 +1,289 @@
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// FailureReportFile is the name of the failure report stored by ReportFailure in the artifact directory of a failed spec
+	FailureReportFile = "failure-report.json"
+	// FailureSummaryFile is the name of the summary of the failure reports of a run, in the artifact directory of the run
+	FailureSummaryFile = "failure-summary.json"
+
+	FailureCategoryInfra                = "infra"
+	FailureCategoryFlakyExternalService = "flaky external service"
+	FailureCategoryProductBug           = "product bug"
+	FailureCategoryTestBug              = "test bug"
+	// FailureCategoryUnknown is the category of the failures matched by no rule
+	FailureCategoryUnknown = "unknown"
+
+	// the sources of the failure reports matched by the rules
+	FailureSourceMessage          = "message"
+	FailureSourceLocation         = "location"
+	FailureSourcePipelineRuns     = "pipelineRuns"
+	FailureSourceControllerErrors = "controllerErrors"
+)
+
+// maxControllerErrors is the maximum number of controller error lines kept in a failure report
+const maxControllerErrors = 50
+
+// controllerErrorPattern matches the error lines of the controller logs, JSON (zap), logfmt, console (zap) and klog formatted
+var controllerErrorPattern = regexp.MustCompile(`(?i)"level":\s*"(error|dpanic|panic|fatal)"|\blevel=(error|fatal)\b|\tERROR\t|^E\d{4} \d{2}:\d{2}:\d{2}`)
+
+// FailureReport is the machine-readable report of a failed spec, stored by ReportFailure
+type FailureReport struct {
+	Spec   string   `json:"spec"`
+	Labels []string `json:"labels,omitempty"`
+	State  string   `json:"state"`
+	// FailureNode is the type of the node which failed, i.e. It or BeforeAll
+	FailureNode string `json:"failureNode"`
+	Message     string `json:"message"`
+	// Location is the location of the failing assertion
+	Location         string              `json:"location"`
+	StartTime        time.Time           `json:"startTime"`
+	EndTime          time.Time           `json:"endTime"`
+	PipelineRuns     []FailedPipelineRun `json:"pipelineRuns,omitempty"`
+	ControllerErrors []ControllerError   `json:"controllerErrors,omitempty"`
+	Category         string              `json:"category"`
+	// Rule is the name of the rule which assigned the category
+	Rule string `json:"rule,omitempty"`
+}
+
+// FailedPipelineRun is a PipelineRun of the user namespace which failed while the spec ran
+type FailedPipelineRun struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	Reason          string `json:"reason,omitempty"`
+	Message         string `json:"message,omitempty"`
+	FailedTaskRun   string `json:"failedTaskRun,omitempty"`
+	PodName         string `json:"podName,omitempty"`
+	FailedContainer string `json:"failedContainer,omitempty"`
+}
+
+// ControllerError is an error line logged by a controller while the spec ran
+type ControllerError struct {
+	Pod  string `json:"pod"`
+	Line string `json:"line"`
+}
+
+// FailureRule assigns its category to the failure reports whose sources match its pattern
+type FailureRule struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	// Sources are the parts of the report matched by the pattern: message, location, pipelineRuns
+	// and controllerErrors. All of them are matched when empty.
+	Sources []string `json:"sources,omitempty"`
+	Pattern string   `json:"pattern"`
+}
+
+// DefaultFailureRules are the rules categorizing the failure reports when E2E_FAILURE_TAXONOMY is not set, the first matching rule wins
+var DefaultFailureRules = []FailureRule{
+	{
+		Name:     "cluster-unreachable",
+		Category: FailureCategoryInfra,
+		Sources:  []string{FailureSourceMessage, FailureSourcePipelineRuns},
+		Pattern:  `(?i)connection refused|no such host|i/o timeout|TLS handshake timeout|the server is currently unable to handle the request|etcdserver|http2: client connection lost`,
+	},
+	{
+		Name:     "cluster-capacity",
+		Category: FailureCategoryInfra,
+		Sources:  []string{FailureSourceMessage, FailureSourcePipelineRuns, FailureSourceControllerErrors},
+		Pattern:  `(?i)Insufficient (cpu|memory)|\d+ node\(s\) (had|didn't)|OOMKilled|Evicted|failed to create pod sandbox|exceeded quota`,
+	},
+	{
+		Name:     "external-service-unavailable",
+		Category: FailureCategoryFlakyExternalService,
+		Sources:  []string{FailureSourceMessage, FailureSourcePipelineRuns, FailureSourceControllerErrors},
+		Pattern:  `(?i)rate limit|too many requests|bad gateway|service unavailable|gateway time-?out|\b(429|502|503|504)\b`,
+	},
+	{
+		Name:     "external-service-timeout",
+		Category: FailureCategoryFlakyExternalService,
+		Sources:  []string{FailureSourceMessage, FailureSourcePipelineRuns, FailureSourceControllerErrors},
+		Pattern:  `(?i)(quay\.io|registry\.redhat\.io|github\.com|gitlab\.com|codeberg\.org)\S*.*(timeout|timed out|connection reset|EOF)`,
+	},
+	{
+		Name:     "test-panic",
+		Category: FailureCategoryTestBug,
+		Sources:  []string{FailureSourceMessage},
+		Pattern:  `(?i)test panicked|runtime error|nil pointer dereference|index out of range|assignment to entry in nil map`,
+	},
+	{
+		Name:     "test-resource-conflict",
+		Category: FailureCategoryTestBug,
+		Sources:  []string{FailureSourceMessage},
+		Pattern:  `(?i)already exists|because it is being terminated`,
+	},
+	{
+		Name:     "pipelinerun-failed",
+		Category: FailureCategoryProductBug,
+		Sources:  []string{FailureSourcePipelineRuns},
+		Pattern:  `.`,
+	},
+	{
+		Name:     "controller-errors",
+		Category: FailureCategoryProductBug,
+		Sources:  []string{FailureSourceControllerErrors},
+		Pattern:  `.`,
+	},
+}
+
+// LoadFailureRules loads the rules of the YAML or JSON file, a list of FailureRule, or returns DefaultFailureRules when file is empty
+func LoadFailureRules(file string) ([]FailureRule, error) {
+
+	if file == "" {
+		return DefaultFailureRules, nil
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var rules []FailureRule
+	if err := yaml.Unmarshal(content, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse the failure rules %s: %v", file, err)
+	}
+	for _, rule := range rules {
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid pattern of the failure rule %q: %v", rule.Name, err)
+		}
+	}
+	return rules, nil
+}
+
+// Categorize assigns the category of the first rule matching the report, FailureCategoryUnknown when none does
+func (r *FailureReport) Categorize(rules []FailureRule) error {
+
+	r.Category, r.Rule = FailureCategoryUnknown, ""
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern of the failure rule %q: %v", rule.Name, err)
+		}
+		sources := rule.Sources
+		if len(sources) == 0 {
+			sources = []string{FailureSourceMessage, FailureSourceLocation, FailureSourcePipelineRuns, FailureSourceControllerErrors}
+		}
+		for _, source := range sources {
+			for _, text := range r.sourceTexts(source) {
+				if re.MatchString(text) {
+					r.Category, r.Rule = rule.Category, rule.Name
+					return nil
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (r *FailureReport) sourceTexts(source string) []string {
+
+	var texts []string
+	switch source {
+	case FailureSourceMessage:
+		texts = append(texts, r.Message)
+	case FailureSourceLocation:
+		texts = append(texts, r.Location)
+	case FailureSourcePipelineRuns:
+		for _, pr := range r.PipelineRuns {
+			texts = append(texts, strings.TrimSpace(pr.Reason+" "+pr.Message))
+		}
+	case FailureSourceControllerErrors:
+		for _, e := range r.ControllerErrors {
+			texts = append(texts, e.Line)
+		}
+	}
+	return texts
+}
+
+// controllerErrors returns the error lines of the logs of a controller pod
+func controllerErrors(pod, logs string) []ControllerError {
+
+	var errors []ControllerError
+	for _, line := range strings.Split(logs, "\n") {
+		if controllerErrorPattern.MatchString(line) {
+			errors = append(errors, ControllerError{Pod: pod, Line: line})
+		}
+	}
+	return errors
+}
+
+// FailureSummary aggregates the failure reports of a run
+type FailureSummary struct {
+	Total int `json:"total"`
+	// Categories counts the failures by category
+	Categories map[string]int `json:"categories"`
+	Specs      []FailedSpec   `json:"specs"`
+}
+
+// FailedSpec is a failure of the summary
+type FailedSpec struct {
+	Spec     string `json:"spec"`
+	Category string `json:"category"`
+	Rule     string `json:"rule,omitempty"`
+	Message  string `json:"message"`
+	// Report is the path of the failure report, relative to the artifact directory
+	Report string `json:"report"`
+}
+
+// SummarizeFailureReports aggregates the failure reports found in the artifact directory of a run
+func SummarizeFailureReports(artifactDir string) (*FailureSummary, error) {
+
+	summary := &FailureSummary{Categories: map[string]int{}}
+	err := filepath.WalkDir(artifactDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || d.Name() != FailureReportFile {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var report FailureReport
+		if err := json.Unmarshal(content, &report); err != nil {
+			return fmt.Errorf("failed to parse the failure report %s: %v", path, err)
+		}
+		rel, err := filepath.Rel(artifactDir, path)
+		if err != nil {
+			return err
+		}
+
+		summary.Total++
+		summary.Categories[report.Category]++
+		summary.Specs = append(summary.Specs, FailedSpec{Spec: report.Spec, Category: report.Category, Rule: report.Rule, Message: report.Message, Report: rel})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(summary.Specs, func(i, j int) bool {
+		if summary.Specs[i].Category != summary.Specs[j].Category {
+			return summary.Specs[i].Category < summary.Specs[j].Category
+		}
+		return summary.Specs[i].Spec < summary.Specs[j].Spec
+	})
+	return summary, nil
+}
+
+// StoreFailureSummary writes the summary of the failure reports of the artifact directory into its failure-summary.json
+func StoreFailureSummary(artifactDir string) (*FailureSummary, error) {
+
+	summary, err := SummarizeFailureReports(artifactDir)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return summary, os.WriteFile(filepath.Join(artifactDir, FailureSummaryFile), data, 0644)
+}
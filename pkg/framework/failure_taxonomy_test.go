@@ -0,0 +1,155 @@
+package framework
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategorizeFailureReport(t *testing.T) {
+
+	for _, tc := range []struct {
+		name     string
+		report   FailureReport
+		category string
+		rule     string
+	}{
+		{
+			name:     "api server unreachable",
+			report:   FailureReport{Message: `Post "https://api.ci.example.com:6443/api/v1/namespaces": dial tcp 10.0.0.1:6443: connect: connection refused`},
+			category: FailureCategoryInfra,
+			rule:     "cluster-unreachable",
+		},
+		{
+			name:     "quay rate limited",
+			report:   FailureReport{Message: "failed to create the image repository: 429 Too Many Requests"},
+			category: FailureCategoryFlakyExternalService,
+			rule:     "external-service-unavailable",
+		},
+		{
+			name: "github timeout in the PipelineRun",
+			report: FailureReport{Message: "PipelineRun didn't succeed", PipelineRuns: []FailedPipelineRun{
+				{Name: "devfile-sample-on-push", Reason: "Failed", Message: "fatal: unable to access 'https://github.com/redhat-appstudio-qe/devfile-sample/': Operation timed out"},
+			}},
+			category: FailureCategoryFlakyExternalService,
+			rule:     "external-service-timeout",
+		},
+		{
+			name:     "panic in the test",
+			report:   FailureReport{Message: "Test Panicked\nruntime error: invalid memory address or nil pointer dereference", Location: "tests/build/build.go:429"},
+			category: FailureCategoryTestBug,
+			rule:     "test-panic",
+		},
+		{
+			name: "failed PipelineRun",
+			report: FailureReport{Message: "PipelineRun didn't succeed", PipelineRuns: []FailedPipelineRun{
+				{Name: "devfile-sample-on-push", Reason: "Failed", Message: `Tasks Completed: 3 (Failed: 1, Cancelled 0), Skipped: 2`},
+			}},
+			category: FailureCategoryProductBug,
+			rule:     "pipelinerun-failed",
+		},
+		{
+			name: "controller errors",
+			report: FailureReport{Message: "Timed out waiting for the Component to be ready", ControllerErrors: []ControllerError{
+				{Pod: "pod-build-service-controller-manager-manager.log", Line: `{"level":"error","ts":"2023-08-18T01:30:06Z","msg":"Reconciler error"}`},
+			}},
+			category: FailureCategoryProductBug,
+			rule:     "controller-errors",
+		},
+		{
+			name:     "no evidence",
+			report:   FailureReport{Message: "Expected <bool>: false to be true"},
+			category: FailureCategoryUnknown,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.NoError(t, tc.report.Categorize(DefaultFailureRules))
+			assert.Equal(t, tc.category, tc.report.Category)
+			assert.Equal(t, tc.rule, tc.report.Rule)
+		})
+	}
+}
+
+func TestLoadFailureRules(t *testing.T) {
+
+	rules, err := LoadFailureRules("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultFailureRules, rules)
+
+	taxonomy := filepath.Join(t.TempDir(), "taxonomy.yaml")
+	assert.NoError(t, os.WriteFile(taxonomy, []byte(`
+- name: release-helper
+  category: test bug
+  sources: [location]
+  pattern: ^pkg/clients/release/
+- name: anything
+  category: product bug
+  pattern: .
+`), 0644))
+	rules, err = LoadFailureRules(taxonomy)
+	assert.NoError(t, err)
+	assert.Len(t, rules, 2)
+
+	report := FailureReport{Message: "connection refused", Location: "pkg/clients/release/releases.go:42"}
+	assert.NoError(t, report.Categorize(rules))
+	assert.Equal(t, FailureCategoryTestBug, report.Category)
+	assert.Equal(t, "release-helper", report.Rule)
+
+	assert.NoError(t, os.WriteFile(taxonomy, []byte("- name: invalid\n  category: infra\n  pattern: '(unclosed'\n"), 0644))
+	_, err = LoadFailureRules(taxonomy)
+	assert.Error(t, err)
+}
+
+func TestControllerErrors(t *testing.T) {
+
+	logs := `{"level":"info","ts":"2023-08-18T01:28:06Z","msg":"Reconciling"}
+{"level":"error","ts":"2023-08-18T01:29:06Z","msg":"Reconciler error","error":"conflict"}
+2023-08-18T01:30:06.213Z	ERROR	ComponentImageRepository	failed to create the repository
+2023-08-18T01:30:07.213Z	INFO	ComponentImageRepository	error handled, retrying
+time="2023-08-18T01:31:06Z" level=error msg="failed to sync"
+E0818 01:32:06.000000       1 reflector.go:147] failed to list *v1.Secret`
+
+	errors := controllerErrors("pod-image-controller-manager.log", logs)
+	assert.Len(t, errors, 4)
+	assert.Equal(t, "pod-image-controller-manager.log", errors[0].Pod)
+	assert.Contains(t, errors[3].Line, "reflector.go:147")
+}
+
+func TestSummarizeFailureReports(t *testing.T) {
+
+	artifactDir := t.TempDir()
+	for dir, report := range map[string]FailureReport{
+		"[ Build service] builds":       {Spec: "[build-service-suite Build service] builds", Message: "PipelineRun failed", Category: FailureCategoryProductBug, Rule: "pipelinerun-failed"},
+		"[ Release service] releases":   {Spec: "[release-service-suite Release service] releases", Message: "429", Category: FailureCategoryFlakyExternalService, Rule: "external-service-unavailable"},
+		"[ Release service] advisories": {Spec: "[release-service-suite Release service] advisories", Message: "timed out", Category: FailureCategoryProductBug, Rule: "controller-errors"},
+	} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(artifactDir, dir), 0775))
+		data, err := json.Marshal(report)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(filepath.Join(artifactDir, dir, FailureReportFile), data, 0644))
+	}
+
+	summary, err := StoreFailureSummary(artifactDir)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, summary.Total)
+	assert.Equal(t, map[string]int{FailureCategoryProductBug: 2, FailureCategoryFlakyExternalService: 1}, summary.Categories)
+	var specs []string
+	for _, spec := range summary.Specs {
+		specs = append(specs, spec.Spec)
+	}
+	assert.Equal(t, []string{
+		"[release-service-suite Release service] releases",
+		"[build-service-suite Build service] builds",
+		"[release-service-suite Release service] advisories",
+	}, specs)
+	assert.Equal(t, filepath.Join("[ Build service] builds", FailureReportFile), summary.Specs[1].Report)
+
+	data, err := os.ReadFile(filepath.Join(artifactDir, FailureSummaryFile))
+	assert.NoError(t, err)
+	var stored FailureSummary
+	assert.NoError(t, json.Unmarshal(data, &stored))
+	assert.Equal(t, *summary, stored)
+}
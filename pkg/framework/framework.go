@@ -44,18 +44,29 @@ type Framework struct {
 	UserNamespace        string
 	UserName             string
 	UserToken            string
+	// UserLease is the lease of the Dev Sandbox user when it was leased from the pool of SANDBOX_USER_POOL_SIZE users
+	UserLease *sandbox.UserLease
+	// leaseKey is the userName the user was leased for
+	leaseKey string
 }
 
 func NewFramework(userName string, stageConfig ...utils.Options) (*Framework, error) {
 	return NewFrameworkWithTimeout(userName, time.Second*60, stageConfig...)
 }
 
+// NewFrameworkWithTimeout returns a framework for the given userName. On stage, when SANDBOX_USER_POOL_SIZE is set,
+// the user is leased from the pool of pooled users instead: userName only keys the lease, so that the frameworks
+// created again for it get the same user, and the name and namespace of the user, i.e. the ones derived from
+// RELEASE_DEV_WORKSPACE and RELEASE_MANAGED_WORKSPACE, are ignored in favour of the ones of the framework. The lease is
+// released by a cleanup of the node creating the framework.
 func NewFrameworkWithTimeout(userName string, timeout time.Duration, options ...utils.Options) (*Framework, error) {
 	var err error
 	var k *kubeCl.K8SClient
 	var clusterAppDomain, openshiftConsoleHost string
 	var option utils.Options
 	var asUser *ControllerHub
+	var lease *sandbox.UserLease
+	var leased bool
 
 	if userName == "" {
 		return nil, fmt.Errorf("userName cannot be empty when initializing a new framework instance")
@@ -72,24 +83,45 @@ func NewFrameworkWithTimeout(userName string, timeout time.Duration, options ...
 
 	var asAdmin *ControllerHub
 	if isStage {
-		// in some very rare cases fail to get the client for some timeout in member operator.
-		// Just try several times to get the user kubeconfig
-		err = retry.Do(
-			func() error {
-				if k, err = kubeCl.NewDevSandboxProxyClient(userName, option); err != nil {
-					ginkgo.GinkgoWriter.Printf("error when creating dev sandbox proxy client: %+v\n", err)
-				}
-				return err
-			},
-			retry.Attempts(20),
-		)
-
+		pool, err := sandboxUserPool(option)
 		if err != nil {
-			return nil, fmt.Errorf("error when initializing kubernetes clients: %v", err)
+			return nil, err
 		}
-		asUser, err = InitControllerHub(k.AsKubeDeveloper)
-		if err != nil {
-			return nil, fmt.Errorf("error when initializing appstudio hub controllers for sandbox user: %v", err)
+		if pool != nil {
+			if lease, leased, err = leaseSandboxUser(pool, userName); err != nil {
+				return nil, fmt.Errorf("error when leasing a sandbox user: %v", err)
+			}
+			if k, err = sandboxUserClient(lease); err == nil {
+				asUser, err = InitControllerHub(k.AsKubeDeveloper)
+			}
+			if err != nil {
+				if leased {
+					if releaseErr := (&Framework{UserLease: lease, leaseKey: userName}).ReleaseUser(); releaseErr != nil {
+						ginkgo.GinkgoWriter.Printf("failed to release the sandbox user %s: %v\n", lease.UserName, releaseErr)
+					}
+				}
+				return nil, fmt.Errorf("error when initializing appstudio hub controllers for sandbox user: %v", err)
+			}
+		} else {
+			// in some very rare cases fail to get the client for some timeout in member operator.
+			// Just try several times to get the user kubeconfig
+			err = retry.Do(
+				func() error {
+					if k, err = kubeCl.NewDevSandboxProxyClient(userName, option); err != nil {
+						ginkgo.GinkgoWriter.Printf("error when creating dev sandbox proxy client: %+v\n", err)
+					}
+					return err
+				},
+				retry.Attempts(20),
+			)
+
+			if err != nil {
+				return nil, fmt.Errorf("error when initializing kubernetes clients: %v", err)
+			}
+			asUser, err = InitControllerHub(k.AsKubeDeveloper)
+			if err != nil {
+				return nil, fmt.Errorf("error when initializing appstudio hub controllers for sandbox user: %v", err)
+			}
 		}
 		asAdmin = asUser
 
@@ -156,7 +188,7 @@ func NewFrameworkWithTimeout(userName string, timeout time.Duration, options ...
 
 	}
 
-	return &Framework{
+	fw := &Framework{
 		AsKubeAdmin:          asAdmin,
 		AsKubeDeveloper:      asUser,
 		ClusterAppDomain:     clusterAppDomain,
@@ -166,7 +198,17 @@ func NewFrameworkWithTimeout(userName string, timeout time.Duration, options ...
 		UserNamespace:        k.UserNamespace,
 		UserName:             k.UserName,
 		UserToken:            k.UserToken,
-	}, nil
+		UserLease:            lease,
+		leaseKey:             userName,
+	}
+	if leased {
+		ginkgo.DeferCleanup(func() {
+			if err := fw.ReleaseUser(); err != nil {
+				ginkgo.GinkgoWriter.Printf("failed to release the sandbox user %s: %v\n", fw.UserName, err)
+			}
+		})
+	}
+	return fw, nil
 }
 
 func InitControllerHub(cc *kubeCl.CustomClient) (*ControllerHub, error) {
@@ -0,0 +1,217 @@
+package framework
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// klogPattern matches the header of the klog lines, i.e. `I1018 12:00:00.000000`, which has no year nor time zone
+	klogPattern = regexp.MustCompile(`^[IWEF](\d{2})(\d{2}) (\d{2}):(\d{2}):(\d{2})(\.\d+)?\s`)
+	// timestampPattern matches the RFC3339 timestamps and the ones without time zone or with a space as date/time separator
+	timestampPattern = regexp.MustCompile(`(\d{4}-\d{2}-\d{2})[T ](\d{2}:\d{2}:\d{2})([.,]\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+	// keyValuePattern matches the key/value pairs of the logr and logfmt lines, i.e. `"namespace"="tenant"` or `name=component`
+	keyValuePattern = regexp.MustCompile(`"?([\w.-]+)"?=("(?:[^"\\]|\\.)*"|[^\s"]+)`)
+)
+
+// timestampKeys are the keys of the timestamp of the JSON (zap) and logr lines
+var timestampKeys = []string{"ts", "time", "timestamp", "@timestamp"}
+
+// LogLine is a line of a controller log
+type LogLine struct {
+	Text string
+	// Time is the timestamp of the line, zero when the line has none, i.e. a stack trace line
+	Time time.Time
+	// Namespaces and Names are the values of the namespace and name fields of the line
+	Namespaces []string
+	Names      []string
+}
+
+// ParseLogLine parses the timestamp and the namespace/name fields of a klog, zap (JSON or console), logr or plain
+// RFC3339 line. Timestamps without time zone are UTC, the ones without year, i.e. klog, are the closest to reference.
+func ParseLogLine(text string, reference time.Time) LogLine {
+	line := LogLine{Text: text}
+
+	var fields map[string]any
+	if start := strings.Index(text, "{"); start != -1 {
+		if err := json.Unmarshal([]byte(text[start:]), &fields); err != nil {
+			fields = nil
+		}
+	}
+	if fields != nil {
+		line.collectFields(fields)
+		if strings.HasPrefix(strings.TrimSpace(text), "{") {
+			for _, key := range timestampKeys {
+				if ts, ok := parseTimestampValue(fields[key]); ok {
+					line.Time = ts
+					return line
+				}
+			}
+		}
+	} else {
+		for _, kv := range keyValuePattern.FindAllStringSubmatch(text, -1) {
+			line.addField(kv[1], strings.Trim(kv[2], `"`))
+		}
+	}
+
+	if m := klogPattern.FindStringSubmatch(text); m != nil {
+		line.Time = klogTime(m, reference)
+	} else if ts, ok := parseTimestamp(text); ok {
+		line.Time = ts
+	}
+	return line
+}
+
+// collectFields collects the namespace and name fields of the JSON object and of its nested objects,
+// i.e. `"Component": {"name": "...", "namespace": "..."}`
+func (l *LogLine) collectFields(fields map[string]any) {
+	for key, value := range fields {
+		switch v := value.(type) {
+		case string:
+			l.addField(key, v)
+		case map[string]any:
+			l.collectFields(v)
+		}
+	}
+}
+
+// addField adds the value of a namespace or name field, the `namespace/name` values of the request, object or pod fields are split
+func (l *LogLine) addField(key, value string) {
+	if value == "" {
+		return
+	}
+	switch strings.ToLower(key) {
+	case "namespace":
+		l.Namespaces = append(l.Namespaces, value)
+	case "name", "resource":
+		l.Names = append(l.Names, value)
+	case "request", "object", "pod", "key":
+		if namespace, name, ok := strings.Cut(value, "/"); ok {
+			l.Namespaces = append(l.Namespaces, namespace)
+			l.Names = append(l.Names, name)
+		}
+	}
+}
+
+func parseTimestampValue(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case float64:
+		// zap epoch timestamps are in seconds, some encoders use milliseconds
+		if v > 1e12 {
+			v /= 1000
+		}
+		// a float64 epoch is only precise to the microsecond
+		sec, frac := math.Modf(v)
+		return time.Unix(int64(sec), int64(math.Round(frac*1e6))*1e3).UTC(), true
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return parseTimestampValue(f)
+		}
+		return parseTimestamp(v)
+	}
+	return time.Time{}, false
+}
+
+func parseTimestamp(text string) (time.Time, bool) {
+	m := timestampPattern.FindStringSubmatch(text)
+	if m == nil {
+		return time.Time{}, false
+	}
+	layout, value := "2006-01-02T15:04:05", m[1]+"T"+m[2]
+	if m[3] != "" {
+		layout, value = layout+".999999999", value+"."+m[3][1:]
+	}
+	if m[4] != "" {
+		layout, value = layout+"Z07:00", value+m[4]
+		if m[4] != "Z" && !strings.Contains(m[4], ":") {
+			value = value[:len(value)-2] + ":" + value[len(value)-2:]
+		}
+	}
+	ts, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts.UTC(), true
+}
+
+// klogTime returns the time of a klog header, in the year which makes it the closest to the reference
+func klogTime(m []string, reference time.Time) time.Time {
+	atoi := func(s string) int {
+		i, _ := strconv.Atoi(s)
+		return i
+	}
+	var nsec int
+	if m[6] != "" {
+		frac := (m[6][1:] + "000000000")[:9]
+		nsec = atoi(frac)
+	}
+	ts := time.Date(reference.Year(), time.Month(atoi(m[1])), atoi(m[2]), atoi(m[3]), atoi(m[4]), atoi(m[5]), nsec, time.UTC)
+	if ts.Sub(reference) > 183*24*time.Hour {
+		ts = ts.AddDate(-1, 0, 0)
+	} else if reference.Sub(ts) > 183*24*time.Hour {
+		ts = ts.AddDate(1, 0, 0)
+	}
+	return ts
+}
+
+// LogFilter keeps the lines of the logs logged in a time window, i.e. while a spec ran, and optionally only the
+// ones relevant to the spec. The lines without timestamp, i.e. stack traces, follow the line they continue.
+type LogFilter struct {
+	Start time.Time
+	// End is the end of the window, the window is open ended when zero
+	End time.Time
+	// Namespaces and Names, when set, keep only the lines with one of these namespace or name fields,
+	// or, when a line has no such fields, which mention one of them
+	Namespaces []string
+	Names      []string
+}
+
+// Filter returns the lines of the logs kept by the filter
+func (f LogFilter) Filter(logs string) string {
+	var ret []string
+	keep := false
+	for _, text := range strings.Split(logs, "\n") {
+		line := ParseLogLine(text, f.Start)
+		if !line.Time.IsZero() {
+			keep = f.inWindow(line.Time) && f.isRelevant(line)
+		}
+		if keep {
+			ret = append(ret, text)
+		}
+	}
+	return strings.Join(ret, "\n")
+}
+
+func (f LogFilter) inWindow(ts time.Time) bool {
+	return !ts.Before(f.Start) && (f.End.IsZero() || !ts.After(f.End))
+}
+
+func (f LogFilter) isRelevant(line LogLine) bool {
+	if len(f.Namespaces) == 0 && len(f.Names) == 0 {
+		return true
+	}
+	if len(line.Namespaces) == 0 && len(line.Names) == 0 {
+		for _, value := range append(slices.Clone(f.Namespaces), f.Names...) {
+			if strings.Contains(line.Text, value) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, namespace := range line.Namespaces {
+		if slices.Contains(f.Namespaces, namespace) {
+			return true
+		}
+	}
+	for _, name := range line.Names {
+		if slices.Contains(f.Names, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package framework
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLogLine(t *testing.T) {
+
+	reference := time.Date(2023, 8, 18, 1, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name       string
+		line       string
+		time       time.Time
+		namespaces []string
+		names      []string
+	}{
+		{
+			name:       "klog",
+			line:       `I0818 01:18:58.654321       1 controller.go:42] "Reconciling" pod="tenant/build-pod"`,
+			time:       time.Date(2023, 8, 18, 1, 18, 58, 654321000, time.UTC),
+			namespaces: []string{"tenant"},
+			names:      []string{"build-pod"},
+		},
+		{
+			name:       "zap JSON with epoch ts",
+			line:       `{"level":"info","ts":1692321538.654,"msg":"Reconciling","namespace":"tenant","name":"devfile-sample"}`,
+			time:       time.Date(2023, 8, 18, 1, 18, 58, 654000000, time.UTC),
+			namespaces: []string{"tenant"},
+			names:      []string{"devfile-sample"},
+		},
+		{
+			name:       "zap JSON with ISO ts and nested object",
+			line:       `{"level":"error","ts":"2023-08-18T03:18:58.654+02:00","msg":"Reconciler error","Component":{"name":"devfile-sample","namespace":"tenant"}}`,
+			time:       time.Date(2023, 8, 18, 1, 18, 58, 654000000, time.UTC),
+			namespaces: []string{"tenant"},
+			names:      []string{"devfile-sample"},
+		},
+		{
+			name:       "zap console",
+			line:       `2023-08-18T01:18:58.654Z	INFO	controllers.Component	Reconciling	{"request": "tenant/devfile-sample"}`,
+			time:       time.Date(2023, 8, 18, 1, 18, 58, 654000000, time.UTC),
+			namespaces: []string{"tenant"},
+			names:      []string{"devfile-sample"},
+		},
+		{
+			name:       "logr",
+			line:       `"level"=0 "msg"="Reconciling" "ts"="2023-08-18 01:18:58.654321" "namespace"="tenant"`,
+			time:       time.Date(2023, 8, 18, 1, 18, 58, 654321000, time.UTC),
+			namespaces: []string{"tenant"},
+		},
+		{
+			name: "timestamp without time zone",
+			line: `2023-08-18 01:18:58,654 INFO starting the server`,
+			time: time.Date(2023, 8, 18, 1, 18, 58, 654000000, time.UTC),
+		},
+		{
+			name: "stack trace",
+			line: `	/opt/app-root/src/controllers/component_controller.go:171 +0x1ab`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			line := ParseLogLine(tc.line, reference)
+			assert.Equal(t, tc.time, line.Time)
+			assert.Equal(t, tc.namespaces, line.Namespaces)
+			assert.Equal(t, tc.names, line.Names)
+		})
+	}
+
+	// the klog lines have no year, the one of the last day of the year is logged in the previous year of a January reference
+	line := ParseLogLine(`E1231 23:59:59.000000       1 reflector.go:147] failed to list *v1.Secret`, time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC))
+	assert.Equal(t, time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC), line.Time)
+}
+
+func TestLogFilterWindow(t *testing.T) {
+
+	logs := `I0818 01:18:57.000000       1 controller.go:42] before the spec
+I0818 01:18:58.100000       1 controller.go:42] during the spec
+{"level":"error","ts":1692321539.5,"msg":"Reconciler error"}
+panic: runtime error
+	/opt/app-root/src/controllers/component_controller.go:171 +0x1ab
+2023-08-18 01:19:00 INFO after the spec
+	continuation of the line after the spec`
+
+	filter := LogFilter{
+		Start: time.Date(2023, 8, 18, 1, 18, 58, 0, time.UTC),
+		End:   time.Date(2023, 8, 18, 1, 18, 59, 999000000, time.UTC),
+	}
+	assert.Equal(t, `I0818 01:18:58.100000       1 controller.go:42] during the spec
+{"level":"error","ts":1692321539.5,"msg":"Reconciler error"}
+panic: runtime error
+	/opt/app-root/src/controllers/component_controller.go:171 +0x1ab`, filter.Filter(logs))
+}
+
+func TestLogFilterRelevance(t *testing.T) {
+
+	logs := `{"level":"info","ts":"2023-08-18T01:18:58Z","msg":"Reconciling","namespace":"tenant","name":"devfile-sample"}
+{"level":"info","ts":"2023-08-18T01:18:59Z","msg":"Reconciling","namespace":"other-tenant","name":"other-component"}
+{"level":"info","ts":"2023-08-18T01:19:00Z","msg":"Reconciling","namespace":"other-tenant","name":"devfile-sample"}
+2023-08-18T01:19:01Z	INFO	creating the image repository of tenant/devfile-sample
+2023-08-18T01:19:02Z	INFO	leader election renewed`
+
+	filter := LogFilter{
+		Start:      time.Date(2023, 8, 18, 1, 18, 58, 0, time.UTC),
+		Namespaces: []string{"tenant"},
+	}
+	assert.Equal(t, `{"level":"info","ts":"2023-08-18T01:18:58Z","msg":"Reconciling","namespace":"tenant","name":"devfile-sample"}
+2023-08-18T01:19:01Z	INFO	creating the image repository of tenant/devfile-sample`, filter.Filter(logs))
+
+	filter.Names = []string{"devfile-sample"}
+	assert.Equal(t, `{"level":"info","ts":"2023-08-18T01:18:58Z","msg":"Reconciling","namespace":"tenant","name":"devfile-sample"}
+{"level":"info","ts":"2023-08-18T01:19:00Z","msg":"Reconciling","namespace":"other-tenant","name":"devfile-sample"}
+2023-08-18T01:19:01Z	INFO	creating the image repository of tenant/devfile-sample`, filter.Filter(logs))
+}
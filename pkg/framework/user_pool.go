@@ -0,0 +1,197 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+
+	kubeCl "github.com/konflux-ci/e2e-tests/pkg/clients/kubernetes"
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/sandbox"
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
+)
+
+var (
+	userPool     *sandbox.UserPool
+	userPoolErr  error
+	userPoolOnce sync.Once
+
+	// userLeases are the users leased by the frameworks of the process, keyed by the userName of the framework, so
+	// that the frameworks created again for the same userName, i.e. in AfterAll, get the same user
+	userLeases   = map[string]*sandbox.UserLease{}
+	userLeasesMu sync.Mutex
+)
+
+// sandboxUserPool returns the pool of SANDBOX_USER_POOL_SIZE stage users shared by the frameworks of the process,
+// nil when SANDBOX_USER_POOL_SIZE is unset. The leases are stored in the cluster of KUBECONFIG.
+func sandboxUserPool(option utils.Options) (*sandbox.UserPool, error) {
+	userPoolOnce.Do(func() {
+		size, err := strconv.Atoi(os.Getenv(constants.SANDBOX_USER_POOL_SIZE_ENV))
+		if err != nil || size <= 0 {
+			return
+		}
+
+		lockClient, err := kubeCl.NewAdminKubernetesClient()
+		if err != nil {
+			userPoolErr = fmt.Errorf("error when creating the client of the sandbox user pool: %v", err)
+			return
+		}
+		sandboxController, err := sandbox.NewDevSandboxStageController()
+		if err != nil {
+			userPoolErr = err
+			return
+		}
+
+		pool := sandbox.NewUserPool(sandboxController, lockClient.KubeInterface(), utils.GetEnv(constants.SANDBOX_USER_POOL_NAMESPACE_ENV, "default"), size)
+		if ttl, err := time.ParseDuration(os.Getenv(constants.SANDBOX_USER_POOL_TTL_ENV)); err == nil && ttl > 0 {
+			pool.TTL = ttl
+		}
+		if pool.LeaseTimeout > pool.TTL/2 {
+			pool.LeaseTimeout = pool.TTL / 2
+		}
+		pool.Provision = func(userName string) (*sandbox.SandboxUserAuthInfo, error) {
+			return sandboxController.ReconcileUserCreationStage(userName, option.ApiUrl, option.Token)
+		}
+		pool.Reset = resetSandboxUserNamespace
+		userPool = pool
+	})
+	return userPool, userPoolErr
+}
+
+// PrepareSandboxUserPool provisions the users of the pool of SANDBOX_USER_POOL_SIZE stage users which aren't
+// provisioned yet or are unhealthy, so that the specs don't sign them up. It does nothing when the pool is disabled.
+func PrepareSandboxUserPool(option utils.Options) error {
+	pool, err := sandboxUserPool(option)
+	if err != nil || pool == nil {
+		return err
+	}
+	if err := pool.Prepare(context.Background()); err != nil {
+		return fmt.Errorf("error when preparing the sandbox user pool: %v", err)
+	}
+	if health, err := pool.Health(context.Background()); err == nil {
+		ginkgo.GinkgoWriter.Printf("sandbox user pool: %s\n", health)
+	}
+	return nil
+}
+
+// resetSandboxUserNamespace deletes the resources the specs created in the namespace of a pooled user
+func resetSandboxUserNamespace(info *sandbox.SandboxUserAuthInfo) error {
+	client, err := kubeCl.CreateAPIProxyClient(info.UserToken, info.ProxyUrl)
+	if err != nil {
+		return err
+	}
+	hub, err := InitControllerHub(client)
+	if err != nil {
+		return err
+	}
+
+	namespace := info.UserNamespace
+	if err := hub.ReleaseController.DeleteAllReleasesInASpecificNamespace(namespace); err != nil {
+		return err
+	}
+	if err := hub.ReleaseController.DeleteAllReleasePlansInASpecificNamespace(namespace); err != nil {
+		return err
+	}
+	// leftover ReleasePlanAdmissions would make the matching of the ReleasePlans of the next holder ambiguous
+	if err := hub.ReleaseController.DeleteAllReleasePlanAdmissionsInASpecificNamespace(namespace); err != nil {
+		return err
+	}
+	if err := hub.TektonController.DeleteAllEnterpriseContractPoliciesInASpecificNamespace(namespace); err != nil {
+		return err
+	}
+	if err := hub.HasController.DeleteAllComponentsInASpecificNamespace(namespace, time.Minute*2); err != nil {
+		return err
+	}
+	if err := hub.HasController.DeleteAllApplicationsInASpecificNamespace(namespace, time.Minute*2); err != nil {
+		return err
+	}
+	if err := hub.IntegrationController.DeleteAllSnapshotsInASpecificNamespace(namespace, time.Minute*2); err != nil {
+		return err
+	}
+	if err := hub.TektonController.DeleteAllPipelineRunsInASpecificNamespace(namespace); err != nil {
+		return err
+	}
+	return hub.CommonController.DeleteAllUnmanagedSecretsInASpecificNamespace(namespace)
+}
+
+// leaseSandboxUser returns the user leased for userName by the process, or leases a user of the pool, waiting at most
+// for the LeaseTimeout of the pool. The second return value reports whether the user was newly leased.
+func leaseSandboxUser(pool *sandbox.UserPool, userName string) (*sandbox.UserLease, bool, error) {
+	userLeasesMu.Lock()
+	defer userLeasesMu.Unlock()
+	if lease, ok := userLeases[userName]; ok {
+		return lease, false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pool.LeaseTimeout)
+	defer cancel()
+	lease, err := pool.Lease(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	userLeases[userName] = lease
+	return lease, true, nil
+}
+
+// sandboxUserClient returns the clients of a leased user
+func sandboxUserClient(lease *sandbox.UserLease) (*kubeCl.K8SClient, error) {
+	client, err := kubeCl.CreateAPIProxyClient(lease.UserToken, lease.ProxyUrl)
+	if err != nil {
+		return nil, err
+	}
+	return &kubeCl.K8SClient{
+		AsKubeAdmin:     client,
+		AsKubeDeveloper: client,
+		ProxyUrl:        lease.ProxyUrl,
+		UserName:        lease.UserName,
+		UserNamespace:   lease.UserNamespace,
+		UserToken:       lease.UserToken,
+	}, nil
+}
+
+// releaseSandboxUser releases the lease taken for userName, when it is still the lease of the process.
+// userLeasesMu must be held.
+func releaseSandboxUser(userName string, lease *sandbox.UserLease) error {
+	if userLeases[userName] != lease {
+		return nil
+	}
+	delete(userLeases, userName)
+	return userPool.Release(context.Background(), lease)
+}
+
+// ReleaseUser returns the Dev Sandbox user leased by the framework to the pool of SANDBOX_USER_POOL_SIZE users,
+// after resetting its namespace. It is registered as a cleanup of the node which created the framework, and does
+// nothing when the user wasn't leased or was already released.
+func (f *Framework) ReleaseUser() error {
+	userLeasesMu.Lock()
+	defer userLeasesMu.Unlock()
+	if f.UserLease == nil || userPool == nil {
+		return nil
+	}
+	lease := f.UserLease
+	f.UserLease = nil
+	if err := releaseSandboxUser(f.leaseKey, lease); err != nil {
+		return err
+	}
+
+	if health, err := userPool.Health(context.Background()); err == nil {
+		ginkgo.GinkgoWriter.Printf("sandbox user pool: %s\n", health)
+	}
+	return nil
+}
+
+// RenewUser extends the lease of the Dev Sandbox user leased by the framework, for the specs running longer than
+// SANDBOX_USER_POOL_TTL. It does nothing when the user wasn't leased or was released.
+func (f *Framework) RenewUser() error {
+	userLeasesMu.Lock()
+	defer userLeasesMu.Unlock()
+	if f.UserLease == nil || userPool == nil {
+		return nil
+	}
+	return userPool.Renew(context.Background(), f.UserLease)
+}
@@ -0,0 +1,267 @@
+package logs
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/konflux-ci/e2e-tests/pkg/testspecs"
+	"github.com/onsi/ginkgo/v2/types"
+	"sigs.k8s.io/yaml"
+)
+
+// artifactIndexTemplate is embedded as the index is generated from the artifact directory, wherever the tests run
+//
+//go:embed templates/artifact_index.html.tmpl
+var artifactIndexTemplate string
+
+// artifactKindPrefixes are the name prefixes of the resource YAMLs stored by the clients, which are marshalled
+// without their kind, the more specific first. An empty kind means that the artifact is not a resource.
+var artifactKindPrefixes = []struct {
+	prefix string
+	kind   string
+}{
+	{"releaseplanadmission-", "ReleasePlanAdmission"},
+	{"release-tracking-", ""},
+	{"application-", "Application"},
+	{"pipelineRun-", "PipelineRun"},
+	{"component-", "Component"},
+	{"snapshot-", "Snapshot"},
+	{"release-", "Release"},
+	{"taskRun-", "TaskRun"},
+}
+
+// ArtifactIndex links the specs of a run to the artifacts they stored
+type ArtifactIndex struct {
+	Reports []string
+	// States counts the specs of the index by their state
+	States map[string]int
+	// Specs are the specs which were executed or stored artifacts, the failed ones first
+	Specs []SpecArtifacts
+	// UnmatchedDirectories are the artifact directories of no spec of the reports
+	UnmatchedDirectories []Artifact
+}
+
+// SpecArtifacts are the artifacts stored by a spec, i.e. by logs.StoreArtifacts or framework.ReportFailure
+type SpecArtifacts struct {
+	Spec            string
+	Labels          []string
+	State           string
+	Duration        time.Duration
+	Failure         string
+	FailureLocation string
+	// Directory is the artifact directory of the spec, empty when the spec stored no artifact
+	Directory *Artifact
+	// Logs are the logs of the spec grouped by their source, i.e. the PipelineRun or pod logs
+	Logs []ArtifactGroup
+	// Resources are the YAMLs of the resources grouped by their kind
+	Resources []ArtifactGroup
+	Other     []Artifact
+}
+
+// ArtifactGroup is a named group of artifacts of a spec
+type ArtifactGroup struct {
+	Name      string
+	Artifacts []Artifact
+}
+
+// Artifact is a file or directory of the artifact directory
+type Artifact struct {
+	Name string
+	// Link is the URL of the artifact relative to the index
+	Link string
+}
+
+// NewArtifactIndex correlates the artifact directories created under artifactDir by createArtifactDirectory, which are
+// named after the specs with ShortenStringAddHash, with the specs of the ginkgo JSON reports.
+func NewArtifactIndex(artifactDir string, reportFiles []string) (*ArtifactIndex, error) {
+
+	index := &ArtifactIndex{States: map[string]int{}}
+	matched := map[string]bool{}
+	for _, reportFile := range reportFiles {
+		reports, err := testspecs.LoadGinkgoReports(reportFile)
+		if err != nil {
+			return nil, err
+		}
+		index.Reports = append(index.Reports, reportFile)
+
+		for _, report := range reports {
+			for _, sr := range report.SpecReports {
+				if sr.LeafNodeType != types.NodeTypeIt {
+					continue
+				}
+				spec, err := newSpecArtifacts(artifactDir, sr)
+				if err != nil {
+					return nil, err
+				}
+				if spec.Directory == nil && sr.State.Is(types.SpecStateSkipped|types.SpecStatePending) {
+					continue
+				}
+				if spec.Directory != nil {
+					matched[strings.Split(spec.Directory.Name, string(filepath.Separator))[0]] = true
+				}
+				index.States[spec.State]++
+				index.Specs = append(index.Specs, spec)
+			}
+		}
+	}
+	sort.SliceStable(index.Specs, func(i, j int) bool {
+		return isFailure(index.Specs[i].State) && !isFailure(index.Specs[j].State)
+	})
+
+	entries, err := os.ReadDir(artifactDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && !matched[entry.Name()] {
+			index.UnmatchedDirectories = append(index.UnmatchedDirectories, newArtifact(entry.Name()))
+		}
+	}
+
+	return index, nil
+}
+
+// RenderArtifactIndex writes the index into the index.html of the artifact directory
+func RenderArtifactIndex(artifactDir string, index *ArtifactIndex) error {
+
+	tmpl, err := template.New("index").Parse(artifactIndexTemplate)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(artifactDir, "index.html"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := tmpl.Execute(f, index); err != nil {
+		return fmt.Errorf("failed to render the artifact index: %v", err)
+	}
+	return nil
+}
+
+// StoreArtifactIndex writes the index.html of the artifact directory, linking the specs of the ginkgo JSON reports to their artifacts
+func StoreArtifactIndex(artifactDir string, reportFiles ...string) error {
+
+	index, err := NewArtifactIndex(artifactDir, reportFiles)
+	if err != nil {
+		return err
+	}
+	return RenderArtifactIndex(artifactDir, index)
+}
+
+func newSpecArtifacts(artifactDir string, sr types.SpecReport) (SpecArtifacts, error) {
+
+	spec := SpecArtifacts{
+		Spec:     sr.FullText(),
+		Labels:   sr.Labels(),
+		State:    sr.State.String(),
+		Duration: sr.RunTime.Round(time.Millisecond),
+	}
+	if sr.State.Is(types.SpecStateFailureStates) {
+		spec.Failure = sr.Failure.Message
+		spec.FailureLocation = sr.Failure.Location.String()
+	}
+
+	directory := ShortenStringAddHash(sr)
+	if strings.TrimSpace(directory) == "" {
+		return spec, nil
+	}
+	entries, err := os.ReadDir(filepath.Join(artifactDir, directory))
+	if os.IsNotExist(err) {
+		return spec, nil
+	}
+	if err != nil {
+		return spec, err
+	}
+	dir := newArtifact(filepath.Clean(directory))
+	spec.Directory = &dir
+
+	logGroups := map[string][]Artifact{}
+	resourceGroups := map[string][]Artifact{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		artifact := newArtifact(filepath.Join(dir.Name, entry.Name()))
+		artifact.Name = entry.Name()
+
+		switch ext := filepath.Ext(entry.Name()); {
+		case entry.Name() == "test-timing":
+			logGroups["Test timing"] = append(logGroups["Test timing"], artifact)
+		case ext == ".log":
+			group := "Other logs"
+			if strings.HasPrefix(entry.Name(), "pipelineRun-") || strings.HasPrefix(entry.Name(), "release-pipelinerun-") {
+				group = "PipelineRun logs"
+			} else if strings.HasPrefix(entry.Name(), "pod-") {
+				group = "Pod logs"
+			}
+			logGroups[group] = append(logGroups[group], artifact)
+		case ext == ".yaml" || ext == ".yml" || ext == ".json":
+			if kind := artifactKind(filepath.Join(artifactDir, dir.Name, entry.Name())); kind != "" {
+				resourceGroups[kind] = append(resourceGroups[kind], artifact)
+			} else {
+				spec.Other = append(spec.Other, artifact)
+			}
+		default:
+			spec.Other = append(spec.Other, artifact)
+		}
+	}
+
+	for _, group := range []string{"PipelineRun logs", "Pod logs", "Other logs", "Test timing"} {
+		if artifacts, ok := logGroups[group]; ok {
+			spec.Logs = append(spec.Logs, ArtifactGroup{Name: group, Artifacts: artifacts})
+		}
+	}
+	kinds := make([]string, 0, len(resourceGroups))
+	for kind := range resourceGroups {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	for _, kind := range kinds {
+		spec.Resources = append(spec.Resources, ArtifactGroup{Name: kind, Artifacts: resourceGroups[kind]})
+	}
+
+	return spec, nil
+}
+
+// artifactKind returns the kind of the resource stored in the file, from its content or from its name
+func artifactKind(file string) string {
+
+	var resource struct {
+		Kind string `json:"kind"`
+	}
+	if content, err := os.ReadFile(file); err == nil && yaml.Unmarshal(content, &resource) == nil && resource.Kind != "" {
+		return resource.Kind
+	}
+	for _, p := range artifactKindPrefixes {
+		if strings.HasPrefix(filepath.Base(file), p.prefix) {
+			return p.kind
+		}
+	}
+	return ""
+}
+
+// newArtifact returns the artifact of the path relative to the artifact directory. The directories of the specs
+// contain spaces and colons, so the link is escaped and made explicitly relative.
+func newArtifact(path string) Artifact {
+
+	segments := strings.Split(filepath.ToSlash(path), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return Artifact{Name: path, Link: "./" + strings.Join(segments, "/")}
+}
+
+func isFailure(state string) bool {
+	return state != types.SpecStatePassed.String() && state != types.SpecStateSkipped.String() && state != types.SpecStatePending.String()
+}
@@ -0,0 +1,147 @@
+package logs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2/reporters"
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+func artifactSpecReport(leaf string, line int, state types.SpecState, ordered bool) types.SpecReport {
+	sr := types.SpecReport{
+		ContainerHierarchyTexts:     []string{"[book-suite Book service E2E tests]"},
+		ContainerHierarchyLocations: []types.CodeLocation{{FileName: "books.go", LineNumber: 1}},
+		LeafNodeType:                types.NodeTypeIt,
+		LeafNodeText:                leaf,
+		LeafNodeLocation:            types.CodeLocation{FileName: "books.go", LineNumber: line},
+		State:                       state,
+	}
+	if ordered {
+		sr.ContainerHierarchyTexts = append(sr.ContainerHierarchyTexts, "Categorizing (book) length")
+		sr.ContainerHierarchyLocations = append(sr.ContainerHierarchyLocations, types.CodeLocation{FileName: "books.go", LineNumber: 10})
+		sr.IsInOrderedContainer = true
+	}
+	return sr
+}
+
+// writeArtifactRun writes the JSON report of a run and the artifacts stored by its failed spec
+func writeArtifactRun(t *testing.T) (string, string) {
+	failed := artifactSpecReport("is a novel", 12, types.SpecStateFailed, true)
+	failed.Failure = types.Failure{Message: "Expected <int>: 120 to be > <int>: 200", Location: types.CodeLocation{FileName: "books.go", LineNumber: 13}}
+	report := types.Report{SuiteDescription: "E2E", SpecReports: types.SpecReports{
+		{LeafNodeType: types.NodeTypeBeforeSuite, State: types.SpecStatePassed},
+		failed,
+		artifactSpecReport("has a title", 20, types.SpecStatePassed, false),
+		artifactSpecReport("is filtered out", 24, types.SpecStateSkipped, false),
+	}}
+
+	artifactDir := t.TempDir()
+	reportFile := filepath.Join(artifactDir, "e2e-report.json")
+	if err := reporters.GenerateJSONReport(report, reportFile); err != nil {
+		t.Fatal(err)
+	}
+
+	specDir := filepath.Join(artifactDir, ShortenStringAddHash(failed))
+	if err := os.MkdirAll(specDir, 0775); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"pipelineRun-novel-build.log":      "step-build: counting the pages",
+		"pipelineRun-novel-build.yaml":     "metadata:\n  name: novel-build\n",
+		"taskRun-novel-build-count.yaml":   "metadata:\n  name: novel-build-count\n",
+		"pod-book-controller-manager.log":  "reconciling the book",
+		"test-timing":                      "Test started at: 2026-10-01",
+		"scenario.yaml":                    "apiVersion: appstudio.redhat.com/v1beta2\nkind: IntegrationTestScenario\n",
+		"release-tracking-novel.json":      `{"releaseName": "novel"}`,
+		"component-condition-status-x.log": "Ready",
+	} {
+		if err := os.WriteFile(filepath.Join(specDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(artifactDir, "stale-spec"), 0775); err != nil {
+		t.Fatal(err)
+	}
+
+	return artifactDir, reportFile
+}
+
+func TestNewArtifactIndex(t *testing.T) {
+	artifactDir, reportFile := writeArtifactRun(t)
+
+	index, err := NewArtifactIndex(artifactDir, []string{reportFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Specs) != 2 || index.States["failed"] != 1 || index.States["passed"] != 1 {
+		t.Fatalf("expected a failed and a passed spec, got %v", index.States)
+	}
+
+	failed := index.Specs[0]
+	if failed.State != "failed" || failed.Directory == nil || !strings.Contains(failed.Failure, "120 to be > ") {
+		t.Fatalf("expected the failed spec with its artifacts first, got %+v", failed)
+	}
+	if !strings.HasPrefix(failed.Directory.Link, "./%5B%20Book%20service%20E2E%20tests%5D%20Categorizing%20%28book%29") {
+		t.Errorf("unexpected link of the spec directory %s", failed.Directory.Link)
+	}
+	groups := func(groups []ArtifactGroup) string {
+		var names []string
+		for _, group := range groups {
+			var artifacts []string
+			for _, artifact := range group.Artifacts {
+				artifacts = append(artifacts, artifact.Name)
+			}
+			names = append(names, group.Name+": "+strings.Join(artifacts, ", "))
+		}
+		return strings.Join(names, "; ")
+	}
+	if got, expected := groups(failed.Logs), "PipelineRun logs: pipelineRun-novel-build.log; Pod logs: pod-book-controller-manager.log; "+
+		"Other logs: component-condition-status-x.log; Test timing: test-timing"; got != expected {
+		t.Errorf("expected the logs %q, got %q", expected, got)
+	}
+	if got, expected := groups(failed.Resources), "IntegrationTestScenario: scenario.yaml; PipelineRun: pipelineRun-novel-build.yaml; "+
+		"TaskRun: taskRun-novel-build-count.yaml"; got != expected {
+		t.Errorf("expected the resources %q, got %q", expected, got)
+	}
+	if len(failed.Other) != 1 || failed.Other[0].Name != "release-tracking-novel.json" {
+		t.Errorf("unexpected other artifacts %v", failed.Other)
+	}
+
+	if index.Specs[1].Directory != nil {
+		t.Errorf("expected the passed spec without artifacts, got %v", index.Specs[1].Directory)
+	}
+	if len(index.UnmatchedDirectories) != 1 || index.UnmatchedDirectories[0].Name != "stale-spec" {
+		t.Errorf("expected the stale directory to be unmatched, got %v", index.UnmatchedDirectories)
+	}
+}
+
+func TestRenderArtifactIndex(t *testing.T) {
+	artifactDir, reportFile := writeArtifactRun(t)
+	index, err := NewArtifactIndex(artifactDir, []string{reportFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RenderArtifactIndex(artifactDir, index); err != nil {
+		t.Fatal(err)
+	}
+
+	html, err := os.ReadFile(filepath.Join(artifactDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`<a href="#spec-0">[book-suite Book service E2E tests] Categorizing (book) length is a novel</a>`,
+		`<td class="failed">failed</td>`,
+		`<pre>Expected &lt;int&gt;: 120 to be &gt; &lt;int&gt;: 200</pre>`,
+		`/pipelineRun-novel-build.log">pipelineRun-novel-build.log</a>`,
+		`<summary>TaskRun (1)</summary>`,
+		`<a href="./stale-spec/">stale-spec</a>`,
+	} {
+		if !strings.Contains(string(html), expected) {
+			t.Errorf("artifact index does not contain %q:\n%s", expected, html)
+		}
+	}
+}
@@ -38,7 +38,7 @@ func StoreResourceYaml(resource any, name string) error {
 	return StoreArtifacts(resources)
 }
 
-// StoreArtifacts stores given artifacts under artifact directory.
+// StoreArtifacts stores given artifacts under artifact directory, with their secrets redacted.
 func StoreArtifacts(artifacts map[string][]byte) error {
 	artifactsDirectory, err := createArtifactDirectory()
 	if err != nil {
@@ -47,7 +47,7 @@ func StoreArtifacts(artifacts map[string][]byte) error {
 
 	for artifact_name, artifact_value := range artifacts {
 		filePath := fmt.Sprintf("%s/%s", artifactsDirectory, artifact_name)
-		if err := os.WriteFile(filePath, []byte(artifact_value), 0644); err != nil {
+		if err := os.WriteFile(filePath, RedactSecrets(artifact_name, artifact_value), 0644); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,247 @@
+package mustgather
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	applicationsResource = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}
+	crdsResource         = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+)
+
+// tektonNamespaces are the namespaces of the Tekton config ConfigMaps
+var tektonNamespaces = []string{"openshift-pipelines", "tekton-pipelines"}
+
+// table renders the rows as aligned columns
+func table(header string, rows [][]string) []byte {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, header)
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+func addYaml(add func(string, []byte), name string, resource any) error {
+	data, err := yaml.Marshal(resource)
+	if err != nil {
+		return fmt.Errorf("marshalling %s: %w", name, err)
+	}
+	add(name, data)
+	return nil
+}
+
+// collectArgoCDApplications collects the ArgoCD Applications and a summary of their sync and health statuses
+func collectArgoCDApplications(ctx context.Context, g *Gatherer, add func(string, []byte)) error {
+	apps, err := g.DynamicClient.Resource(applicationsResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing the ArgoCD Applications: %w", err)
+	}
+
+	var rows [][]string
+	for _, app := range apps.Items {
+		sync, _, _ := unstructured.NestedString(app.Object, "status", "sync", "status")
+		health, _, _ := unstructured.NestedString(app.Object, "status", "health", "status")
+		message, _, _ := unstructured.NestedString(app.Object, "status", "operationState", "message")
+		rows = append(rows, []string{app.GetNamespace(), app.GetName(), sync, health, strings.ReplaceAll(message, "\n", " ")})
+	}
+	add("applications.txt", table("NAMESPACE\tNAME\tSYNC\tHEALTH\tMESSAGE", rows))
+	return addYaml(add, "applications.yaml", apps)
+}
+
+// collectControllers collects the Deployments and Pods of the controller namespaces, with the logs of the containers
+// and, when they restarted, the logs of their previous instance
+func collectControllers(ctx context.Context, g *Gatherer, add func(string, []byte)) error {
+	var errs []error
+	for _, namespace := range g.Namespaces {
+		deployments, err := g.KubeClient.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listing the Deployments of %s: %w", namespace, err))
+		} else if len(deployments.Items) > 0 {
+			errs = append(errs, addYaml(add, namespace+"/deployments.yaml", deployments))
+		}
+
+		pods, err := g.KubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listing the Pods of %s: %w", namespace, err))
+			continue
+		}
+		if len(pods.Items) == 0 {
+			continue
+		}
+		errs = append(errs, addYaml(add, namespace+"/pods.yaml", pods))
+
+		for _, pod := range pods.Items {
+			for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+				name := fmt.Sprintf("%s/%s-%s", namespace, pod.Name, status.Name)
+				errs = append(errs, collectContainerLogs(ctx, g, add, &pod, status.Name, false, name+".log"))
+				if status.RestartCount > 0 {
+					errs = append(errs, collectContainerLogs(ctx, g, add, &pod, status.Name, true, name+"-previous.log"))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func collectContainerLogs(ctx context.Context, g *Gatherer, add func(string, []byte), pod *corev1.Pod, container string, previous bool, name string) error {
+	stream, err := g.KubeClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: container, Previous: previous}).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("getting the logs of %s: %w", name, err)
+	}
+	defer stream.Close()
+
+	logs, err := io.ReadAll(stream)
+	if err != nil {
+		return fmt.Errorf("reading the logs of %s: %w", name, err)
+	}
+	add(name, logs)
+	return nil
+}
+
+// collectTektonConfig collects the config ConfigMaps of Tekton and Pipelines as Code, i.e. config-defaults or feature-flags
+func collectTektonConfig(ctx context.Context, g *Gatherer, add func(string, []byte)) error {
+	var errs []error
+	for _, namespace := range tektonNamespaces {
+		configMaps, err := g.KubeClient.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listing the ConfigMaps of %s: %w", namespace, err))
+			continue
+		}
+		for _, cm := range configMaps.Items {
+			if strings.HasPrefix(cm.Name, "config-") || cm.Name == "feature-flags" || cm.Name == "pipelines-as-code" {
+				errs = append(errs, addYaml(add, fmt.Sprintf("%s/%s.yaml", namespace, cm.Name), cm))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// collectCRDVersions collects the served and storage versions of the CRDs
+func collectCRDVersions(ctx context.Context, g *Gatherer, add func(string, []byte)) error {
+	crds, err := g.DynamicClient.Resource(crdsResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing the CRDs: %w", err)
+	}
+
+	var rows [][]string
+	for _, crd := range crds.Items {
+		versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+		var served []string
+		storage := ""
+		for _, v := range versions {
+			version, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(version, "name")
+			if isServed, _, _ := unstructured.NestedBool(version, "served"); isServed {
+				served = append(served, name)
+			}
+			if isStorage, _, _ := unstructured.NestedBool(version, "storage"); isStorage {
+				storage = name
+			}
+		}
+		rows = append(rows, []string{crd.GetName(), strings.Join(served, ","), storage})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+	add("versions.txt", table("NAME\tSERVED\tSTORAGE", rows))
+	return nil
+}
+
+// collectNodeConditions collects the conditions of the nodes
+func collectNodeConditions(ctx context.Context, g *Gatherer, add func(string, []byte)) error {
+	nodes, err := g.KubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing the nodes: %w", err)
+	}
+
+	var rows [][]string
+	for _, node := range nodes.Items {
+		for _, condition := range node.Status.Conditions {
+			rows = append(rows, []string{node.Name, string(condition.Type), string(condition.Status), condition.Reason, condition.LastTransitionTime.UTC().Format(time.RFC3339), condition.Message})
+		}
+	}
+	add("conditions.txt", table("NODE\tTYPE\tSTATUS\tREASON\tSINCE\tMESSAGE", rows))
+	return nil
+}
+
+// collectResourceQuotas collects the ResourceQuotas of all the namespaces with their used and hard limits
+func collectResourceQuotas(ctx context.Context, g *Gatherer, add func(string, []byte)) error {
+	quotas, err := g.KubeClient.CoreV1().ResourceQuotas(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing the ResourceQuotas: %w", err)
+	}
+
+	var rows [][]string
+	for _, quota := range quotas.Items {
+		var resources []string
+		for resource := range quota.Status.Hard {
+			resources = append(resources, string(resource))
+		}
+		sort.Strings(resources)
+		for _, resource := range resources {
+			hard := quota.Status.Hard[corev1.ResourceName(resource)]
+			used := quota.Status.Used[corev1.ResourceName(resource)]
+			rows = append(rows, []string{quota.Namespace, quota.Name, resource, used.String(), hard.String()})
+		}
+	}
+	add("resourcequotas.txt", table("NAMESPACE\tNAME\tRESOURCE\tUSED\tHARD", rows))
+	return addYaml(add, "resourcequotas.yaml", quotas)
+}
+
+// eventTime returns the time an Event was last seen
+func eventTime(event corev1.Event) time.Time {
+	switch {
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// collectEvents collects the Events of all the namespaces seen since EventsSince, the oldest first
+func collectEvents(ctx context.Context, g *Gatherer, add func(string, []byte)) error {
+	events, err := g.KubeClient.CoreV1().Events(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing the Events: %w", err)
+	}
+
+	since := time.Now().Add(-g.EventsSince)
+	var recent []corev1.Event
+	for _, event := range events.Items {
+		if !eventTime(event).Before(since) {
+			recent = append(recent, event)
+		}
+	}
+	sort.SliceStable(recent, func(i, j int) bool { return eventTime(recent[i]).Before(eventTime(recent[j])) })
+
+	var rows [][]string
+	for _, event := range recent {
+		rows = append(rows, []string{
+			eventTime(event).UTC().Format(time.RFC3339), event.Namespace, event.Type, event.Reason,
+			fmt.Sprintf("%s/%s", event.InvolvedObject.Kind, event.InvolvedObject.Name), strings.ReplaceAll(event.Message, "\n", " "),
+		})
+	}
+	add("events.txt", table("LAST SEEN\tNAMESPACE\tTYPE\tREASON\tOBJECT\tMESSAGE", rows))
+	return nil
+}
@@ -0,0 +1,195 @@
+package mustgather
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/konflux-ci/e2e-tests/pkg/logs"
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// SnapshotFile is the name of the tarball of the cluster snapshot under ARTIFACT_DIR
+	SnapshotFile = "must-gather.tar.gz"
+	// ErrorsFile lists the collectors which failed or timed out, in the tarball
+	ErrorsFile = "errors.txt"
+
+	DefaultCollectorTimeout = 2 * time.Minute
+	DefaultEventsSince      = time.Hour
+)
+
+// DefaultNamespaces are the namespaces of the Konflux controllers and of their dependencies
+var DefaultNamespaces = []string{
+	"application-service",
+	"build-service",
+	"image-controller",
+	"integration-service",
+	"release-service",
+	"enterprise-contract-service",
+	"openshift-gitops",
+	"openshift-pipelines",
+	"tekton-pipelines",
+	"toolchain-host-operator",
+	"toolchain-member-operator",
+}
+
+// Collector collects a part of the cluster snapshot, the files it adds are stored under its name in the tarball
+type Collector struct {
+	Name    string
+	Collect func(ctx context.Context, g *Gatherer, add func(name string, content []byte)) error
+}
+
+// DefaultCollectors are the collectors of the cluster snapshot
+var DefaultCollectors = []Collector{
+	{Name: "argocd", Collect: collectArgoCDApplications},
+	{Name: "controllers", Collect: collectControllers},
+	{Name: "tekton", Collect: collectTektonConfig},
+	{Name: "crds", Collect: collectCRDVersions},
+	{Name: "nodes", Collect: collectNodeConditions},
+	{Name: "resourcequotas", Collect: collectResourceQuotas},
+	{Name: "events", Collect: collectEvents},
+}
+
+// Gatherer snapshots the state of the cluster
+type Gatherer struct {
+	KubeClient    kubernetes.Interface
+	DynamicClient dynamic.Interface
+	// Namespaces are the namespaces of the controllers whose Deployments, Pods and logs are collected
+	Namespaces []string
+	Collectors []Collector
+	// Timeout is the timeout of every collector, the files a collector added before timing out are kept
+	Timeout time.Duration
+	// EventsSince is the age of the oldest collected Event
+	EventsSince time.Duration
+}
+
+// NewGatherer returns a Gatherer of the default collectors and namespaces, with the timeout of
+// MUST_GATHER_COLLECTOR_TIMEOUT when set
+func NewGatherer(kubeClient kubernetes.Interface, dynamicClient dynamic.Interface) *Gatherer {
+	timeout := DefaultCollectorTimeout
+	if t, err := time.ParseDuration(utils.GetEnv("MUST_GATHER_COLLECTOR_TIMEOUT", "")); err == nil && t > 0 {
+		timeout = t
+	}
+	return &Gatherer{
+		KubeClient:    kubeClient,
+		DynamicClient: dynamicClient,
+		Namespaces:    DefaultNamespaces,
+		Collectors:    DefaultCollectors,
+		Timeout:       timeout,
+		EventsSince:   DefaultEventsSince,
+	}
+}
+
+// Gather runs the collectors concurrently and returns the collected files, keyed by their path in the tarball,
+// with their secrets redacted. The collectors which failed or timed out are listed in ErrorsFile.
+func (g *Gatherer) Gather(ctx context.Context) map[string][]byte {
+	var mu sync.Mutex
+	files := map[string][]byte{}
+	var errs []string
+
+	var wg sync.WaitGroup
+	for _, collector := range g.Collectors {
+		wg.Add(1)
+		go func(collector Collector) {
+			defer wg.Done()
+			collectorCtx, cancel := context.WithTimeout(ctx, g.Timeout)
+			defer cancel()
+
+			add := func(name string, content []byte) {
+				name = filepath.Join(collector.Name, name)
+				mu.Lock()
+				defer mu.Unlock()
+				files[name] = logs.RedactSecrets(name, content)
+			}
+			err := collector.Collect(collectorCtx, g, add)
+			if err == nil && collectorCtx.Err() != nil {
+				err = collectorCtx.Err()
+			}
+			if err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, fmt.Sprintf("%s: %v", collector.Name, err))
+			}
+		}(collector)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		files[ErrorsFile] = []byte(strings.Join(errs, "\n") + "\n")
+	}
+	return files
+}
+
+// WriteTarball gathers the cluster snapshot into a gzipped tarball at path, whose files are under a must-gather directory
+func (g *Gatherer) WriteTarball(ctx context.Context, path string) error {
+	files := g.Gather(ctx)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0775); err != nil {
+		return err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if err := writeTarball(out, files); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return out.Close()
+}
+
+func writeTarball(w io.Writer, files map[string][]byte) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	for _, name := range names {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    filepath.ToSlash(filepath.Join("must-gather", name)),
+			Mode:    0644,
+			Size:    int64(len(files[name])),
+			ModTime: now,
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// StoreClusterSnapshot snapshots the cluster into SnapshotFile under the artifact directory
+func StoreClusterSnapshot(artifactDir string, kubeClient kubernetes.Interface, dynamicClient dynamic.Interface) (string, error) {
+	path := filepath.Join(artifactDir, SnapshotFile)
+
+	klog.Infof("storing the cluster snapshot into %s", path)
+	if err := NewGatherer(kubeClient, dynamicClient).WriteTarball(context.Background(), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
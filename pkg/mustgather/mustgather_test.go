@@ -0,0 +1,151 @@
+package mustgather
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestGatherer() *Gatherer {
+	kubeClient := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "build-service-controller-manager", Namespace: "build-service"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "build-service-controller-manager-1", Namespace: "build-service"},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "manager", RestartCount: 2},
+				{Name: "kube-rbac-proxy"},
+			}},
+		},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "feature-flags", Namespace: "openshift-pipelines"}, Data: map[string]string{"enable-api-fields": "beta"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kube-root-ca.crt", Namespace: "openshift-pipelines"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}, Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue, Reason: "KubeletHasInsufficientMemory"},
+		}}},
+		&corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "tenant"}, Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse("20")},
+			Used: corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse("19500m")},
+		}},
+		&corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "recent", Namespace: "tenant"}, Type: corev1.EventTypeWarning, Reason: "FailedScheduling",
+			LastTimestamp: metav1.NewTime(time.Now().Add(-time.Minute)), InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "build-pod"}, Message: "0/3 nodes are available"},
+		&corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "old", Namespace: "tenant"}, Type: corev1.EventTypeNormal, Reason: "Scheduled",
+			LastTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)), InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "old-pod"}},
+	)
+
+	application := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Application",
+		"metadata":   map[string]any{"name": "build-service-in-cluster-local", "namespace": "openshift-gitops"},
+		"status": map[string]any{
+			"sync":   map[string]any{"status": "OutOfSync"},
+			"health": map[string]any{"status": "Degraded"},
+		},
+	}}
+	crd := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]any{"name": "components.appstudio.redhat.com"},
+		"spec": map[string]any{"versions": []any{
+			map[string]any{"name": "v1alpha1", "served": true, "storage": true},
+		}},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		applicationsResource: "ApplicationList",
+		crdsResource:         "CustomResourceDefinitionList",
+	}, application, crd)
+
+	g := NewGatherer(kubeClient, dynamicClient)
+	g.Namespaces = []string{"build-service"}
+	return g
+}
+
+func TestGather(t *testing.T) {
+
+	files := newTestGatherer().Gather(context.Background())
+
+	for _, name := range []string{
+		"argocd/applications.yaml",
+		"controllers/build-service/deployments.yaml",
+		"controllers/build-service/pods.yaml",
+		"controllers/build-service/build-service-controller-manager-1-manager.log",
+		"controllers/build-service/build-service-controller-manager-1-manager-previous.log",
+		"controllers/build-service/build-service-controller-manager-1-kube-rbac-proxy.log",
+		"tekton/openshift-pipelines/feature-flags.yaml",
+		"resourcequotas/resourcequotas.yaml",
+	} {
+		assert.Contains(t, files, name)
+	}
+	assert.NotContains(t, files, "controllers/build-service/build-service-controller-manager-1-kube-rbac-proxy-previous.log")
+	assert.NotContains(t, files, "tekton/openshift-pipelines/kube-root-ca.crt.yaml")
+	assert.NotContains(t, files, ErrorsFile)
+
+	assert.Regexp(t, `build-service-in-cluster-local\s+OutOfSync\s+Degraded`, string(files["argocd/applications.txt"]))
+	assert.Regexp(t, `components.appstudio.redhat.com\s+v1alpha1\s+v1alpha1`, string(files["crds/versions.txt"]))
+	assert.Regexp(t, `worker-0\s+MemoryPressure\s+True\s+KubeletHasInsufficientMemory`, string(files["nodes/conditions.txt"]))
+	assert.Regexp(t, `tenant\s+compute\s+limits.cpu\s+19500m\s+20`, string(files["resourcequotas/resourcequotas.txt"]))
+	assert.Contains(t, string(files["events/events.txt"]), "FailedScheduling")
+	assert.NotContains(t, string(files["events/events.txt"]), "old-pod")
+}
+
+func TestGatherCollectorTimeout(t *testing.T) {
+
+	g := newTestGatherer()
+	g.Timeout = 10 * time.Millisecond
+	g.Collectors = []Collector{
+		{Name: "hanging", Collect: func(ctx context.Context, _ *Gatherer, add func(string, []byte)) error {
+			add("partial.txt", []byte("collected before the timeout"))
+			<-ctx.Done()
+			return nil
+		}},
+		{Name: "nodes", Collect: collectNodeConditions},
+	}
+
+	files := g.Gather(context.Background())
+	assert.Equal(t, "collected before the timeout", string(files["hanging/partial.txt"]))
+	assert.Contains(t, files, "nodes/conditions.txt")
+	assert.Equal(t, "hanging: context deadline exceeded\n", string(files[ErrorsFile]))
+}
+
+func TestWriteTarball(t *testing.T) {
+
+	g := newTestGatherer()
+	path := filepath.Join(t.TempDir(), SnapshotFile)
+	assert.NoError(t, g.WriteTarball(context.Background(), path))
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	tr := tar.NewReader(gr)
+
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		if err != nil {
+			break
+		}
+		names = append(names, header.Name)
+	}
+	assert.Contains(t, names, "must-gather/argocd/applications.txt")
+	assert.Contains(t, names, "must-gather/controllers/build-service/build-service-controller-manager-1-manager-previous.log")
+}
@@ -0,0 +1,404 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	DEFAULT_USER_POOL_CONFIGMAP_NAME = "e2e-sandbox-user-pool"
+
+	DEFAULT_USER_POOL_PREFIX = "e2e-pool"
+
+	DEFAULT_USER_POOL_LEASE_TTL = 2 * time.Hour
+
+	DEFAULT_USER_POOL_LEASE_TIMEOUT = 15 * time.Minute
+
+	DEFAULT_USER_POOL_POLL_INTERVAL = 10 * time.Second
+)
+
+// ErrLeaseLost is returned when a lease expired and its user was leased again
+var ErrLeaseLost = errors.New("the lease of the sandbox user expired and was taken over")
+
+// PooledUser is the state of a user of the pool, stored in the ConfigMap of the pool
+type PooledUser struct {
+	UserName string `json:"userName"`
+	// Ready is set once the user is provisioned
+	Ready     bool   `json:"ready"`
+	Namespace string `json:"namespace,omitempty"`
+	// Holder holds the user, leased or being provisioned, until LeaseExpires
+	Holder       string    `json:"holder,omitempty"`
+	LeaseExpires time.Time `json:"leaseExpires,omitempty"`
+	// Error is the last provisioning or reset error, the user is only leased when no healthy user is available
+	Error string `json:"error,omitempty"`
+}
+
+func (u *PooledUser) isHeld(now time.Time) bool {
+	return u.Holder != "" && now.Before(u.LeaseExpires)
+}
+
+// UserLease is a user leased from the pool, until Expires unless renewed
+type UserLease struct {
+	*SandboxUserAuthInfo
+	Holder  string
+	Expires time.Time
+}
+
+// PoolHealth reports the state of the users of the pool
+type PoolHealth struct {
+	Size int
+	// Ready users are provisioned and healthy, Leased is the number of them currently leased
+	Ready  int
+	Leased int
+	// Expired is the number of leases which expired without being released
+	Expired int
+	// Unhealthy maps the users which failed to be provisioned or reset to their error
+	Unhealthy map[string]string
+}
+
+func (h PoolHealth) String() string {
+	return fmt.Sprintf("%d users: %d ready (%d leased, %d expired leases), %d unprovisioned, %d unhealthy",
+		h.Size, h.Ready, h.Leased, h.Expired, h.Size-h.Ready-len(h.Unhealthy), len(h.Unhealthy))
+}
+
+// UserPool leases pre-provisioned Dev Sandbox users to the specs, so that they don't sign up a user each.
+// The leases are stored in a ConfigMap updated with optimistic locking, which makes the pool safe to share
+// between the ginkgo processes and the jobs using the same cluster.
+type UserPool struct {
+	// LockClient is the client of the cluster holding the ConfigMap of the pool
+	LockClient kubernetes.Interface
+	Namespace  string
+	Name       string
+
+	// Prefix is the prefix of the user names, the users are named <prefix>-1 to <prefix>-<size>
+	Prefix string
+	Size   int
+	TTL    time.Duration
+	// Holder identifies the leases of this process
+	Holder       string
+	PollInterval time.Duration
+	// LeaseTimeout bounds the wait for a user to be released, it is kept well below the TTL so that the specs
+	// fail fast instead of waiting for the leases of the other holders to expire
+	LeaseTimeout time.Duration
+
+	// Provision signs up the user when needed and returns its auth info, it must be idempotent as it is also called on every lease
+	Provision func(userName string) (*SandboxUserAuthInfo, error)
+	// Reset cleans the namespace of the user when it is released, or when its lease expired before it was released
+	Reset func(*SandboxUserAuthInfo) error
+}
+
+// NewUserPool returns a pool of size users provisioned with the SandboxController, whose ConfigMap is in the given namespace
+func NewUserPool(s *SandboxController, lockClient kubernetes.Interface, namespace string, size int) *UserPool {
+	hostname, _ := os.Hostname()
+	return &UserPool{
+		LockClient:   lockClient,
+		Namespace:    namespace,
+		Name:         DEFAULT_USER_POOL_CONFIGMAP_NAME,
+		Prefix:       DEFAULT_USER_POOL_PREFIX,
+		Size:         size,
+		TTL:          DEFAULT_USER_POOL_LEASE_TTL,
+		Holder:       fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), ginkgo.GinkgoParallelProcess()),
+		PollInterval: DEFAULT_USER_POOL_POLL_INTERVAL,
+		LeaseTimeout: DEFAULT_USER_POOL_LEASE_TIMEOUT,
+		Provision:    s.ReconcileUserCreation,
+		Reset:        func(*SandboxUserAuthInfo) error { return nil },
+	}
+}
+
+func (p *UserPool) userName(i int) string {
+	return fmt.Sprintf("%s-%d", p.Prefix, i+1)
+}
+
+// load returns the ConfigMap of the pool, nil when it doesn't exist yet, and the state of its users
+func (p *UserPool) load(ctx context.Context) (*corev1.ConfigMap, map[string]*PooledUser, error) {
+	cm, err := p.LockClient.CoreV1().ConfigMaps(p.Namespace).Get(ctx, p.Name, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		cm = nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	users := map[string]*PooledUser{}
+	for i := 0; i < p.Size; i++ {
+		users[p.userName(i)] = &PooledUser{UserName: p.userName(i)}
+	}
+	if cm == nil {
+		return nil, users, nil
+	}
+	for name, data := range cm.Data {
+		if _, ok := users[name]; !ok {
+			continue
+		}
+		if err := json.Unmarshal([]byte(data), users[name]); err != nil {
+			return nil, nil, fmt.Errorf("decoding the state of the pooled user %s: %w", name, err)
+		}
+	}
+	return cm, users, nil
+}
+
+// update applies the mutation to the users of the pool and stores them, retrying on conflicts with the other holders
+func (p *UserPool) update(ctx context.Context, mutate func(users map[string]*PooledUser) error) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cm, users, err := p.load(ctx)
+		if err != nil {
+			return err
+		}
+		if err := mutate(users); err != nil {
+			return err
+		}
+
+		data := map[string]string{}
+		for name, user := range users {
+			userData, err := json.Marshal(user)
+			if err != nil {
+				return err
+			}
+			data[name] = string(userData)
+		}
+		if cm == nil {
+			cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: p.Name, Namespace: p.Namespace}, Data: data}
+			_, err = p.LockClient.CoreV1().ConfigMaps(p.Namespace).Create(ctx, cm, metav1.CreateOptions{})
+			if k8sErrors.IsAlreadyExists(err) {
+				// created by another holder in the meantime, retry with its state
+				return k8sErrors.NewConflict(corev1.Resource("configmaps"), p.Name, err)
+			}
+			return err
+		}
+		cm.Data = data
+		_, err = p.LockClient.CoreV1().ConfigMaps(p.Namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// sortedUsers returns the users in the order of their names
+func sortedUsers(users map[string]*PooledUser) []*PooledUser {
+	sorted := make([]*PooledUser, 0, len(users))
+	for _, user := range users {
+		sorted = append(sorted, user)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].UserName < sorted[j].UserName })
+	return sorted
+}
+
+// provisionUser provisions a user claimed by this holder and records the outcome, the user stays held on success
+func (p *UserPool) provisionUser(ctx context.Context, userName string) (*SandboxUserAuthInfo, error) {
+	info, provisionErr := p.Provision(userName)
+	err := p.update(ctx, func(users map[string]*PooledUser) error {
+		user := users[userName]
+		if provisionErr != nil {
+			user.Ready, user.Error, user.Holder = false, provisionErr.Error(), ""
+			return nil
+		}
+		user.Ready, user.Error, user.Namespace = true, "", info.UserNamespace
+		return nil
+	})
+	if provisionErr != nil {
+		return nil, fmt.Errorf("provisioning the pooled user %s: %w", userName, provisionErr)
+	}
+	return info, err
+}
+
+// Prepare provisions the users of the pool which aren't provisioned yet or are unhealthy, resetting the namespaces
+// of the unhealthy ones, skipping the ones another holder is provisioning. It is called once per suite.
+func (p *UserPool) Prepare(ctx context.Context) error {
+	var errs []error
+	for i := 0; i < p.Size; i++ {
+		userName := p.userName(i)
+		claimed, unhealthy := false, false
+		if err := p.update(ctx, func(users map[string]*PooledUser) error {
+			user := users[userName]
+			claimed, unhealthy = !user.Ready && !user.isHeld(time.Now()), user.Error != ""
+			if claimed {
+				user.Holder, user.LeaseExpires = p.Holder, time.Now().Add(p.TTL)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		info, err := p.provisionUser(ctx, userName)
+		if err == nil && unhealthy {
+			// the namespace of a user whose reset failed still holds the leftovers of its last holder
+			if err = p.Reset(info); err != nil {
+				err = fmt.Errorf("resetting the namespace of the pooled user %s: %w", userName, err)
+				p.markUnhealthy(ctx, userName, err)
+			}
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := p.update(ctx, func(users map[string]*PooledUser) error {
+			if users[userName].Holder == p.Holder {
+				users[userName].Holder = ""
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Lease leases a ready user of the pool to this holder, provisioning one when none is ready, and waits for a user
+// to be released when all of them are leased. The unhealthy users are retried last: they are provisioned and reset
+// again, so that a failed reset or provisioning doesn't shrink the pool for the following runs.
+func (p *UserPool) Lease(ctx context.Context) (*UserLease, error) {
+	for {
+		var userName string
+		var provision, reset bool
+		expires := time.Now().Add(p.TTL)
+		if err := p.update(ctx, func(users map[string]*PooledUser) error {
+			userName, provision, reset = "", false, false
+			now := time.Now()
+			for _, available := range []func(*PooledUser) bool{
+				func(user *PooledUser) bool { return user.Ready && user.Error == "" },
+				func(user *PooledUser) bool { return !user.Ready && user.Error == "" },
+				func(user *PooledUser) bool { return user.Error != "" },
+			} {
+				for _, user := range sortedUsers(users) {
+					if available(user) && !user.isHeld(now) {
+						// an expired lease or a failed reset leaves the leftovers of the previous holder
+						userName, provision, reset = user.UserName, !user.Ready, user.Holder != "" || user.Error != ""
+						break
+					}
+				}
+				if userName != "" {
+					break
+				}
+			}
+			if userName != "" {
+				users[userName].Holder, users[userName].LeaseExpires = p.Holder, expires
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+
+		if userName != "" {
+			var info *SandboxUserAuthInfo
+			var err error
+			if provision {
+				info, err = p.provisionUser(ctx, userName)
+			} else {
+				info, err = p.Provision(userName)
+			}
+			if err == nil && reset {
+				ginkgo.GinkgoWriter.Printf("resetting the namespace of the pooled user %s left by its previous holder\n", userName)
+				err = p.Reset(info)
+				if err != nil {
+					err = fmt.Errorf("resetting the namespace of the pooled user %s: %w", userName, err)
+				}
+			}
+			if err != nil {
+				if !provision || reset {
+					p.markUnhealthy(ctx, userName, err)
+				}
+				return nil, err
+			}
+			return &UserLease{SandboxUserAuthInfo: info, Holder: p.Holder, Expires: expires}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no user of the pool of %d users was released in time: %w", p.Size, ctx.Err())
+		case <-time.After(p.PollInterval):
+		}
+	}
+}
+
+func (p *UserPool) markUnhealthy(ctx context.Context, userName string, cause error) {
+	if err := p.update(ctx, func(users map[string]*PooledUser) error {
+		user := users[userName]
+		user.Ready, user.Error, user.Holder = false, cause.Error(), ""
+		return nil
+	}); err != nil {
+		ginkgo.GinkgoWriter.Printf("failed to mark the pooled user %s as unhealthy: %v\n", userName, err)
+	}
+}
+
+// holding applies the mutation to the user of the lease, failing with ErrLeaseLost when it is held by another holder
+func (p *UserPool) holding(ctx context.Context, lease *UserLease, mutate func(user *PooledUser)) error {
+	return p.update(ctx, func(users map[string]*PooledUser) error {
+		user, ok := users[lease.UserName]
+		if !ok || user.Holder != lease.Holder || !user.LeaseExpires.Equal(lease.Expires) {
+			return ErrLeaseLost
+		}
+		mutate(user)
+		return nil
+	})
+}
+
+// Renew extends the lease by the TTL of the pool
+func (p *UserPool) Renew(ctx context.Context, lease *UserLease) error {
+	expires := time.Now().Add(p.TTL)
+	if err := p.holding(ctx, lease, func(user *PooledUser) {
+		user.LeaseExpires = expires
+	}); err != nil {
+		return err
+	}
+	lease.Expires = expires
+	return nil
+}
+
+// Release resets the namespace of the leased user and returns it to the pool. A user which fails to be reset is
+// marked unhealthy, it is only leased again when no healthy user is available, after being reset again.
+func (p *UserPool) Release(ctx context.Context, lease *UserLease) error {
+	// a lost user is used by another holder, it mustn't be reset
+	if err := p.holding(ctx, lease, func(*PooledUser) {}); err != nil {
+		return err
+	}
+	resetErr := p.Reset(lease.SandboxUserAuthInfo)
+	if err := p.holding(ctx, lease, func(user *PooledUser) {
+		user.Holder, user.LeaseExpires = "", time.Time{}
+		if resetErr != nil {
+			user.Ready, user.Error = false, resetErr.Error()
+		}
+	}); err != nil {
+		return err
+	}
+	if resetErr != nil {
+		return fmt.Errorf("resetting the namespace of the pooled user %s: %w", lease.UserName, resetErr)
+	}
+	return nil
+}
+
+// Health returns the state of the users of the pool
+func (p *UserPool) Health(ctx context.Context) (*PoolHealth, error) {
+	_, users, err := p.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	health := &PoolHealth{Size: p.Size, Unhealthy: map[string]string{}}
+	now := time.Now()
+	for _, user := range users {
+		switch {
+		case user.Error != "":
+			health.Unhealthy[user.UserName] = user.Error
+		case user.Ready:
+			health.Ready++
+			if user.isHeld(now) {
+				health.Leased++
+			} else if user.Holder != "" {
+				health.Expired++
+			}
+		}
+	}
+	return health, nil
+}
@@ -0,0 +1,199 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// fakeSandbox records the users it signs up and the namespaces it resets
+type fakeSandbox struct {
+	mu       sync.Mutex
+	signedUp map[string]bool
+	resets   []string
+	resetErr error
+}
+
+func (f *fakeSandbox) provision(userName string) (*SandboxUserAuthInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.signedUp[userName] = true
+	return &SandboxUserAuthInfo{UserName: userName, UserNamespace: userName + "-tenant", UserToken: "token-" + userName}, nil
+}
+
+func (f *fakeSandbox) reset(info *SandboxUserAuthInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resets = append(f.resets, info.UserNamespace)
+	return f.resetErr
+}
+
+func newTestPool(client kubernetes.Interface, sandbox *fakeSandbox, holder string, size int) *UserPool {
+	pool := NewUserPool(&SandboxController{}, client, "e2e-tests", size)
+	pool.Holder = holder
+	pool.PollInterval = 10 * time.Millisecond
+	pool.Provision = sandbox.provision
+	pool.Reset = sandbox.reset
+	return pool
+}
+
+func TestUserPoolLeaseAndRelease(t *testing.T) {
+
+	client := fake.NewSimpleClientset()
+	sandbox := &fakeSandbox{signedUp: map[string]bool{}}
+	pool := newTestPool(client, sandbox, "proc-1", 2)
+	other := newTestPool(client, sandbox, "proc-2", 2)
+
+	first, err := pool.Lease(context.Background())
+	assert.NoError(t, err)
+	second, err := other.Lease(context.Background())
+	assert.NoError(t, err)
+	if first == nil || second == nil {
+		return
+	}
+	assert.Equal(t, "e2e-pool-1", first.UserName)
+	assert.Equal(t, "e2e-pool-1-tenant", first.UserNamespace)
+	assert.Equal(t, "e2e-pool-2", second.UserName)
+
+	// all the users are leased
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = pool.Lease(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	health, err := pool.Health(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &PoolHealth{Size: 2, Ready: 2, Leased: 2, Unhealthy: map[string]string{}}, health)
+
+	// a released user is reset and leased again, without being signed up again
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		assert.NoError(t, other.Release(context.Background(), second))
+	}()
+	again, err := pool.Lease(context.Background())
+	assert.NoError(t, err)
+	if assert.NotNil(t, again) {
+		assert.Equal(t, "e2e-pool-2", again.UserName)
+	}
+	assert.Equal(t, []string{"e2e-pool-2-tenant"}, sandbox.resets)
+	assert.Len(t, sandbox.signedUp, 2)
+}
+
+func TestUserPoolExpiredLease(t *testing.T) {
+
+	client := fake.NewSimpleClientset()
+	sandbox := &fakeSandbox{signedUp: map[string]bool{}}
+	pool := newTestPool(client, sandbox, "proc-1", 1)
+	pool.TTL = 20 * time.Millisecond
+	other := newTestPool(client, sandbox, "proc-2", 1)
+
+	lease, err := pool.Lease(context.Background())
+	assert.NoError(t, err)
+	time.Sleep(30 * time.Millisecond)
+
+	health, err := pool.Health(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, health.Expired)
+
+	// the leftovers of the expired lease are reset before the user is leased again
+	taken, err := other.Lease(context.Background())
+	assert.NoError(t, err)
+	if assert.NotNil(t, taken) {
+		assert.Equal(t, "e2e-pool-1", taken.UserName)
+	}
+	assert.Equal(t, []string{"e2e-pool-1-tenant"}, sandbox.resets)
+
+	// the namespace of the new holder isn't reset by the previous one
+	assert.ErrorIs(t, pool.Release(context.Background(), lease), ErrLeaseLost)
+	assert.ErrorIs(t, pool.Renew(context.Background(), lease), ErrLeaseLost)
+	assert.Len(t, sandbox.resets, 1)
+	assert.NoError(t, other.Renew(context.Background(), taken))
+}
+
+func TestUserPoolUnhealthyUser(t *testing.T) {
+
+	client := fake.NewSimpleClientset()
+	sandbox := &fakeSandbox{signedUp: map[string]bool{}, resetErr: errors.New("failed to delete the Components")}
+	pool := newTestPool(client, sandbox, "proc-1", 2)
+
+	lease, err := pool.Lease(context.Background())
+	assert.NoError(t, err)
+	assert.Error(t, pool.Release(context.Background(), lease))
+
+	health, err := pool.Health(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"e2e-pool-1": "failed to delete the Components"}, health.Unhealthy)
+	assert.Equal(t, "2 users: 0 ready (0 leased, 0 expired leases), 1 unprovisioned, 1 unhealthy", health.String())
+
+	// the healthy users are leased first
+	lease, err = pool.Lease(context.Background())
+	assert.NoError(t, err)
+	if assert.NotNil(t, lease) {
+		assert.Equal(t, "e2e-pool-2", lease.UserName)
+	}
+
+	// the unhealthy user is retried when no healthy user is available, it stays unhealthy while its reset fails
+	_, err = pool.Lease(context.Background())
+	assert.ErrorContains(t, err, "failed to delete the Components")
+	assert.Equal(t, []string{"e2e-pool-1-tenant", "e2e-pool-1-tenant"}, sandbox.resets)
+
+	sandbox.resetErr = nil
+	retried, err := pool.Lease(context.Background())
+	assert.NoError(t, err)
+	if assert.NotNil(t, retried) {
+		assert.Equal(t, "e2e-pool-1", retried.UserName)
+	}
+	assert.Len(t, sandbox.resets, 3)
+	health, err = pool.Health(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &PoolHealth{Size: 2, Ready: 2, Leased: 2, Unhealthy: map[string]string{}}, health)
+}
+
+func TestUserPoolPrepareUnhealthyUser(t *testing.T) {
+
+	client := fake.NewSimpleClientset()
+	sandbox := &fakeSandbox{signedUp: map[string]bool{}, resetErr: errors.New("failed to delete the Components")}
+	pool := newTestPool(client, sandbox, "proc-1", 2)
+
+	lease, err := pool.Lease(context.Background())
+	assert.NoError(t, err)
+	assert.Error(t, pool.Release(context.Background(), lease))
+
+	// preparing the pool provisions the unhealthy user again and resets its namespace
+	sandbox.resetErr = nil
+	assert.NoError(t, pool.Prepare(context.Background()))
+	assert.Equal(t, []string{"e2e-pool-1-tenant", "e2e-pool-1-tenant"}, sandbox.resets)
+	health, err := pool.Health(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &PoolHealth{Size: 2, Ready: 2, Unhealthy: map[string]string{}}, health)
+}
+
+func TestUserPoolPrepare(t *testing.T) {
+
+	client := fake.NewSimpleClientset()
+	sandbox := &fakeSandbox{signedUp: map[string]bool{}}
+	pool := newTestPool(client, sandbox, "proc-1", 3)
+
+	assert.NoError(t, pool.Prepare(context.Background()))
+	assert.Len(t, sandbox.signedUp, 3)
+	health, err := pool.Health(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &PoolHealth{Size: 3, Ready: 3, Unhealthy: map[string]string{}}, health)
+
+	failing := newTestPool(client, sandbox, "proc-2", 4)
+	failing.Provision = func(userName string) (*SandboxUserAuthInfo, error) {
+		return nil, fmt.Errorf("signup of %s rate limited", userName)
+	}
+	assert.ErrorContains(t, failing.Prepare(context.Background()), "signup of e2e-pool-4 rate limited")
+	health, err = failing.Health(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, health.Ready)
+	assert.Contains(t, health.Unhealthy, "e2e-pool-4")
+}
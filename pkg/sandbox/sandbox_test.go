@@ -0,0 +1,156 @@
+package sandbox_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	toolchainApi "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/sandbox"
+	"github.com/konflux-ci/e2e-tests/pkg/sandbox/sandboxtest"
+	"github.com/stretchr/testify/assert"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newTestSandboxController returns a SandboxController using the Keycloak and toolchain stand-ins
+func newTestSandboxController(t *testing.T) (*sandbox.SandboxController, *sandboxtest.Keycloak, crclient.Client) {
+	keycloak := sandboxtest.NewKeycloak("keycloak-admin-password")
+	t.Cleanup(keycloak.Close)
+	toolchainClient := sandboxtest.NewToolchainClient()
+	s, err := sandboxtest.NewSandboxController(keycloak, toolchainClient)
+	assert.NoError(t, err)
+	return s, keycloak, toolchainClient
+}
+
+func getUserSignup(t *testing.T, c crclient.Client, name string) *toolchainApi.UserSignup {
+	userSignup := &toolchainApi.UserSignup{}
+	assert.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: sandbox.DEFAULT_TOOLCHAIN_NAMESPACE, Name: name}, userSignup))
+	return userSignup
+}
+
+func spaceExists(c crclient.Client, name string) bool {
+	err := c.Get(context.Background(), types.NamespacedName{Namespace: sandbox.DEFAULT_TOOLCHAIN_NAMESPACE, Name: name}, &toolchainApi.Space{})
+	return !k8sErrors.IsNotFound(err)
+}
+
+func TestReconcileUserCreation(t *testing.T) {
+
+	s, keycloak, toolchainClient := newTestSandboxController(t)
+	kubeconfigPath := filepath.Join(t.TempDir(), "user.kubeconfig")
+	t.Setenv(constants.USER_KUBE_CONFIG_PATH_ENV, kubeconfigPath)
+
+	info, err := s.ReconcileUserCreation("e2e-user")
+	assert.NoError(t, err)
+	assert.Equal(t, &sandbox.SandboxUserAuthInfo{
+		UserName:       "e2e-user",
+		UserNamespace:  "e2e-user-tenant",
+		KubeconfigPath: kubeconfigPath,
+		ProxyUrl:       "https://" + sandboxtest.ToolchainAPIHost,
+		UserToken:      sandboxtest.UserToken("e2e-user"),
+	}, info)
+	assert.Contains(t, keycloak.Users(sandbox.DEFAULT_KEYCLOAK_TESTING_REALM), "e2e-user")
+
+	userSignup := getUserSignup(t, toolchainClient, "e2e-user")
+	assert.Equal(t, []toolchainApi.UserSignupState{toolchainApi.UserSignupStateApproved}, userSignup.Spec.States)
+	assert.True(t, spaceExists(toolchainClient, "e2e-user"))
+
+	kubeconfig, err := clientcmd.LoadFromFile(kubeconfigPath)
+	assert.NoError(t, err)
+	if assert.NotNil(t, kubeconfig) {
+		assert.Equal(t, "e2e-user-tenant", kubeconfig.Contexts[kubeconfig.CurrentContext].Namespace)
+		assert.Equal(t, sandboxtest.UserToken("e2e-user"), kubeconfig.AuthInfos[kubeconfig.Contexts[kubeconfig.CurrentContext].AuthInfo].Token)
+	}
+
+	// the creation is idempotent, the existing UserSignup and Keycloak user are reused
+	again, err := s.ReconcileUserCreation("e2e-user")
+	assert.NoError(t, err)
+	assert.Equal(t, info, again)
+	assert.Len(t, keycloak.Users(sandbox.DEFAULT_KEYCLOAK_TESTING_REALM), 1)
+
+	deleted, err := s.DeleteUserSignup("e2e-user")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+}
+
+func TestReconcileUserCreationWithoutKeycloakAdmin(t *testing.T) {
+
+	s, keycloak, _ := newTestSandboxController(t)
+	t.Setenv(constants.USER_KUBE_CONFIG_PATH_ENV, filepath.Join(t.TempDir(), "user.kubeconfig"))
+	keycloak.AdminPassword = "rotated-password"
+
+	_, err := s.ReconcileUserCreation("e2e-user")
+	assert.ErrorContains(t, err, "failed to get keycloak token, userName: admin, statusCode: 401")
+}
+
+func TestKeycloakUsers(t *testing.T) {
+
+	s, keycloak, _ := newTestSandboxController(t)
+	s.KeycloakUrl = keycloak.URL
+	realm := sandbox.DEFAULT_KEYCLOAK_TESTING_REALM
+
+	adminToken, err := s.GetKeycloakToken(sandbox.DEFAULT_KEYCLOAK_ADMIN_CLIENT_ID, sandbox.DEFAULT_KEYCLOAK_ADMIN_USERNAME, keycloak.AdminPassword, sandbox.DEFAULT_KEYCLOAK_MASTER_REALM)
+	assert.NoError(t, err)
+	assert.Equal(t, sandboxtest.AdminToken, adminToken)
+
+	assert.False(t, s.KeycloakUserExists(realm, adminToken, "e2e-user"))
+	user, err := s.RegisterKeycloakUser("e2e-user", adminToken, realm)
+	assert.NoError(t, err)
+	assert.Equal(t, "e2e-user@test.com", user.Email)
+	assert.True(t, s.KeycloakUserExists(realm, adminToken, "e2e-user"))
+	assert.False(t, s.KeycloakUserExists(sandbox.DEFAULT_KEYCLOAK_MASTER_REALM, adminToken, "e2e-user"))
+
+	_, err = s.RegisterKeycloakUser("e2e-user", adminToken, realm)
+	assert.ErrorContains(t, err, "Status code 409")
+	_, err = s.RegisterKeycloakUser("other-user", "invalid-token", realm)
+	assert.ErrorContains(t, err, "Status code 401")
+	assert.False(t, s.KeycloakUserExists(realm, "invalid-token", "e2e-user"))
+
+	// the password of the users created by the SandboxController is their user name
+	userToken, err := s.GetKeycloakToken(sandbox.DEFAULT_KEYCLOAK_TEST_CLIENT_ID, "e2e-user", "e2e-user", realm)
+	assert.NoError(t, err)
+	assert.Equal(t, sandboxtest.UserToken("e2e-user"), userToken)
+	_, err = s.GetKeycloakToken(sandbox.DEFAULT_KEYCLOAK_TEST_CLIENT_ID, "e2e-user", "wrong-password", realm)
+	assert.Error(t, err)
+
+	stageToken, err := s.GetKeycloakTokenStage("e2e-user", keycloak.URL+"/auth/realms/"+realm+"/protocol/openid-connect/token", sandboxtest.RefreshToken("e2e-user"))
+	assert.NoError(t, err)
+	assert.Equal(t, sandboxtest.UserToken("e2e-user"), stageToken)
+	_, err = s.GetKeycloakTokenStage("e2e-user", keycloak.URL+"/auth/realms/"+realm+"/protocol/openid-connect/token", "expired-token")
+	assert.Error(t, err)
+}
+
+func TestRegisterBannedSandboxUser(t *testing.T) {
+
+	s, _, toolchainClient := newTestSandboxController(t)
+
+	compliantUsername, err := s.RegisterBannedSandboxUser("Banned_User")
+	assert.NoError(t, err)
+	assert.Equal(t, "banned-user", compliantUsername)
+
+	userSignup := getUserSignup(t, toolchainClient, "Banned_User")
+	assert.Equal(t, []toolchainApi.UserSignupState{toolchainApi.UserSignupStateBanned}, userSignup.Spec.States)
+	if assert.Len(t, userSignup.Status.Conditions, 1) {
+		assert.Equal(t, toolchainApi.UserSignupUserBannedReason, userSignup.Status.Conditions[0].Reason)
+	}
+	assert.False(t, spaceExists(toolchainClient, compliantUsername), "a banned user has no Space")
+}
+
+func TestRegisterDeactivatedSandboxUser(t *testing.T) {
+
+	s, _, toolchainClient := newTestSandboxController(t)
+
+	compliantUsername, err := s.RegisterDeactivatedSandboxUser("deactivated-user")
+	assert.NoError(t, err)
+	assert.Equal(t, "deactivated-user", compliantUsername)
+
+	userSignup := getUserSignup(t, toolchainClient, "deactivated-user")
+	assert.Equal(t, []toolchainApi.UserSignupState{toolchainApi.UserSignupStateDeactivated}, userSignup.Spec.States)
+	if assert.Len(t, userSignup.Status.Conditions, 1) {
+		assert.Equal(t, toolchainApi.UserSignupUserDeactivatedReason, userSignup.Status.Conditions[0].Reason)
+	}
+	assert.False(t, spaceExists(toolchainClient, compliantUsername), "the Space of a deactivated user is removed")
+}
@@ -0,0 +1,153 @@
+// Package sandboxtest provides stand-ins of Keycloak and of the toolchain host operator, to test the SandboxController
+// without a toolchain deployment.
+package sandboxtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/konflux-ci/e2e-tests/pkg/sandbox"
+)
+
+// AdminToken is the access token of the Keycloak admin
+const AdminToken = "keycloak-admin-token"
+
+const refreshTokenPrefix = "keycloak-offline-token-"
+
+// UserToken returns the access token the Keycloak stand-in issues to a user
+func UserToken(userName string) string {
+	return "keycloak-token-" + userName
+}
+
+// RefreshToken returns the offline token of a user, exchanged for its access token by the refresh_token grant as on stage
+func RefreshToken(userName string) string {
+	return refreshTokenPrefix + userName
+}
+
+// Keycloak is an httptest server implementing the token and admin users endpoints of Keycloak used by the SandboxController
+type Keycloak struct {
+	*httptest.Server
+	AdminPassword string
+
+	mu sync.Mutex
+	// users are the users of the realms, keyed by realm and user name
+	users map[string]map[string]sandbox.KeycloakUser
+}
+
+// NewKeycloak starts a Keycloak stand-in served over TLS, as the Keycloak route is, whose admin has the given password
+func NewKeycloak(adminPassword string) *Keycloak {
+	k := &Keycloak{AdminPassword: adminPassword, users: map[string]map[string]sandbox.KeycloakUser{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /auth/realms/{realm}/protocol/openid-connect/token", k.token)
+	mux.HandleFunc("GET /auth/admin/realms/{realm}/users", k.admin(k.listUsers))
+	mux.HandleFunc("POST /auth/admin/realms/{realm}/users", k.admin(k.createUser))
+	k.Server = httptest.NewTLSServer(mux)
+	return k
+}
+
+// Host returns the host of the stand-in, as the host of the Keycloak route
+func (k *Keycloak) Host() string {
+	return strings.TrimPrefix(k.URL, "https://")
+}
+
+// Users returns the users of the realm
+func (k *Keycloak) Users(realm string) map[string]sandbox.KeycloakUser {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	users := map[string]sandbox.KeycloakUser{}
+	for name, user := range k.users[realm] {
+		users[name] = user
+	}
+	return users
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (k *Keycloak) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	realm := r.PathValue("realm")
+
+	var userName, accessToken string
+	switch r.Form.Get("grant_type") {
+	case "password":
+		var password string
+		userName, password = r.Form.Get("username"), r.Form.Get("password")
+		if realm == sandbox.DEFAULT_KEYCLOAK_MASTER_REALM && r.Form.Get("client_id") == sandbox.DEFAULT_KEYCLOAK_ADMIN_CLIENT_ID &&
+			userName == sandbox.DEFAULT_KEYCLOAK_ADMIN_USERNAME && password == k.AdminPassword {
+			accessToken = AdminToken
+			break
+		}
+		k.mu.Lock()
+		user, ok := k.users[realm][userName]
+		k.mu.Unlock()
+		if ok && len(user.Credentials) > 0 && user.Credentials[0].Value == password {
+			accessToken = UserToken(userName)
+		}
+	case "refresh_token":
+		if name, ok := strings.CutPrefix(r.Form.Get("refresh_token"), refreshTokenPrefix); ok && name != "" {
+			userName, accessToken = name, UserToken(name)
+		}
+	}
+
+	if accessToken == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	writeJSON(w, http.StatusOK, sandbox.KeycloakAuth{AccessToken: accessToken, RefreshToken: RefreshToken(userName)})
+}
+
+// admin rejects the requests without the access token of the admin
+func (k *Keycloak) admin(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+AdminToken {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "HTTP 401 Unauthorized"})
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func (k *Keycloak) listUsers(w http.ResponseWriter, r *http.Request) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	// Keycloak answers with an empty list when no user matches
+	users := []sandbox.KeycloakUser{}
+	if user, ok := k.users[r.PathValue("realm")][r.URL.Query().Get("username")]; ok {
+		users = append(users, user)
+	}
+	writeJSON(w, http.StatusOK, users)
+}
+
+func (k *Keycloak) createUser(w http.ResponseWriter, r *http.Request) {
+	var user sandbox.KeycloakUser
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil || user.Username == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"errorMessage": fmt.Sprintf("invalid user: %v", err)})
+		return
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	realm := r.PathValue("realm")
+	if _, ok := k.users[realm][user.Username]; ok {
+		writeJSON(w, http.StatusConflict, map[string]string{"errorMessage": "User exists with same username"})
+		return
+	}
+	if k.users[realm] == nil {
+		k.users[realm] = map[string]sandbox.KeycloakUser{}
+	}
+	k.users[realm][user.Username] = user
+	w.WriteHeader(http.StatusCreated)
+}
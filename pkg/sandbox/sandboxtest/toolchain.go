@@ -0,0 +1,148 @@
+package sandboxtest
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	toolchainApi "github.com/codeready-toolchain/api/api/v1alpha1"
+	toolchainStates "github.com/codeready-toolchain/toolchain-common/pkg/states"
+	"github.com/konflux-ci/e2e-tests/pkg/sandbox"
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	crfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// ToolchainAPIHost is the host of the route of the toolchain API in the toolchain stand-in
+const ToolchainAPIHost = "api-toolchain-host-operator.apps.example.com"
+
+var (
+	scheme = runtime.NewScheme()
+
+	nonCompliantCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(toolchainApi.AddToScheme(scheme))
+	utilruntime.Must(routev1.AddToScheme(scheme))
+}
+
+// CompliantUsername returns the DNS-1123 compliant user name the toolchain stand-in assigns to a UserSignup
+func CompliantUsername(userName string) string {
+	return strings.Trim(nonCompliantCharacters.ReplaceAllString(strings.ToLower(userName), "-"), "-")
+}
+
+// NewToolchainClient returns a fake client reconciling the UserSignups like the host operator does: the approved ones
+// are completed and get a Space with a provisioned <user>-tenant namespace, the banned and deactivated ones are
+// completed with the Banned or Deactivated reason and have no Space.
+func NewToolchainClient(objs ...crclient.Object) crclient.Client {
+	return crfake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c crclient.WithWatch, obj crclient.Object, opts ...crclient.CreateOption) error {
+				if err := c.Create(ctx, obj, opts...); err != nil {
+					return err
+				}
+				if userSignup, ok := obj.(*toolchainApi.UserSignup); ok {
+					return reconcileUserSignup(ctx, c, userSignup)
+				}
+				return nil
+			},
+			Update: func(ctx context.Context, c crclient.WithWatch, obj crclient.Object, opts ...crclient.UpdateOption) error {
+				if err := c.Update(ctx, obj, opts...); err != nil {
+					return err
+				}
+				if userSignup, ok := obj.(*toolchainApi.UserSignup); ok {
+					return reconcileUserSignup(ctx, c, userSignup)
+				}
+				return nil
+			},
+		}).
+		Build()
+}
+
+func reconcileUserSignup(ctx context.Context, c crclient.Client, userSignup *toolchainApi.UserSignup) error {
+	compliantUsername := CompliantUsername(userSignup.Spec.Username)
+	space := &toolchainApi.Space{ObjectMeta: metav1.ObjectMeta{Name: compliantUsername, Namespace: userSignup.Namespace}}
+
+	reason := ""
+	switch {
+	case toolchainStates.Deactivated(userSignup):
+		reason = toolchainApi.UserSignupUserDeactivatedReason
+	case hasState(userSignup, toolchainApi.UserSignupStateBanned):
+		reason = toolchainApi.UserSignupUserBannedReason
+	case toolchainStates.Approved(userSignup):
+		space.Status = toolchainApi.SpaceStatus{
+			ProvisionedNamespaces: []toolchainApi.SpaceNamespace{{Name: compliantUsername + "-tenant", Type: "default"}},
+			Conditions: []toolchainApi.Condition{{
+				Type:   toolchainApi.ConditionReady,
+				Status: corev1.ConditionTrue,
+				Reason: toolchainApi.SpaceProvisionedReason,
+			}},
+		}
+		if err := c.Create(ctx, space); err != nil && !k8sErrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	if reason != "" {
+		if err := c.Delete(ctx, space); err != nil && !k8sErrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	userSignup.Status.CompliantUsername = compliantUsername
+	userSignup.Status.Conditions = []toolchainApi.Condition{{
+		Type:   toolchainApi.UserSignupComplete,
+		Status: corev1.ConditionTrue,
+		Reason: reason,
+	}}
+	return c.Update(ctx, userSignup)
+}
+
+func hasState(userSignup *toolchainApi.UserSignup, state toolchainApi.UserSignupState) bool {
+	for _, s := range userSignup.Spec.States {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSandboxController returns a SandboxController using the Keycloak and toolchain stand-ins, with the routes of
+// the toolchain API and of Keycloak, the Keycloak StatefulSet and the Keycloak admin secret it looks up
+func NewSandboxController(keycloak *Keycloak, toolchainClient crclient.Client) (*sandbox.SandboxController, error) {
+	for _, route := range []*routev1.Route{
+		{ObjectMeta: metav1.ObjectMeta{Name: sandbox.DEFAULT_TOOLCHAIN_INSTANCE_NAME, Namespace: sandbox.DEFAULT_TOOLCHAIN_NAMESPACE}, Spec: routev1.RouteSpec{Host: ToolchainAPIHost}},
+		{ObjectMeta: metav1.ObjectMeta{Name: sandbox.DEFAULT_KEYCLOAK_INSTANCE_NAME, Namespace: sandbox.DEFAULT_KEYCLOAK_NAMESPACE}, Spec: routev1.RouteSpec{Host: keycloak.Host()}},
+	} {
+		if err := toolchainClient.Create(context.Background(), route); err != nil {
+			return nil, err
+		}
+	}
+
+	replicas := int32(1)
+	kubeClient := fake.NewSimpleClientset(
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: sandbox.DEFAULT_KEYCLOAK_INSTANCE_NAME, Namespace: sandbox.DEFAULT_KEYCLOAK_NAMESPACE},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: replicas},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: sandbox.DEFAULT_KEYCLOAK_ADMIN_SECRET, Namespace: sandbox.DEFAULT_KEYCLOAK_NAMESPACE},
+			Data:       map[string][]byte{sandbox.SECRET_KEY: []byte(keycloak.AdminPassword)},
+		},
+	)
+
+	return sandbox.NewDevSandboxController(kubeClient, toolchainClient)
+}
@@ -5,7 +5,8 @@ import (
 	"go/parser"
 	"go/token"
 	"os"
-
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -19,7 +20,12 @@ func ExtractFrameworkDescribeNode(filename string) (TestSpecNode, error) {
 	if err != nil {
 		return TestSpecNode{}, err
 	}
-	return getFrameworkDescribeNode(ispr), nil
+	consts, err := packageStringConsts(filepath.Dir(filename))
+	if err != nil {
+		klog.Errorf("Failed to parse the package of %s", filename)
+		return TestSpecNode{}, err
+	}
+	return getFrameworkDescribeNode(ispr, consts), nil
 }
 
 // parseGinkgoAst creates a new AST inspector based on
@@ -47,12 +53,14 @@ func parseGinkgoAst(filename string) (inspector.Inspector, error) {
 // getFrameworkDescribeNode will use the AST inspector to search
 // for the framework describe decorator function within the test file
 // so that we can generate a complete outline
-func getFrameworkDescribeNode(isp inspector.Inspector) TestSpecNode {
+func getFrameworkDescribeNode(isp inspector.Inspector, consts map[string]string) TestSpecNode {
 
 	var gnode TestSpecNode
 	isp.Preorder([]ast.Node{&ast.CallExpr{}}, func(n ast.Node) {
-		g := findFrameworkDescribeAstNode(n.(*ast.CallExpr))
-		if g.Name != "" {
+		g := findFrameworkDescribeAstNode(n.(*ast.CallExpr), consts)
+		// the framework describe is the first one found, later calls
+		// can be helpers named alike, i.e. ec2Client.DescribeInstances
+		if g.Name != "" && gnode.Name == "" {
 			gnode = g
 		}
 	})
@@ -62,8 +70,9 @@ func getFrameworkDescribeNode(isp inspector.Inspector) TestSpecNode {
 
 // findFrameworkDescribeAstNode will examine the call expression
 // to determine if it is the framework describe decorator
-// and generate a TestSpecNode for it
-func findFrameworkDescribeAstNode(ce *ast.CallExpr) TestSpecNode {
+// and generate a TestSpecNode for it. Its labels referencing
+// constants are resolved with the string constants of the package.
+func findFrameworkDescribeAstNode(ce *ast.CallExpr, consts map[string]string) TestSpecNode {
 
 	var funcname string
 	var n = TestSpecNode{}
@@ -80,8 +89,9 @@ func findFrameworkDescribeAstNode(ce *ast.CallExpr) TestSpecNode {
 		if !ok {
 			// some of our tests don't provide a string to this function
 			// so follow ginkgo outline and set it to `undefined`
-			n.Text = "undefined"
-			n.Labels = extractFrameworkDescribeLabels(ce)
+			n.Text = undefinedText
+			n.Labels = extractFrameworkDescribeLabels(ce, consts)
+			n.Decorators = extractDecorators(ce.Args)
 			return n
 		}
 		switch text.Kind {
@@ -96,7 +106,8 @@ func findFrameworkDescribeAstNode(ce *ast.CallExpr) TestSpecNode {
 		default:
 			n.Text = text.Value
 		}
-		n.Labels = extractFrameworkDescribeLabels(ce)
+		n.Labels = extractFrameworkDescribeLabels(ce, consts)
+		n.Decorators = extractDecorators(ce.Args[1:])
 
 	}
 
@@ -105,22 +116,19 @@ func findFrameworkDescribeAstNode(ce *ast.CallExpr) TestSpecNode {
 }
 
 // extractFrameworkDescribeLables iterates through the Call Expression
-// to determine if it is a Ginkgo Label
-func extractFrameworkDescribeLabels(ce *ast.CallExpr) []string {
+// to extract the values of its Ginkgo Labels, qualified or not
+func extractFrameworkDescribeLabels(ce *ast.CallExpr, consts map[string]string) []string {
 
 	labels := []string{}
 
 	for _, arg := range ce.Args {
-		switch expr := arg.(type) {
-		case *ast.CallExpr:
-			id, ok := expr.Fun.(*ast.Ident)
-			if !ok {
-				// to skip over cases where the expr.Fun. is actually *ast.SelectorExpr
-				continue
-			}
-			if id.Name == "Label" {
-				ls := extractLabels(expr)
-				labels = append(labels, ls...)
+		expr, ok := arg.(*ast.CallExpr)
+		if !ok || callExprName(expr) != "Label" {
+			continue
+		}
+		for _, l := range expr.Args {
+			if label, ok := stringValue(l, consts); ok {
+				labels = append(labels, label)
 			}
 		}
 	}
@@ -128,24 +136,231 @@ func extractFrameworkDescribeLabels(ce *ast.CallExpr) []string {
 
 }
 
-// extracLabels will extract the string values from the
-// Ginkgo Label expression
-func extractLabels(ce *ast.CallExpr) []string {
+// ginkgoSpecNodes are the Ginkgo container, subject and setup/teardown nodes
+// which are graphed into the outline
+var ginkgoSpecNodes = []string{
+	"Describe", "FDescribe", "PDescribe", "XDescribe",
+	"Context", "FContext", "PContext", "XContext",
+	"When", "FWhen", "PWhen", "XWhen",
+	"DescribeTable", "FDescribeTable", "PDescribeTable", "XDescribeTable",
+	"It", "FIt", "PIt", "XIt",
+	"Specify", "FSpecify", "PSpecify", "XSpecify",
+	"Entry", "FEntry", "PEntry", "XEntry",
+	"By",
+	"BeforeAll", "BeforeEach", "JustBeforeEach", "JustAfterEach", "AfterEach", "AfterAll",
+}
 
-	out := []string{}
-	for _, arg := range ce.Args {
+// ginkgoDecorators are the Ginkgo decorators which are not a function call
+// and are kept in the outline
+var ginkgoDecorators = []string{"Ordered", "Serial", "Pending", "Focus", "ContinueOnFailure", "OncePerOrdered"}
+
+// undefinedText is the text of the nodes whose text isn't a string literal or constant, as in ginkgo outline
+const undefinedText = "undefined"
+
+// ExtractGinkgoOutline will walk the AST of the Ginkgo test file and graph
+// its Ginkgo nodes, including tables, decorators and setup/teardown nodes,
+// into a TestOutline. The framework describe decorator function is not
+// part of the returned outline, see ExtractFrameworkDescribeNode.
+func ExtractGinkgoOutline(filename string) (TestOutline, error) {
+
+	fset := token.NewFileSet()
+	parsedSrc, err := parser.ParseFile(fset, filename, nil, 0)
+	if err != nil {
+		klog.Errorf("Failed to parse file to inspect %s", filename)
+		return nil, err
+	}
+	consts, err := packageStringConsts(filepath.Dir(filename))
+	if err != nil {
+		klog.Errorf("Failed to parse the package of %s", filename)
+		return nil, err
+	}
+
+	return extractGinkgoNodes(parsedSrc, consts), nil
+}
+
+// extractGinkgoNodes returns the Ginkgo nodes found within the AST node,
+// the nodes nested in the body of a Ginkgo node are graphed as its children
+func extractGinkgoNodes(root ast.Node, consts map[string]string) TestOutline {
+
+	nodes := TestOutline{}
+	ast.Inspect(root, func(n ast.Node) bool {
+		ce, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		node, ok := ginkgoNodeFromCallExpr(ce, consts)
+		if !ok {
+			return true
+		}
+		for _, arg := range ce.Args {
+			node.Nodes = append(node.Nodes, extractGinkgoNodes(arg, consts)...)
+		}
+		nodes = append(nodes, node)
+		return false
+	})
+
+	return nodes
+}
+
+// ginkgoNodeFromCallExpr will examine the call expression to determine
+// if it is a Ginkgo node and generate a TestSpecNode for it
+func ginkgoNodeFromCallExpr(ce *ast.CallExpr, consts map[string]string) (TestSpecNode, bool) {
+
+	name := callExprName(ce)
+	if !slices.Contains(ginkgoSpecNodes, name) {
+		return TestSpecNode{}, false
+	}
+
+	n := TestSpecNode{Name: name, Nodes: TestOutline{}}
+	args := ce.Args
+	if !isSetupTeardownNode(name) && len(args) > 0 {
+		// follow ginkgo outline and set the text to `undefined`
+		// when it isn't a string literal
+		n.Text = undefinedText
+		if text, ok := stringValue(args[0], consts); ok {
+			n.Text = text
+		}
+		args = args[1:]
+	}
+
+	for _, arg := range args {
+		if expr, ok := arg.(*ast.CallExpr); ok && callExprName(expr) == "Label" {
+			for _, l := range expr.Args {
+				if label, ok := stringValue(l, consts); ok {
+					n.Labels = append(n.Labels, label)
+				}
+			}
+		}
+	}
+	n.Decorators = extractDecorators(args)
+
+	return n, true
+}
+
+// extractDecorators returns the Ginkgo decorators, qualified or not,
+// found within the arguments of a Ginkgo node
+func extractDecorators(args []ast.Expr) []string {
+
+	var decorators []string
+	for _, arg := range args {
 		switch expr := arg.(type) {
-		case *ast.BasicLit:
-			if expr.Kind == token.STRING {
-				unquoted, err := strconv.Unquote(expr.Value)
-				if err != nil {
-					unquoted = expr.Value
+		case *ast.Ident:
+			if slices.Contains(ginkgoDecorators, expr.Name) {
+				decorators = append(decorators, expr.Name)
+			}
+		case *ast.SelectorExpr:
+			if slices.Contains(ginkgoDecorators, expr.Sel.Name) {
+				decorators = append(decorators, expr.Sel.Name)
+			}
+		}
+	}
+
+	return decorators
+}
+
+// isSetupTeardownNode returns whether the Ginkgo node name is
+// one of the setup or teardown nodes which don't have a text
+func isSetupTeardownNode(name string) bool {
+
+	return strings.HasPrefix(name, "Before") || strings.HasPrefix(name, "After") || strings.HasPrefix(name, "Just")
+}
+
+// callExprName returns the name of the called function,
+// with or without the package qualifier, i.e. `ginkgo.Describe`
+func callExprName(ce *ast.CallExpr) string {
+
+	switch expr := ce.Fun.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.SelectorExpr:
+		if _, ok := expr.X.(*ast.Ident); ok {
+			return expr.Sel.Name
+		}
+	}
+	return ""
+}
+
+// stringValue returns the value of a string literal or of a string
+// constant declared within the package
+func stringValue(expr ast.Expr, consts map[string]string) (string, bool) {
+
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		unquoted, err := strconv.Unquote(e.Value)
+		if err != nil {
+			return e.Value, true
+		}
+		return unquoted, true
+	case *ast.Ident:
+		v, ok := consts[e.Name]
+		return v, ok
+	}
+	return "", false
+}
+
+// packageStringConsts returns the string constants declared
+// in the Go files of the package directory
+func packageStringConsts(dir string) (map[string]string, error) {
+
+	consts := map[string]string{}
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		parsedSrc, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range parsedSrc.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if i >= len(vs.Values) {
+						continue
+					}
+					if v, ok := stringValue(vs.Values[i], nil); ok {
+						consts[name.Name] = v
+					}
 				}
-				out = append(out, unquoted)
 			}
 		}
 	}
 
-	return out
+	return consts, nil
+}
+
+// orderedContainerLines returns whether the calls of the file are given the Ordered decorator,
+// keyed by the lines of the calls, which are the lines of the code locations ginkgo reports for
+// the containers declared by them
+func orderedContainerLines(filename string) (map[int]bool, error) {
+
+	fset := token.NewFileSet()
+	parsedSrc, err := parser.ParseFile(fset, filename, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	lines := map[int]bool{}
+	ast.Inspect(parsedSrc, func(n ast.Node) bool {
+		ce, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		ordered := slices.Contains(extractDecorators(ce.Args), "Ordered")
+		for _, pos := range []token.Pos{ce.Pos(), ce.Lparen} {
+			line := fset.Position(pos).Line
+			lines[line] = lines[line] || ordered
+		}
+		return true
+	})
 
+	return lines, nil
 }
@@ -5,4 +5,9 @@ const (
 	FrameworkDescribePath = "templates/framework_describe_func.tmpl"
 
 	SpecsPath = "templates/specs.tmpl"
+
+	TestPlanMarkdownPath = "templates/test_plan.md.tmpl"
+	TestPlanHTMLPath     = "templates/test_plan.html.tmpl"
+
+	FlakeReportMarkdownPath = "templates/flake_report.md.tmpl"
 )
@@ -0,0 +1,435 @@
+package testspecs
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/reporters"
+	"github.com/onsi/ginkgo/v2/types"
+	"k8s.io/klog/v2"
+)
+
+// junitTimestampLayout is the layout of the timestamp of the ginkgo JUnit test suites
+const junitTimestampLayout = "2006-01-02T15:04:05"
+
+// failureSignaturePatterns replace the variable parts of the failure messages,
+// i.e. names with random suffixes, so that similar failures are clustered together
+var failureSignaturePatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`), "<uuid>"},
+	{regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`), "<time>"},
+	{regexp.MustCompile(`\b(sha256:)?[0-9a-f]{12,}\b`), "<hash>"},
+	{regexp.MustCompile(`-[a-z0-9]*[0-9][a-z0-9]*\b`), "-<id>"},
+	{regexp.MustCompile(`\d+(\.\d+)?`), "<n>"},
+	{regexp.MustCompile(`\s+`), " "},
+}
+
+// SpecRun is a single run of a spec found in a ginkgo JSON or JUnit report
+type SpecRun struct {
+	// Spec is the full text of the spec, the texts of its containers followed by its own
+	Spec      string
+	Labels    []string
+	State     string
+	Duration  time.Duration
+	Failure   string
+	StartTime time.Time
+	Report    string
+}
+
+// FlakeReport ranks the specs of historical reports by their flakiness
+type FlakeReport struct {
+	Reports []string
+	Runs    int
+	// Specs are the specs which were executed at least once, the flakiest first
+	Specs []*SpecFlakiness
+	// Clusters are the failures of all the specs, clustered by their signature
+	Clusters []*FailureCluster
+	// Threshold is the flakiness above which the specs are quarantined, if set
+	Threshold float64
+	// QuarantineLabelFilter excludes the labels carried only by the quarantined specs
+	QuarantineLabelFilter string
+	// Unquarantined are the specs above the threshold without a label of their own
+	Unquarantined []string
+}
+
+// SpecFlakiness summarizes the runs of a spec
+type SpecFlakiness struct {
+	Spec    string
+	Labels  []string
+	Runs    int
+	Passed  int
+	Failed  int
+	Skipped int
+	// PassRate is the ratio of passed runs among the executed ones
+	PassRate float64
+	// FlipRate is the ratio of consecutive executed runs which changed from passed to failed or back
+	FlipRate float64
+	// Flakiness is the flip rate of the specs which both passed and failed, 0 otherwise
+	Flakiness   float64
+	DurationP50 time.Duration
+	DurationP90 time.Duration
+	DurationP99 time.Duration
+	Failures    []*FailureCluster
+}
+
+// FailureCluster groups the failures with the same signature
+type FailureCluster struct {
+	Signature string
+	Count     int
+	// Example is the first failure message of the cluster
+	Example string
+	Specs   []string
+}
+
+// LoadSpecRuns walks the directory for the ginkgo JSON (*.json) and JUnit (*.xml) reports and returns the
+// runs of their specs. When a directory holds both, i.e. the e2e-report.json and e2e-report.xml of the same
+// job, only the JSON reports are read so that the runs are not counted twice. Other files are skipped.
+func LoadSpecRuns(dir string) ([]SpecRun, error) {
+
+	jsonReports := map[string][]string{}
+	junitReports := map[string][]string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch filepath.Ext(path) {
+		case ".json":
+			jsonReports[filepath.Dir(path)] = append(jsonReports[filepath.Dir(path)], path)
+		case ".xml":
+			junitReports[filepath.Dir(path)] = append(junitReports[filepath.Dir(path)], path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []SpecRun
+	for reportDir, reports := range jsonReports {
+		for _, file := range reports {
+			fileRuns, err := loadJSONSpecRuns(file)
+			if err != nil {
+				klog.Warningf("skipping %s: %s", file, err)
+				continue
+			}
+			if len(fileRuns) != 0 {
+				delete(junitReports, reportDir)
+			}
+			runs = append(runs, fileRuns...)
+		}
+	}
+	for _, reports := range junitReports {
+		for _, file := range reports {
+			fileRuns, err := loadJUnitSpecRuns(file)
+			if err != nil {
+				klog.Warningf("skipping %s: %s", file, err)
+				continue
+			}
+			runs = append(runs, fileRuns...)
+		}
+	}
+
+	return runs, nil
+}
+
+// loadJSONSpecRuns returns the runs of the It nodes of a ginkgo JSON report
+func loadJSONSpecRuns(file string) ([]SpecRun, error) {
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var reports []types.Report
+	if err := json.Unmarshal(data, &reports); err != nil {
+		return nil, fmt.Errorf("not a ginkgo JSON report: %w", err)
+	}
+
+	var runs []SpecRun
+	for _, report := range reports {
+		for _, sr := range report.SpecReports {
+			if sr.LeafNodeType != types.NodeTypeIt {
+				continue
+			}
+			runs = append(runs, SpecRun{
+				Spec:      sr.FullText(),
+				Labels:    sr.Labels(),
+				State:     sr.State.String(),
+				Duration:  sr.RunTime,
+				Failure:   sr.Failure.Message,
+				StartTime: report.StartTime,
+				Report:    file,
+			})
+		}
+	}
+	return runs, nil
+}
+
+// loadJUnitSpecRuns returns the runs of the It nodes of a ginkgo JUnit report, the name
+// of their test cases is `[It] <full text> [<labels>]`
+func loadJUnitSpecRuns(file string) ([]SpecRun, error) {
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var suites reporters.JUnitTestSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		return nil, fmt.Errorf("not a JUnit report: %w", err)
+	}
+
+	var runs []SpecRun
+	for _, suite := range suites.TestSuites {
+		// the timestamp is missing from older reports, their runs are then ordered by report
+		start, _ := time.Parse(junitTimestampLayout, suite.Timestamp)
+		for _, tc := range suite.TestCases {
+			spec, ok := strings.CutPrefix(tc.Name, "[It] ")
+			if !ok {
+				continue
+			}
+			run := SpecRun{
+				Spec:      spec,
+				State:     tc.Status,
+				Duration:  time.Duration(tc.Time * float64(time.Second)),
+				StartTime: start,
+				Report:    file,
+			}
+			if i := strings.LastIndex(spec, " ["); i != -1 && strings.HasSuffix(spec, "]") {
+				run.Spec = spec[:i]
+				run.Labels = strings.Split(spec[i+2:len(spec)-1], ", ")
+			}
+			switch {
+			case tc.Failure != nil:
+				run.Failure = tc.Failure.Message
+			case tc.Error != nil:
+				run.Failure = tc.Error.Message
+			}
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+// AnalyzeFlakes computes the pass rate, flip rate, duration percentiles and failure
+// clusters of every spec of the runs and ranks the specs by their flakiness
+func AnalyzeFlakes(runs []SpecRun) *FlakeReport {
+
+	report := &FlakeReport{Runs: len(runs)}
+	bySpec := map[string][]SpecRun{}
+	for _, run := range runs {
+		bySpec[run.Spec] = append(bySpec[run.Spec], run)
+		if !slices.Contains(report.Reports, run.Report) {
+			report.Reports = append(report.Reports, run.Report)
+		}
+	}
+	sort.Strings(report.Reports)
+
+	clusters := map[string]*FailureCluster{}
+	for _, spec := range slices.Sorted(maps.Keys(bySpec)) {
+		specRuns := bySpec[spec]
+		sort.SliceStable(specRuns, func(i, j int) bool {
+			if specRuns[i].StartTime.Equal(specRuns[j].StartTime) {
+				return specRuns[i].Report < specRuns[j].Report
+			}
+			return specRuns[i].StartTime.Before(specRuns[j].StartTime)
+		})
+
+		sf := &SpecFlakiness{Spec: spec, Runs: len(specRuns)}
+		specClusters := map[string]*FailureCluster{}
+		var durations []time.Duration
+		var previous string
+		flips := 0
+		for _, run := range specRuns {
+			for _, l := range run.Labels {
+				if !slices.Contains(sf.Labels, l) {
+					sf.Labels = append(sf.Labels, l)
+				}
+			}
+			var outcome string
+			switch run.State {
+			case types.SpecStatePassed.String():
+				sf.Passed++
+				outcome = "passed"
+			case types.SpecStateSkipped.String(), types.SpecStatePending.String():
+				sf.Skipped++
+				continue
+			default:
+				sf.Failed++
+				outcome = "failed"
+				signature := failureSignature(run.Failure)
+				addToFailureCluster(specClusters, signature, run.Failure, spec)
+				addToFailureCluster(clusters, signature, run.Failure, spec)
+			}
+			durations = append(durations, run.Duration)
+			if previous != "" && previous != outcome {
+				flips++
+			}
+			previous = outcome
+		}
+		if sf.Passed+sf.Failed == 0 {
+			continue
+		}
+
+		sf.PassRate = float64(sf.Passed) / float64(sf.Passed+sf.Failed)
+		if sf.Passed+sf.Failed > 1 {
+			sf.FlipRate = float64(flips) / float64(sf.Passed+sf.Failed-1)
+		}
+		if sf.Passed != 0 && sf.Failed != 0 {
+			sf.Flakiness = sf.FlipRate
+		}
+		slices.Sort(durations)
+		sf.DurationP50 = durationPercentile(durations, 50)
+		sf.DurationP90 = durationPercentile(durations, 90)
+		sf.DurationP99 = durationPercentile(durations, 99)
+		sf.Failures = sortedFailureClusters(specClusters)
+		report.Specs = append(report.Specs, sf)
+	}
+	report.Clusters = sortedFailureClusters(clusters)
+
+	sort.Slice(report.Specs, func(i, j int) bool {
+		a, b := report.Specs[i], report.Specs[j]
+		if a.Flakiness != b.Flakiness {
+			return a.Flakiness > b.Flakiness
+		}
+		if a.Failed != b.Failed {
+			return a.Failed > b.Failed
+		}
+		return a.Spec < b.Spec
+	})
+
+	return report
+}
+
+// Quarantine sets the label filter excluding the specs whose flakiness is above the threshold,
+// i.e. to be appended to E2E_EXTRA_LABEL_FILTER. Ginkgo label filters can only select labels, so
+// a label is only excluded when all the specs carrying it are above the threshold. The quarantined
+// specs without such a label are listed as unquarantined, they need a label of their own.
+func (fr *FlakeReport) Quarantine(threshold float64) {
+
+	fr.Threshold = threshold
+	fr.QuarantineLabelFilter = ""
+	fr.Unquarantined = nil
+
+	healthy := map[string]bool{}
+	for _, sf := range fr.Specs {
+		if sf.Flakiness <= threshold {
+			for _, l := range sf.Labels {
+				healthy[l] = true
+			}
+		}
+	}
+
+	var excluded []string
+	for _, sf := range fr.Specs {
+		if sf.Flakiness <= threshold {
+			continue
+		}
+		quarantined := false
+		for _, l := range sf.Labels {
+			if !healthy[l] {
+				quarantined = true
+				if !slices.Contains(excluded, l) {
+					excluded = append(excluded, l)
+				}
+			}
+		}
+		if !quarantined {
+			fr.Unquarantined = append(fr.Unquarantined, sf.Spec)
+		}
+	}
+	sort.Strings(excluded)
+	if len(excluded) != 0 {
+		fr.QuarantineLabelFilter = "!(" + strings.Join(excluded, " || ") + ")"
+	}
+}
+
+// failureSignature returns the first line of the failure message without its variable parts
+func failureSignature(message string) string {
+
+	signature, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	for _, p := range failureSignaturePatterns {
+		signature = p.re.ReplaceAllString(signature, p.repl)
+	}
+	signature = strings.TrimSpace(signature)
+	if len(signature) > 200 {
+		signature = signature[:200]
+	}
+	if signature == "" {
+		return "(no failure message)"
+	}
+	return signature
+}
+
+func addToFailureCluster(clusters map[string]*FailureCluster, signature, message, spec string) {
+
+	c, ok := clusters[signature]
+	if !ok {
+		c = &FailureCluster{Signature: signature, Example: message}
+		clusters[signature] = c
+	}
+	c.Count++
+	if !slices.Contains(c.Specs, spec) {
+		c.Specs = append(c.Specs, spec)
+	}
+}
+
+// sortedFailureClusters returns the clusters, the most frequent first
+func sortedFailureClusters(clusters map[string]*FailureCluster) []*FailureCluster {
+
+	var sorted []*FailureCluster
+	for _, c := range clusters {
+		sort.Strings(c.Specs)
+		sorted = append(sorted, c)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Signature < sorted[j].Signature
+	})
+	return sorted
+}
+
+// durationPercentile returns the nearest-rank percentile of the sorted durations
+func durationPercentile(sorted []time.Duration, p float64) time.Duration {
+
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// RenderFlakeReport writes the flake report into flake-report.md and flake-report.json in the destination directory
+func RenderFlakeReport(destDir string, report *FlakeReport) error {
+
+	err := os.MkdirAll(destDir, 0775)
+	if err != nil {
+		klog.Errorf("failed to create flake report directory, %s", destDir)
+		return err
+	}
+
+	err = renderReportTemplate(filepath.Join(destDir, "flake-report.md"), FlakeReportMarkdownPath, report)
+	if err != nil {
+		klog.Errorf("failed to render the flake report: %s", err)
+		return err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(destDir, "flake-report.json"), data, 0644)
+}
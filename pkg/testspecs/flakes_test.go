@@ -0,0 +1,178 @@
+package testspecs
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/reporters"
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+func flakeSpecReport(leaf string, labels []string, state types.SpecState, runTime time.Duration, failure string) types.SpecReport {
+	return types.SpecReport{
+		ContainerHierarchyTexts:  []string{"[book-suite Book service E2E tests]"},
+		ContainerHierarchyLabels: [][]string{{"book"}},
+		LeafNodeType:             types.NodeTypeIt,
+		LeafNodeText:             leaf,
+		LeafNodeLabels:           labels,
+		State:                    state,
+		RunTime:                  runTime,
+		Failure:                  types.Failure{Message: failure},
+	}
+}
+
+// writeFlakeReports writes the reports of three jobs, the second job only has a JUnit report
+func writeFlakeReports(t *testing.T) string {
+	dir := t.TempDir()
+	start := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	jobs := []struct {
+		name  string
+		specs types.SpecReports
+	}{
+		{"job-1", types.SpecReports{
+			flakeSpecReport("flips", []string{"flaky"}, types.SpecStatePassed, time.Second, ""),
+			flakeSpecReport("is stable", nil, types.SpecStatePassed, time.Second, ""),
+			flakeSpecReport("is broken", []string{"broken"}, types.SpecStateFailed, time.Second, "timed out waiting for pod build-ab1cd after 300s"),
+			flakeSpecReport("flips once", nil, types.SpecStateFailed, time.Second, "pipelinerun 0e3b3f8a-1b6e-4c1a-9d4b-3c1f5a6b7c8d failed"),
+			flakeSpecReport("is pending", nil, types.SpecStatePending, 0, ""),
+		}},
+		{"job-2", types.SpecReports{
+			flakeSpecReport("flips", []string{"flaky"}, types.SpecStateFailed, 4*time.Second, "timed out waiting for pod build-7xk2p after 300s"),
+			flakeSpecReport("is stable", nil, types.SpecStatePassed, 2*time.Second, ""),
+			flakeSpecReport("is broken", []string{"broken"}, types.SpecStateFailed, time.Second, "timed out waiting for pod build-zz9yy after 300s"),
+			flakeSpecReport("flips once", nil, types.SpecStatePassed, time.Second, ""),
+		}},
+		{"job-3", types.SpecReports{
+			flakeSpecReport("flips", []string{"flaky"}, types.SpecStatePassed, 2*time.Second, ""),
+			flakeSpecReport("is stable", nil, types.SpecStatePassed, 3*time.Second, ""),
+			flakeSpecReport("is broken", []string{"broken"}, types.SpecStateFailed, time.Second, "timed out waiting for pod build-q1w2e after 301s"),
+			flakeSpecReport("flips once", nil, types.SpecStatePassed, time.Second, ""),
+		}},
+	}
+	for i, job := range jobs {
+		report := types.Report{SuiteDescription: "E2E", StartTime: start.Add(time.Duration(i) * time.Hour), SpecReports: job.specs}
+		jobDir := filepath.Join(dir, job.name)
+		if err := reporters.GenerateJUnitReport(report, filepath.Join(jobDir, "e2e-report.xml")); err != nil {
+			t.Fatal(err)
+		}
+		if job.name == "job-2" {
+			continue
+		}
+		if err := reporters.GenerateJSONReport(report, filepath.Join(jobDir, "e2e-report.json")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "job-1", "spec-reconciliation.json"), []byte(`{"Declared": 5}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestAnalyzeFlakes(t *testing.T) {
+	dir := writeFlakeReports(t)
+	runs, err := LoadSpecRuns(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 13 {
+		t.Fatalf("expected the 13 runs of the JSON reports of job-1 and job-3 and the JUnit report of job-2, got %d", len(runs))
+	}
+
+	report := AnalyzeFlakes(runs)
+	if len(report.Reports) != 3 || !strings.HasSuffix(report.Reports[1], filepath.Join("job-2", "e2e-report.xml")) {
+		t.Errorf("unexpected reports %v", report.Reports)
+	}
+	var ranked []string
+	for _, sf := range report.Specs {
+		ranked = append(ranked, strings.TrimPrefix(sf.Spec, "[book-suite Book service E2E tests] "))
+	}
+	if !slices.Equal(ranked, []string{"flips", "flips once", "is broken", "is stable"}) {
+		t.Fatalf("unexpected ranking %v", ranked)
+	}
+
+	flips := report.Specs[0]
+	if flips.Runs != 3 || flips.Passed != 2 || flips.Failed != 1 || flips.FlipRate != 1 || flips.Flakiness != 1 {
+		t.Errorf("unexpected flakiness %+v", flips)
+	}
+	if !slices.Equal(flips.Labels, []string{"book", "flaky"}) {
+		t.Errorf("labels of the JSON and JUnit reports differ, got %v", flips.Labels)
+	}
+	if flips.DurationP50 != 2*time.Second || flips.DurationP90 != 4*time.Second {
+		t.Errorf("unexpected duration percentiles %s %s", flips.DurationP50, flips.DurationP90)
+	}
+	if flipsOnce := report.Specs[1]; flipsOnce.FlipRate != 0.5 || flipsOnce.PassRate != 2.0/3 {
+		t.Errorf("unexpected flakiness %+v", flipsOnce)
+	}
+	if broken := report.Specs[2]; broken.Flakiness != 0 || broken.PassRate != 0 || len(broken.Failures) != 1 {
+		t.Errorf("unexpected flakiness %+v", broken)
+	}
+
+	if len(report.Clusters) != 2 {
+		t.Fatalf("expected 2 failure clusters, got %+v", report.Clusters)
+	}
+	timeout := report.Clusters[0]
+	if timeout.Count != 4 || timeout.Signature != "timed out waiting for pod build-<id> after <n>s" || len(timeout.Specs) != 2 {
+		t.Errorf("unexpected failure cluster %+v", timeout)
+	}
+	if report.Clusters[1].Signature != "pipelinerun <uuid> failed" {
+		t.Errorf("unexpected failure cluster %+v", report.Clusters[1])
+	}
+}
+
+func TestFlakeReportQuarantine(t *testing.T) {
+	runs, err := LoadSpecRuns(writeFlakeReports(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := AnalyzeFlakes(runs)
+
+	report.Quarantine(0.4)
+	if report.QuarantineLabelFilter != "!(flaky)" {
+		t.Errorf("unexpected quarantine label filter %q", report.QuarantineLabelFilter)
+	}
+	if len(report.Unquarantined) != 1 || !strings.HasSuffix(report.Unquarantined[0], "flips once") {
+		t.Errorf("unexpected unquarantined specs %v", report.Unquarantined)
+	}
+
+	report.Quarantine(1)
+	if report.QuarantineLabelFilter != "" || len(report.Unquarantined) != 0 {
+		t.Errorf("no spec is above the threshold, got %q %v", report.QuarantineLabelFilter, report.Unquarantined)
+	}
+}
+
+func TestRenderFlakeReport(t *testing.T) {
+	runs, err := LoadSpecRuns(writeFlakeReports(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := AnalyzeFlakes(runs)
+	report.Quarantine(0.4)
+	destDir := t.TempDir()
+	t.Chdir(filepath.Join("..", ".."))
+
+	if err := RenderFlakeReport(destDir, report); err != nil {
+		t.Fatal(err)
+	}
+
+	markdown, err := os.ReadFile(filepath.Join(destDir, "flake-report.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"4 specs executed in 13 runs of 3 reports",
+		"Label filter: `!(flaky)`",
+		"| [book-suite Book service E2E tests] flips | book, flaky | 3 | 2 | 1 | 0 | 0.67 | 1.00 | 1.00 | 2s | 4s | 4s |",
+		"### 4 × `timed out waiting for pod build-<id> after <n>s`",
+	} {
+		if !strings.Contains(string(markdown), expected) {
+			t.Errorf("flake report does not contain %q:\n%s", expected, markdown)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "flake-report.json")); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,357 @@
+package testspecs
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"k8s.io/klog/v2"
+)
+
+// suiteTagPrefix is the prefix of the Feature tag naming the framework describe decorator function
+const suiteTagPrefix = "@suite:"
+
+// gherkinKeywords are the keywords of the Feature and its children which are followed by `:`
+var gherkinKeywords = []string{"Feature", "Rule", "Background", "Scenario", "Example", "Scenario Outline", "Scenario Template", "Examples", "Scenarios"}
+
+// gherkinStepKeywords are the keywords of the steps, a step without one is written with `*`
+var gherkinStepKeywords = []string{"Given ", "When ", "Then ", "And ", "But "}
+
+type GherkinSpecTranslator struct {
+}
+
+// New returns a Gherkin Spec Translator
+func NewGherkinSpecTranslator() *GherkinSpecTranslator {
+
+	return &GherkinSpecTranslator{}
+}
+
+// FromFile generates a TestOutline from a Gherkin feature file. The Feature is the framework
+// describe decorator function, named by its `@suite:<name>` tag or after the file, i.e. books.feature
+// is the BooksSuiteDescribe. A Rule is a Describe, a Background a BeforeEach, a Scenario an It,
+// a Scenario Outline a DescribeTable with an Entry for every row of its Examples and the steps are By nodes.
+// Tags are Labels, except for the Ordered, Serial, Pending and Focus tags which are Decorators.
+func (gst *GherkinSpecTranslator) FromFile(file string) (TestOutline, error) {
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var root *TestSpecNode
+	var container, scenario *TestSpecNode
+	var tags, exampleTags, header []string
+	var docString string
+	inExamples := false
+	for i, line := range strings.Split(strings.ReplaceAll(string(data), "\r", ""), "\n") {
+		line = strings.TrimSpace(line)
+		keyword, text, _ := strings.Cut(line, ":")
+		text = strings.TrimSpace(text)
+
+		if docString != "" {
+			if strings.HasPrefix(line, docString) {
+				docString = ""
+			}
+			continue
+		}
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, `"""`) || strings.HasPrefix(line, "```"):
+			docString = line[:3]
+			continue
+		case strings.HasPrefix(line, "@"):
+			tags = append(tags, strings.Fields(line)...)
+			continue
+		}
+
+		if root == nil && keyword != "Feature" {
+			continue
+		}
+		if slices.Contains(gherkinKeywords, keyword) {
+			inExamples = false
+		}
+		switch keyword {
+		case "Feature":
+			if root != nil {
+				return nil, fmt.Errorf("%s:%d: a feature file can only have a single Feature", file, i+1)
+			}
+			root = &TestSpecNode{Name: featureSuiteName(file), Text: text, Nodes: TestOutline{}}
+			for _, t := range tags {
+				if strings.HasPrefix(t, suiteTagPrefix) {
+					root.Name = strings.TrimPrefix(t, suiteTagPrefix)
+				}
+			}
+			tags = slices.DeleteFunc(tags, func(t string) bool { return strings.HasPrefix(t, suiteTagPrefix) })
+			tagsToLabelsAndDecorators(root, tags)
+			container, scenario = root, nil
+		case "Rule":
+			root.Nodes = append(root.Nodes, gherkinNode("Describe", text, tags))
+			container, scenario = &root.Nodes[len(root.Nodes)-1], nil
+		case "Background":
+			container.Nodes = append(container.Nodes, gherkinNode("BeforeEach", "", tags))
+			scenario = &container.Nodes[len(container.Nodes)-1]
+		case "Scenario", "Example":
+			container.Nodes = append(container.Nodes, gherkinNode("It", text, tags))
+			scenario = &container.Nodes[len(container.Nodes)-1]
+		case "Scenario Outline", "Scenario Template":
+			container.Nodes = append(container.Nodes, gherkinNode("DescribeTable", text, tags))
+			scenario = &container.Nodes[len(container.Nodes)-1]
+		case "Examples", "Scenarios":
+			// the tags of the Examples are the labels of its entries
+			exampleTags, header, inExamples = tags, nil, scenario != nil && scenario.Name == "DescribeTable"
+		default:
+			if scenario == nil {
+				// the description of the Feature or Rule
+				break
+			}
+			if strings.HasPrefix(line, "|") {
+				// data tables of the steps are not part of the outline
+				if !inExamples {
+					break
+				}
+				cells := gherkinTableCells(line)
+				if header == nil {
+					header = cells
+				} else {
+					scenario.Nodes = append(scenario.Nodes, gherkinNode("Entry", gherkinEntryText(header, cells), exampleTags))
+				}
+				break
+			}
+			if step, ok := gherkinStep(line); ok {
+				scenario.Nodes = append(scenario.Nodes, gherkinNode("By", step, nil))
+			}
+		}
+		tags = nil
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("%s: no Feature found", file)
+	}
+	markInnerParentContainer(root.Nodes)
+	return TestOutline{*root}, nil
+}
+
+// ToFile generates a Gherkin feature file from a TestOutline. Gherkin can't nest
+// Rules, so the containers nested in a Describe are flattened into the names of the
+// Scenarios, i.e. `When a book is opened > It has pages`. BeforeEach nodes become
+// Backgrounds while the other setup and teardown nodes are not part of the feature.
+func (gst *GherkinSpecTranslator) ToFile(destination string, outline TestOutline) error {
+
+	if len(outline) != 1 {
+		return fmt.Errorf("a feature file can only have a single Feature, the outline has %d root nodes", len(outline))
+	}
+	root := outline[0]
+
+	var b strings.Builder
+	writeGherkinTags(&b, "", append([]string{suiteTagPrefix + root.Name}, gherkinTags(root)...))
+	fmt.Fprintf(&b, "Feature: %s\n", root.Text)
+	writeGherkinNodes(&b, "  ", root.Nodes, false, nil, nil)
+
+	dir := filepath.Dir(destination)
+	err := os.MkdirAll(dir, 0775)
+	if err != nil {
+		klog.Errorf("failed to create package directory, %s, template with: %v", dir, err)
+		return err
+	}
+	err = os.WriteFile(destination, []byte(b.String()), 0644)
+	if err != nil {
+		return err
+	}
+	klog.Infof("successfully written to %s", destination)
+
+	return nil
+}
+
+// writeGherkinNodes writes the nodes of a Feature or Rule, the path and tags are the
+// ones of the flattened containers the nodes are nested in
+func writeGherkinNodes(b *strings.Builder, indent string, nodes TestOutline, inRule bool, path, tags []string) {
+
+	if !inRule {
+		// everything following a Rule is a part of it, so the Rules are written last
+		nodes = slices.Clone(nodes)
+		slices.SortStableFunc(nodes, func(a, b TestSpecNode) int {
+			return cmp.Compare(gherkinRuleNode(a.Name), gherkinRuleNode(b.Name))
+		})
+	}
+	for _, n := range nodes {
+		switch strings.TrimLeft(n.Name, "FPX") {
+		case "BeforeEach":
+			if len(path) == 0 {
+				fmt.Fprintf(b, "\n%sBackground:\n", indent)
+				writeGherkinSteps(b, indent+"  ", n.Nodes)
+			}
+		case "It", "Specify":
+			fmt.Fprintln(b)
+			writeGherkinTags(b, indent, append(slices.Clone(tags), gherkinTags(n)...))
+			fmt.Fprintf(b, "%sScenario: %s\n", indent, strings.Join(append(slices.Clone(path), n.Text), " > "))
+			writeGherkinSteps(b, indent+"  ", n.Nodes)
+		case "DescribeTable":
+			fmt.Fprintln(b)
+			writeGherkinTags(b, indent, append(slices.Clone(tags), gherkinTags(n)...))
+			fmt.Fprintf(b, "%sScenario Outline: %s\n", indent, strings.Join(append(slices.Clone(path), n.Text), " > "))
+			writeGherkinSteps(b, indent+"  ", n.Nodes)
+			writeGherkinExamples(b, indent+"  ", n.Nodes)
+		default:
+			if gherkinRuleNode(n.Name) == 0 {
+				continue
+			}
+			if !inRule {
+				fmt.Fprintln(b)
+				writeGherkinTags(b, indent, gherkinTags(n))
+				fmt.Fprintf(b, "%sRule: %s\n", indent, n.Text)
+				writeGherkinNodes(b, indent+"  ", n.Nodes, true, nil, nil)
+				continue
+			}
+			// the decorators of the flattened containers are dropped since they can't decorate a Scenario
+			containerTags := slices.Clone(tags)
+			for _, l := range n.Labels {
+				containerTags = append(containerTags, "@"+l)
+			}
+			writeGherkinNodes(b, indent, n.Nodes, true, append(slices.Clone(path), n.Text), containerTags)
+		}
+	}
+}
+
+// gherkinRuleNode returns 1 for the containers written as Rules and 0 otherwise
+func gherkinRuleNode(name string) int {
+
+	switch strings.TrimLeft(name, "FPX") {
+	case "Describe", "Context", "When":
+		return 1
+	}
+	return 0
+}
+
+// writeGherkinSteps writes the By nodes as steps
+func writeGherkinSteps(b *strings.Builder, indent string, nodes TestOutline) {
+
+	for _, n := range nodes {
+		if n.Name != "By" {
+			continue
+		}
+		if _, ok := gherkinStep(n.Text); ok {
+			fmt.Fprintf(b, "%s%s\n", indent, n.Text)
+		} else {
+			fmt.Fprintf(b, "%s* %s\n", indent, n.Text)
+		}
+	}
+}
+
+// writeGherkinExamples writes the Entry nodes as Examples with a single entry
+// column, consecutive entries with the same labels share the Examples
+func writeGherkinExamples(b *strings.Builder, indent string, nodes TestOutline) {
+
+	var tags []string
+	first := true
+	for _, n := range nodes {
+		if strings.TrimLeft(n.Name, "FPX") != "Entry" {
+			continue
+		}
+		if first || !slices.Equal(tags, gherkinTags(n)) {
+			tags = gherkinTags(n)
+			fmt.Fprintln(b)
+			writeGherkinTags(b, indent, tags)
+			fmt.Fprintf(b, "%sExamples:\n%s  | entry |\n", indent, indent)
+			first = false
+		}
+		fmt.Fprintf(b, "%s  | %s |\n", indent, strings.ReplaceAll(n.Text, "|", `\|`))
+	}
+}
+
+func writeGherkinTags(b *strings.Builder, indent string, tags []string) {
+
+	if len(tags) != 0 {
+		fmt.Fprintf(b, "%s%s\n", indent, strings.Join(tags, " "))
+	}
+}
+
+// gherkinTags returns the Labels and Decorators of the node as tags
+func gherkinTags(n TestSpecNode) []string {
+
+	var tags []string
+	for _, d := range n.Decorators {
+		tags = append(tags, "@"+d)
+	}
+	for _, l := range n.Labels {
+		tags = append(tags, "@"+l)
+	}
+	return tags
+}
+
+// gherkinNode creates a node with the Labels and Decorators of the tags
+func gherkinNode(name, text string, tags []string) TestSpecNode {
+
+	n := TestSpecNode{Name: name, Text: text, Nodes: TestOutline{}}
+	tagsToLabelsAndDecorators(&n, tags)
+	return n
+}
+
+func tagsToLabelsAndDecorators(n *TestSpecNode, tags []string) {
+
+	for _, t := range tags {
+		t = strings.TrimPrefix(t, "@")
+		if slices.Contains(ginkgoDecorators, t) {
+			n.Decorators = append(n.Decorators, t)
+		} else {
+			n.Labels = append(n.Labels, t)
+		}
+	}
+}
+
+// gherkinStep returns the text of the step, the `*` keyword is dropped
+func gherkinStep(line string) (string, bool) {
+
+	if strings.HasPrefix(line, "* ") {
+		return strings.TrimSpace(line[2:]), true
+	}
+	for _, k := range gherkinStepKeywords {
+		if strings.HasPrefix(line, k) {
+			return line, true
+		}
+	}
+	return "", false
+}
+
+// gherkinTableCells splits a row of a table, i.e. `| a | b\|c |`
+func gherkinTableCells(line string) []string {
+
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	cells := strings.Split(strings.ReplaceAll(line, `\|`, "\x00"), "|")
+	for i, c := range cells {
+		cells[i] = strings.TrimSpace(strings.ReplaceAll(c, "\x00", "|"))
+	}
+	return cells
+}
+
+// gherkinEntryText returns the text of the Entry of an Examples row,
+// the value of the row when the single column is `entry` or `<column>=<value>` pairs
+func gherkinEntryText(header, cells []string) string {
+
+	if len(header) == 1 && header[0] == "entry" {
+		return cells[0]
+	}
+	var pairs []string
+	for i, h := range header {
+		if i < len(cells) {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", h, cells[i]))
+		}
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// featureSuiteName returns the framework describe decorator function name
+// of the feature file, i.e. book-store.feature is the BookStoreSuiteDescribe
+func featureSuiteName(file string) string {
+
+	base := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	var name strings.Builder
+	for _, word := range strings.FieldsFunc(base, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		name.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return name.String() + "SuiteDescribe"
+}
@@ -0,0 +1,145 @@
+package testspecs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// booksFeatureOutline is the outline of the books fixture once translated to a feature file:
+// the Scenarios of the Feature come before its Rules and the BeforeAll node is dropped
+const booksFeatureOutline = `
+BookSuiteDescribe: Book service E2E tests @book
+  DescribeTable: Reading invalid books always errors @table
+    By: reading the book
+    Entry: Empty book
+    Entry: Only title @title
+    Entry: Missing pages
+
+  Describe (Ordered): Categorizing book length @length
+    It: the book has more than 300 pages > should be a novel @slow
+      By: counting the pages: all of them
+
+  Describe (Serial): Creating bookmarks in a book @bookmark, @parallel
+    BeforeEach:
+    It (Pending): has no bookmarks by default
+    It: can add bookmarks
+`
+
+const libraryFeature = `# the library feature
+@slow @Serial
+Feature: Library loans
+  Members can borrow books.
+
+  Background:
+    Given a member
+
+  @smoke
+  Scenario: borrowing a book
+    When the member borrows "Dune"
+    Then the loan is recorded
+      | title | days |
+      | Dune  | 14   |
+    And the member is notified
+      """
+      Dune: due in 14 days
+      """
+
+  Scenario Outline: renewing a loan
+    * renew the loan for <days> days
+
+    Examples:
+      | days | allowed |
+      | 7    | yes     |
+
+    @limits
+    Examples:
+      | days | allowed |
+      | 60   | no      |
+
+  @Ordered
+  Rule: Returning books
+    Scenario: returning in time
+      * return the book
+`
+
+const libraryOutline = `
+LibraryLoansSuiteDescribe (Serial): Library loans @slow
+  BeforeEach:
+    By: Given a member
+
+  It: borrowing a book @smoke
+    By: When the member borrows "Dune"
+    By: Then the loan is recorded
+    By: And the member is notified
+  DescribeTable: renewing a loan
+    By: renew the loan for <days> days
+    Entry: days=7, allowed=yes
+    Entry: days=60, allowed=no @limits
+
+  Describe (Ordered): Returning books
+    It: returning in time
+      By: return the book
+`
+
+func TestGherkinSpecTranslatorRoundTrip(t *testing.T) {
+	outline, err := NewGinkgoSpecTranslator().FromFile(booksSpecFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	featureFile := filepath.Join(t.TempDir(), "books.feature")
+	gst := NewGherkinSpecTranslator()
+	if err := gst.ToFile(featureFile, outline); err != nil {
+		t.Fatal(err)
+	}
+	featureOutline, err := gst.FromFile(featureFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := featureOutline.ToString(); got != booksFeatureOutline {
+		t.Errorf("feature outline does not match the outline of the Ginkgo spec file, got:\n%s", got)
+	}
+}
+
+func TestGherkinSpecTranslatorFromFile(t *testing.T) {
+	featureFile := filepath.Join(t.TempDir(), "library-loans.feature")
+	if err := os.WriteFile(featureFile, []byte(libraryFeature), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outline, err := NewGherkinSpecTranslator().FromFile(featureFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := outline.ToString(); got != libraryOutline {
+		t.Errorf("unexpected outline of the feature file, got:\n%s", got)
+	}
+	if root := outline[0]; len(root.Decorators) != 1 || root.Decorators[0] != "Serial" {
+		t.Errorf("decorators of the Feature were not parsed: %+v", root.Decorators)
+	}
+	if !outline[0].Nodes[3].InnerParentContainer {
+		t.Errorf("the Rule was not marked as inner parent container")
+	}
+}
+
+func TestGherkinSpecTranslatorErrors(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"no-feature.feature":   "Scenario: orphan\n  * step\n",
+		"two-features.feature": "Feature: one\nFeature: two\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewGherkinSpecTranslator().FromFile(filepath.Join(dir, name)); err == nil {
+			t.Errorf("expected an error parsing %s", name)
+		}
+	}
+
+	if err := NewGherkinSpecTranslator().ToFile(filepath.Join(dir, "out.feature"), TestOutline{{}, {}}); err == nil {
+		t.Errorf("expected an error writing an outline with two root nodes")
+	}
+}
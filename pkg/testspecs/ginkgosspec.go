@@ -17,7 +17,6 @@ import (
 type GinkgosSpecTranslator struct {
 }
 
-var ginkgoOutlineJsonCmd = sh.OutCmd("ginkgo", "outline", "--format", "json")
 var ginkgoGenerateSpecCmd = sh.OutCmd("ginkgo", "generate")
 
 // New returns a Ginkgo Spec Translator
@@ -29,18 +28,12 @@ func NewGinkgoSpecTranslator() *GinkgosSpecTranslator {
 // FromFile generates a TestOutline from a Ginkgo test File
 func (gst *GinkgosSpecTranslator) FromFile(file string) (TestOutline, error) {
 
-	var nodes TestOutline
-	output, err := ginkgoOutlineJsonCmd(file)
+	nodes, err := ExtractGinkgoOutline(file)
 	if err != nil {
-		return nil, err
-	}
-	err = json.Unmarshal([]byte(output), &nodes)
-	if err != nil {
-		klog.Error("Failed to unmarshal spec outline")
+		klog.Error("Failed to extract the spec outline from the AST")
 		return nil, err
 	}
 	markInnerParentContainer(nodes)
-	nodes = excludeSetupTeardownNodes(nodes)
 	frameworkDescribeNode, err := ExtractFrameworkDescribeNode(file)
 	if err != nil {
 		klog.Error("Failed to extract the framework describe node from the AST")
@@ -87,29 +80,6 @@ func markInnerParentContainer(nodes TestOutline) {
 	}
 }
 
-// excludeSetupTeardownNodes removes those nodes from the ginkgo
-// outline output since they don't included anything useful anyways
-func excludeSetupTeardownNodes(nodes TestOutline) TestOutline {
-	excludes := []string{"JustBeforeEach", "BeforeEach", "BeforeAll", "JustAfterEach", "AfterAll", "AfterEach"}
-	for i := 0; i < len(nodes); i++ {
-		for _, ex := range excludes {
-			if ex == nodes[i].Name {
-				nodes = append(nodes[:i], nodes[i+1:]...)
-				nodes = excludeSetupTeardownNodes(nodes)
-				break
-			}
-
-		}
-
-		if len(nodes[i].Nodes) != 0 {
-			nodes[i].Nodes = excludeSetupTeardownNodes(nodes[i].Nodes)
-		}
-	}
-
-	return nodes
-
-}
-
 // generateGinkgoSpec will call the ginkgo generate command
 // to generate the ginkgo data json file we created and
 // the template located in out templates directory
@@ -117,14 +87,12 @@ func generateGinkgoSpec(cwd, teamTmplPath, destination string, dataFile string)
 
 	var err error
 
-	if teamTmplPath != TestFilePath {
-		tmplFile, err := mergeTemplates(teamTmplPath, SpecsPath)
-		if err != nil {
-			return err
-		}
-		defer os.Remove(tmplFile.Name())
-		teamTmplPath = tmplFile.Name()
+	tmplFile, err := mergeTemplates(teamTmplPath, SpecsPath)
+	if err != nil {
+		return err
 	}
+	defer os.Remove(tmplFile.Name())
+	teamTmplPath = tmplFile.Name()
 
 	// Note I change into the directory and rename things because ginkgo
 	// by default generates the test file name as <package>_test.go.
@@ -1,11 +1,48 @@
 package testspecs
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"go/format"
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"text/template"
+
+	sprig "github.com/go-task/slim-sprig"
+)
+
+const (
+	booksSuiteDir   = "testdata/books"
+	booksSpecFile   = booksSuiteDir + "/books.go"
+	releaseSuiteDir = "testdata/release"
 )
 
+// booksOutline is the text outline of the books spec file
+const booksOutline = `
+BookSuiteDescribe: Book service E2E tests @book
+  Describe (Ordered): Categorizing book length @length
+    BeforeAll:
+      By: creating the library
+    When: the book has more than 300 pages @slow
+      It: should be a novel
+        By: counting the pages: all of them
+    AfterAll:
+
+  Describe (Serial): Creating bookmarks in a book @bookmark, @parallel
+    BeforeEach (OncePerOrdered):
+    It (Pending): has no bookmarks by default
+    PIt: can add bookmarks
+
+  DescribeTable: Reading invalid books always errors @table
+    By: reading the book
+    Entry: Empty book
+    Entry: Only title @title
+    FEntry: Missing pages
+`
+
 func TestMergeTemplates(t *testing.T) {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -20,11 +57,7 @@ func TestMergeTemplates(t *testing.T) {
 	}
 	defer os.RemoveAll(tempDir)
 
-	err = os.Chdir(tempDir)
-	if err != nil {
-		t.Fatal(err)
-		return
-	}
+	t.Chdir(tempDir)
 
 	var fileNames []string
 	var expectedString string
@@ -67,3 +100,99 @@ func TestMergeTemplates(t *testing.T) {
 		t.Errorf("content of merged file does not match the expected content")
 	}
 }
+
+func TestGinkgoSpecTranslatorFromFile(t *testing.T) {
+	outline, err := NewGinkgoSpecTranslator().FromFile(booksSpecFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := outline.ToString(); got != booksOutline {
+		t.Errorf("outline does not match the expected outline, got:\n%s", got)
+	}
+}
+
+func TestGinkgoSpecTranslatorFromFileQualifiedLabels(t *testing.T) {
+	outline, err := NewGinkgoSpecTranslator().FromFile(releaseSuiteDir + "/fbc_release.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outline) != 1 {
+		t.Fatalf("expected the framework describe node, got %v", outline)
+	}
+
+	// the qualified ginkgo.Label of the framework describe, referencing a constant, is kept along its decorators
+	suite := outline[0]
+	if suite.Name != "ReleasePipelinesSuiteDescribe" || suite.Text != "FBC e2e-tests" {
+		t.Errorf("unexpected framework describe node %s %q", suite.Name, suite.Text)
+	}
+	if !reflect.DeepEqual(suite.Labels, []string{"release-pipelines", "fbc-release"}) {
+		t.Errorf("unexpected labels of the framework describe node %v", suite.Labels)
+	}
+	if !reflect.DeepEqual(suite.Decorators, []string{"Pending"}) {
+		t.Errorf("unexpected decorators of the framework describe node %v", suite.Decorators)
+	}
+	if len(suite.Nodes) != 1 || !reflect.DeepEqual(suite.Nodes[0].Labels, []string{"fbcHappyPath"}) {
+		t.Errorf("unexpected nodes of the framework describe node %v", suite.Nodes)
+	}
+}
+
+func TestGenerateGinkgoSpecFromOutline(t *testing.T) {
+	outline, err := NewGinkgoSpecTranslator().FromFile(booksSpecFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mergedFile, err := mergeTemplates(filepath.Join("..", "..", TestFilePath), filepath.Join("..", "..", SpecsPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(mergedFile.Name())
+	tmpl, err := os.ReadFile(mergedFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// render the template the same way `ginkgo generate` does with the template data file
+	specFile := filepath.Join(t.TempDir(), "books", "books.go")
+	tmplData, err := json.Marshal(NewTemplateData(outline, specFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var customData map[string]any
+	if err := json.Unmarshal(tmplData, &customData); err != nil {
+		t.Fatal(err)
+	}
+	specTemplate, err := template.New("spec").Funcs(sprig.TxtFuncMap()).Option("missingkey=error").Parse(string(tmpl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec bytes.Buffer
+	if err := specTemplate.Execute(&spec, map[string]any{"CustomData": customData}); err != nil {
+		t.Fatal(err)
+	}
+
+	formatted, err := format.Source(spec.Bytes())
+	if err != nil {
+		t.Fatalf("generated spec is not valid Go: %v\n%s", err, spec.String())
+	}
+	if err := os.MkdirAll(filepath.Dir(specFile), 0775); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(specFile, formatted, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	generated, err := NewGinkgoSpecTranslator().FromFile(specFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the template initializes the framework in a BeforeAll of its own
+	if len(generated) != 1 || len(generated[0].Nodes) == 0 || generated[0].Nodes[0].Name != "BeforeAll" {
+		t.Fatalf("generated spec is missing the framework BeforeAll:\n%s", generated.ToString())
+	}
+	generated[0].Nodes = generated[0].Nodes[1:]
+	if got := generated.ToString(); got != booksOutline {
+		t.Errorf("generated spec does not have the shape of the outline, got:\n%s\nspec:\n%s", got, formatted)
+	}
+}
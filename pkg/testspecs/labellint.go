@@ -0,0 +1,211 @@
+package testspecs
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// LabelUsage is a label declared on a Ginkgo node or on the framework describe decorator function
+type LabelUsage struct {
+	// Label is empty when it isn't a string literal nor a string constant of the package
+	Label string
+	// Node is the name of the node declaring the label, i.e. Describe
+	Node string
+	// Suite is the name of the framework describe decorator function of the file, if any
+	Suite string
+	Pos   token.Position
+}
+
+// ExtractLabelUsages returns the labels declared within the Ginkgo spec file with their position
+func ExtractLabelUsages(filename string) ([]LabelUsage, error) {
+
+	fset := token.NewFileSet()
+	parsedSrc, err := parser.ParseFile(fset, filename, nil, 0)
+	if err != nil {
+		klog.Errorf("Failed to parse file to inspect %s", filename)
+		return nil, err
+	}
+	consts, err := packageStringConsts(filepath.Dir(filename))
+	if err != nil {
+		klog.Errorf("Failed to parse the package of %s", filename)
+		return nil, err
+	}
+
+	var usages []LabelUsage
+	var suite string
+	ast.Inspect(parsedSrc, func(n ast.Node) bool {
+		ce, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		name := callExprName(ce)
+		if _, ok := ginkgoNodeFromCallExpr(ce, consts); !ok {
+			if suite != "" || findFrameworkDescribeAstNode(ce, consts).Name == "" {
+				return true
+			}
+			suite = name
+		}
+		for _, arg := range ce.Args {
+			label, ok := arg.(*ast.CallExpr)
+			if !ok || callExprName(label) != "Label" {
+				continue
+			}
+			for _, l := range label.Args {
+				value, _ := stringValue(l, consts)
+				usages = append(usages, LabelUsage{Label: value, Node: name, Suite: suite, Pos: fset.Position(l.Pos())})
+			}
+		}
+		return true
+	})
+
+	return usages, nil
+}
+
+// LabelLintConfig is the allowlist and the naming rules of the labels, see tests/labels.yaml
+type LabelLintConfig struct {
+	// Pattern is the regular expression every label has to match
+	Pattern string `json:"pattern"`
+	// Allowed are the labels which can be declared by the specs
+	Allowed []string `json:"allowed"`
+	// NamingExceptions are allowed labels which don't follow the naming rules,
+	// i.e. labels the release pipelines are selected by
+	NamingExceptions []string `json:"namingExceptions,omitempty"`
+	// ReferencedOnly are labels referenced by the rules engine
+	// which are not expected to be declared by any spec
+	ReferencedOnly []string `json:"referencedOnly,omitempty"`
+}
+
+// LabelReference is a label referenced by a label filter of the rules engine
+type LabelReference struct {
+	Label string
+	// Excluded is set when the label filter excludes the label, i.e. !upgrade-create
+	Excluded bool
+	Pos      token.Position
+}
+
+// Severities of the label lint issues, only errors fail the lint
+const (
+	LabelLintError   = "error"
+	LabelLintWarning = "warning"
+)
+
+// LabelLintIssue is a problem found by LintLabels
+type LabelLintIssue struct {
+	Pos      token.Position
+	Severity string
+	Message  string
+}
+
+func (i LabelLintIssue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Pos, i.Severity, i.Message)
+}
+
+// LoadLabelLintConfig reads the label lint config from the YAML file
+func LoadLabelLintConfig(path string) (*LabelLintConfig, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &LabelLintConfig{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse label lint config %s: %w", path, err)
+	}
+	if _, err := regexp.Compile(config.Pattern); err != nil {
+		return nil, fmt.Errorf("invalid pattern of label lint config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// LintLabels checks the labels declared by the Ginkgo specs of the tests directory against the
+// allowlist and naming rules of the config and against the labels referenced by the rules engine.
+// The issues are sorted by their position.
+func LintLabels(testsDir string, config *LabelLintConfig, references []LabelReference) ([]LabelLintIssue, error) {
+
+	pattern, err := regexp.Compile(config.Pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var usages []LabelUsage
+	err = walkGinkgoSpecFiles(testsDir, func(path string) error {
+		u, err := ExtractLabelUsages(path)
+		usages = append(usages, u...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []LabelLintIssue
+	declared := map[string]bool{}
+	for _, u := range usages {
+		switch {
+		case u.Label == "":
+			issues = append(issues, LabelLintIssue{u.Pos, LabelLintWarning,
+				fmt.Sprintf("label of %s is not a string constant and can't be checked", u.Node)})
+			continue
+		case !slices.Contains(config.Allowed, u.Label):
+			issues = append(issues, LabelLintIssue{u.Pos, LabelLintError,
+				fmt.Sprintf("label %q of %s is not in the allowlist", u.Label, u.Node)})
+		case !pattern.MatchString(u.Label) && !slices.Contains(config.NamingExceptions, u.Label):
+			issues = append(issues, LabelLintIssue{u.Pos, LabelLintError,
+				fmt.Sprintf("label %q of %s does not match the naming pattern %s", u.Label, u.Node, config.Pattern)})
+		}
+		declared[u.Label] = true
+	}
+
+	referenced := map[string]bool{}
+	for _, r := range references {
+		referenced[r.Label] = true
+		if declared[r.Label] || slices.Contains(config.ReferencedOnly, r.Label) {
+			continue
+		}
+		// excluding a label no spec declares doesn't change the selected specs
+		severity := LabelLintError
+		if r.Excluded {
+			severity = LabelLintWarning
+		}
+		issues = append(issues, LabelLintIssue{r.Pos, severity,
+			fmt.Sprintf("label %q is referenced by the rules engine but no spec declares it", r.Label)})
+	}
+
+	// the labels of the suites are the ones the rules engine selects the tests by
+	for _, u := range usages {
+		if u.Label != "" && u.Node == u.Suite && !referenced[u.Label] {
+			issues = append(issues, LabelLintIssue{u.Pos, LabelLintWarning,
+				fmt.Sprintf("label %q of %s is not referenced by the rules engine", u.Label, u.Suite)})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Pos.Filename != issues[j].Pos.Filename {
+			return issues[i].Pos.Filename < issues[j].Pos.Filename
+		}
+		return issues[i].Pos.Line < issues[j].Pos.Line
+	})
+
+	return issues, nil
+}
+
+// LabelLintErrors returns the number of issues with the error severity
+func LabelLintErrors(issues []LabelLintIssue) int {
+
+	errors := 0
+	for _, i := range issues {
+		if i.Severity == LabelLintError {
+			errors++
+		}
+	}
+	return errors
+}
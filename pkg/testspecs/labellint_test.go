@@ -0,0 +1,88 @@
+package testspecs
+
+import (
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestExtractLabelUsages(t *testing.T) {
+	usages, err := ExtractLabelUsages(booksSpecFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, u := range usages {
+		got = append(got, strings.Join([]string{u.Label, u.Node, u.Suite}, "/"))
+	}
+	expected := []string{
+		"book/BookSuiteDescribe/BookSuiteDescribe",
+		"length/Describe/BookSuiteDescribe",
+		"slow/When/BookSuiteDescribe",
+		"bookmark/Describe/BookSuiteDescribe",
+		"parallel/Describe/BookSuiteDescribe",
+		"table/DescribeTable/BookSuiteDescribe",
+		"title/Entry/BookSuiteDescribe",
+	}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected label usages %v, got %v", expected, got)
+	}
+	if usages[2].Pos.Line != 19 || usages[2].Pos.Filename != booksSpecFile {
+		t.Errorf("unexpected position of the label resolved from a constant: %s", usages[2].Pos)
+	}
+}
+
+func TestLintLabels(t *testing.T) {
+	config := &LabelLintConfig{
+		Pattern:          "^[a-z]{5,}$",
+		Allowed:          []string{"book", "length", "slow", "bookmark", "parallel", "table"},
+		NamingExceptions: []string{"book"},
+		ReferencedOnly:   []string{"no-test-case"},
+	}
+	references := []LabelReference{
+		{Label: "bookmark", Pos: token.Position{Filename: "rules.go", Line: 1}},
+		{Label: "no-test-case", Pos: token.Position{Filename: "rules.go", Line: 2}},
+		{Label: "novel", Pos: token.Position{Filename: "rules.go", Line: 3}},
+		{Label: "upgrade", Excluded: true, Pos: token.Position{Filename: "rules.go", Line: 4}},
+	}
+
+	issues, err := LintLabels(booksSuiteDir, config, references)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, i := range issues {
+		got = append(got, i.String())
+	}
+	expected := []string{
+		"rules.go:3: error: label \"novel\" is referenced by the rules engine but no spec declares it",
+		"rules.go:4: warning: label \"upgrade\" is referenced by the rules engine but no spec declares it",
+		"testdata/books/books.go:10:69: warning: label \"book\" of BookSuiteDescribe is not referenced by the rules engine",
+		"testdata/books/books.go:19:50: error: label \"slow\" of When does not match the naming pattern ^[a-z]{5,}$",
+		"testdata/books/books.go:42:29: error: label \"title\" of Entry is not in the allowlist",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected issues:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+	if errors := LabelLintErrors(issues); errors != 3 {
+		t.Errorf("expected 3 errors, got %d", errors)
+	}
+}
+
+func TestLoadLabelLintConfig(t *testing.T) {
+	config, err := LoadLabelLintConfig("../../tests/labels.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range config.NamingExceptions {
+		found := false
+		for _, a := range config.Allowed {
+			found = found || a == l
+		}
+		if !found {
+			t.Errorf("naming exception %q is not in the allowlist", l)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package testspecs
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+// SpecReconciliation compares the specs declared in a test plan with the specs
+// of the ginkgo JSON reports of several runs, i.e. the e2e-report.json of the CI jobs
+type SpecReconciliation struct {
+	Reports []string
+	// LabelFilters are the label filters the reports were run with
+	LabelFilters []string
+	// Declared is the number of specs of the test plan
+	Declared int
+	// NeverExecuted are the declared specs which are missing from all the reports
+	NeverExecuted []ReconciledSpec
+	// AlwaysSkipped are the declared specs which were skipped or pending in every report they are part of
+	AlwaysSkipped []ReconciledSpec
+	// LabelOrphaned are the declared specs whose labels match none of the label filters of the reports
+	LabelOrphaned []ReconciledSpec
+	// Unresolved are the declared specs whose texts are computed at runtime, so they can't be
+	// matched with the reports. They are resolved when the plan is built from a dry run report.
+	Unresolved []ReconciledSpec
+}
+
+// ReconciledSpec is a declared spec together with the number of times it was found in every state
+type ReconciledSpec struct {
+	Suite  string
+	Spec   *PlannedSpec
+	States map[string]int
+}
+
+// ReconcileReports matches the specs of the test plan with the specs of the ginkgo JSON reports
+func ReconcileReports(plan *TestPlan, reportFiles []string) (*SpecReconciliation, error) {
+
+	if len(reportFiles) == 0 {
+		return nil, fmt.Errorf("no ginkgo JSON report to reconcile the specs with")
+	}
+
+	rec := &SpecReconciliation{Reports: reportFiles}
+	var reports []types.Report
+	var filters []types.LabelFilter
+	for _, file := range reportFiles {
+		fileReports, err := LoadGinkgoReports(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, report := range fileReports {
+			filter, err := types.ParseLabelFilter(report.SuiteConfig.LabelFilter)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse the label filter of %s: %w", file, err)
+			}
+			reports = append(reports, report)
+			filters = append(filters, filter)
+			rec.LabelFilters = append(rec.LabelFilters, report.SuiteConfig.LabelFilter)
+		}
+	}
+
+	for _, suite := range plan.Suites {
+		for _, spec := range suite.Specs {
+			rec.Declared++
+			rs := ReconciledSpec{Suite: suite.Name, Spec: spec, States: map[string]int{}}
+			selected := false
+			for i, report := range reports {
+				selected = selected || filters[i](append(slices.Clone(report.SuiteLabels), spec.Labels...))
+				for _, sr := range report.SpecReports {
+					if sr.LeafNodeType == types.NodeTypeIt && len(sr.ContainerHierarchyTexts) != 0 && spec.matches(sr) {
+						rs.States[sr.State.String()]++
+					}
+				}
+			}
+
+			if !selected {
+				rec.LabelOrphaned = append(rec.LabelOrphaned, rs)
+			}
+			switch {
+			case len(rs.States) == 0 && spec.unresolved():
+				rec.Unresolved = append(rec.Unresolved, rs)
+			case len(rs.States) == 0:
+				rec.NeverExecuted = append(rec.NeverExecuted, rs)
+			case rs.States[types.SpecStateSkipped.String()]+rs.States[types.SpecStatePending.String()] == rs.runs():
+				rec.AlwaysSkipped = append(rec.AlwaysSkipped, rs)
+			}
+		}
+	}
+
+	return rec, nil
+}
+
+// unresolved returns whether the text of the spec or of one of its containers isn't known statically
+func (ps *PlannedSpec) unresolved() bool {
+
+	return ps.Text == undefinedText || slices.Contains(ps.Hierarchy, undefinedText)
+}
+
+// runs returns the number of times the spec was found in the reports
+func (rs ReconciledSpec) runs() int {
+
+	runs := 0
+	for _, n := range rs.States {
+		runs += n
+	}
+	return runs
+}
+
+// String renders the spec with its labels and states, i.e. `BuildSuiteDescribe: a > b @build [skipped: 2]`
+func (rs ReconciledSpec) String() string {
+
+	s := fmt.Sprintf("%s: %s", rs.Suite, rs.Spec.Path())
+	if len(rs.Spec.Labels) != 0 {
+		s += " @" + strings.Join(rs.Spec.Labels, ", @")
+	}
+	var states []string
+	for _, state := range slices.Sorted(maps.Keys(rs.States)) {
+		states = append(states, fmt.Sprintf("%s: %d", state, rs.States[state]))
+	}
+	if len(states) != 0 {
+		s += " [" + strings.Join(states, ", ") + "]"
+	}
+	return s
+}
+
+// String renders the reconciliation as a plain text report
+func (sr *SpecReconciliation) String() string {
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Reconciled %d declared specs with %d ginkgo reports\n", sr.Declared, len(sr.Reports))
+	for _, group := range []struct {
+		title string
+		specs []ReconciledSpec
+	}{
+		{"Never executed", sr.NeverExecuted},
+		{"Always skipped", sr.AlwaysSkipped},
+		{"Label orphaned", sr.LabelOrphaned},
+		{"Unresolved", sr.Unresolved},
+	} {
+		fmt.Fprintf(&b, "\n%s (%d):\n", group.title, len(group.specs))
+		for _, rs := range group.specs {
+			fmt.Fprintf(&b, "  %s\n", rs)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,175 @@
+package testspecs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+func writeGinkgoReport(t *testing.T, name string, reports ...types.Report) string {
+	data, err := json.Marshal(reports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reportFile := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(reportFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return reportFile
+}
+
+func bookSpecReport(state types.SpecState, leaf string, containers ...string) types.SpecReport {
+	return types.SpecReport{
+		ContainerHierarchyTexts: append([]string{"[book-suite Book service E2E tests]"}, containers...),
+		LeafNodeType:            types.NodeTypeIt,
+		LeafNodeText:            leaf,
+		State:                   state,
+	}
+}
+
+func reconciledPaths(specs []ReconciledSpec) []string {
+	var paths []string
+	for _, rs := range specs {
+		paths = append(paths, rs.Spec.Path())
+	}
+	return paths
+}
+
+func TestReconcileReports(t *testing.T) {
+	plan, err := NewTestPlan(booksSuiteDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan.Suites = append(plan.Suites, &SuitePlan{Name: "DynamicSuiteDescribe", Specs: []*PlannedSpec{
+		{Hierarchy: []string{"Dynamic", undefinedText}, Text: "is generated at runtime"},
+	}})
+
+	lengthReport := writeGinkgoReport(t, "length.json", types.Report{
+		SuiteConfig: types.SuiteConfig{LabelFilter: "length"},
+		SpecReports: types.SpecReports{
+			bookSpecReport(types.SpecStatePassed, "should be a novel", "Categorizing book length", "the book has more than 300 pages"),
+			bookSpecReport(types.SpecStateSkipped, "Empty book", "Reading invalid books always errors"),
+			bookSpecReport(types.SpecStateSkipped, "Only title", "Reading invalid books always errors"),
+		},
+	})
+	tableReport := writeGinkgoReport(t, "table.json", types.Report{
+		SuiteConfig: types.SuiteConfig{LabelFilter: "table && !title"},
+		SpecReports: types.SpecReports{
+			bookSpecReport(types.SpecStateSkipped, "Empty book", "Reading invalid books always errors"),
+			bookSpecReport(types.SpecStateSkipped, "Only title", "Reading invalid books always errors"),
+			bookSpecReport(types.SpecStatePassed, "Missing pages", "Reading invalid books always errors"),
+		},
+	})
+
+	rec, err := ReconcileReports(plan, []string{lengthReport, tableReport})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Declared != 7 || !slices.Equal(rec.LabelFilters, []string{"length", "table && !title"}) {
+		t.Errorf("unexpected reconciliation %d %v", rec.Declared, rec.LabelFilters)
+	}
+	bookmarks := []string{"Creating bookmarks in a book > has no bookmarks by default", "Creating bookmarks in a book > can add bookmarks"}
+	if got := reconciledPaths(rec.NeverExecuted); !slices.Equal(got, bookmarks) {
+		t.Errorf("unexpected never executed specs %v", got)
+	}
+	if got := reconciledPaths(rec.AlwaysSkipped); !slices.Equal(got, []string{"Reading invalid books always errors > Empty book", "Reading invalid books always errors > Only title"}) {
+		t.Errorf("unexpected always skipped specs %v", got)
+	}
+	orphaned := append(bookmarks, "Reading invalid books always errors > Only title", "undefined > is generated at runtime")
+	if got := reconciledPaths(rec.LabelOrphaned); !slices.Equal(got, orphaned) {
+		t.Errorf("unexpected label orphaned specs %v", got)
+	}
+	if got := reconciledPaths(rec.Unresolved); !slices.Equal(got, []string{"undefined > is generated at runtime"}) {
+		t.Errorf("unexpected unresolved specs %v", got)
+	}
+	if got := rec.AlwaysSkipped[0].String(); got != "BookSuiteDescribe: Reading invalid books always errors > Empty book @book, @table [skipped: 2]" {
+		t.Errorf("unexpected rendering of the spec %q", got)
+	}
+
+	if _, err := ReconcileReports(plan, nil); err == nil {
+		t.Errorf("expected an error without reports")
+	}
+}
+
+func TestReconcileReportsQualifiedLabels(t *testing.T) {
+	plan, err := NewTestPlan(releaseSuiteDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fbcSpecReport := func(leaf string) types.SpecReport {
+		return types.SpecReport{
+			ContainerHierarchyTexts: []string{"[release-pipelines-suite FBC e2e-tests]", "with FBC happy path"},
+			LeafNodeType:            types.NodeTypeIt,
+			LeafNodeText:            leaf,
+			State:                   types.SpecStatePending,
+		}
+	}
+	pipelinesReport := writeGinkgoReport(t, "pipelines.json", types.Report{
+		SuiteConfig: types.SuiteConfig{LabelFilter: "release-pipelines || build-templates"},
+		SpecReports: types.SpecReports{
+			fbcSpecReport("verifies the fbc release pipelinerun is running and succeeds"),
+			fbcSpecReport("verifies release CR completed and set succeeded"),
+		},
+	})
+	buildReport := writeGinkgoReport(t, "build.json", types.Report{
+		SuiteConfig: types.SuiteConfig{LabelFilter: "build-templates"},
+	})
+
+	// the specs inherit the labels of the qualified ginkgo.Label of the framework describe
+	rec, err := ReconcileReports(plan, []string{pipelinesReport})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Declared != 2 || len(rec.LabelOrphaned) != 0 || len(rec.NeverExecuted) != 0 || len(rec.AlwaysSkipped) != 2 {
+		t.Errorf("unexpected reconciliation %d, label orphaned %v, never executed %v, always skipped %v", rec.Declared,
+			reconciledPaths(rec.LabelOrphaned), reconciledPaths(rec.NeverExecuted), reconciledPaths(rec.AlwaysSkipped))
+	}
+
+	rec, err = ReconcileReports(plan, []string{buildReport})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.LabelOrphaned) != 2 {
+		t.Errorf("expected the specs to be label orphaned, got %v", reconciledPaths(rec.LabelOrphaned))
+	}
+}
+
+func TestNewTestPlanFromReport(t *testing.T) {
+	dynamic := bookSpecReport(types.SpecStatePassed, "pipeline docker-build should succeed", "Categorizing book length")
+	dynamic.LeafNodeLabels = []string{"slow"}
+	dynamic.ContainerHierarchyLabels = [][]string{{"book"}, {"book", "length"}}
+	inventory := writeGinkgoReport(t, "specs.json", types.Report{SpecReports: types.SpecReports{
+		dynamic,
+		bookSpecReport(types.SpecStatePending, "has no bookmarks by default", "Creating bookmarks in a book"),
+		{LeafNodeType: types.NodeTypeBeforeSuite, State: types.SpecStatePassed},
+	}})
+
+	plan, err := NewTestPlanFromReport(inventory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Suites) != 1 || plan.Suites[0].Name != "[book-suite Book service E2E tests]" || len(plan.Suites[0].Specs) != 2 {
+		t.Fatalf("unexpected suites %+v", plan.Suites)
+	}
+	specs := plan.Suites[0].Specs
+	if !slices.Equal(specs[0].Labels, []string{"book", "length", "slow"}) || specs[0].Pending || !specs[1].Pending {
+		t.Errorf("unexpected specs %+v %+v", specs[0], specs[1])
+	}
+	if !slices.Equal(plan.Labels, []string{"book", "length", "slow"}) {
+		t.Errorf("unexpected labels %v", plan.Labels)
+	}
+
+	rec, err := ReconcileReports(plan, []string{inventory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.NeverExecuted) != 0 || len(rec.Unresolved) != 0 || len(rec.AlwaysSkipped) != 1 {
+		t.Errorf("the specs of the inventory were not matched with the report %s", rec)
+	}
+}
@@ -0,0 +1,217 @@
+package testspecs
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+// RerunResult holds the states of a failed spec in the previous run followed by its reruns
+type RerunResult struct {
+	Spec   string
+	States []string
+	// Flaky is set when the spec passed in one of the reruns
+	Flaky bool
+	// NotRerun is set when the spec was not executed by any of the reruns
+	NotRerun bool
+}
+
+// FailedSpecs returns the It nodes which failed in the report. With orderedSiblings, the other specs of the
+// Ordered containers of the failed specs are returned too, since they depend on the specs run before them.
+func FailedSpecs(report types.Report, orderedSiblings bool) types.SpecReports {
+
+	ordered := orderedContainers{}
+	var specs types.SpecReports
+	for _, sr := range report.SpecReports {
+		if sr.LeafNodeType != types.NodeTypeIt || !sr.State.Is(types.SpecStateFailureStates) {
+			continue
+		}
+		rerun := types.SpecReports{sr}
+		if orderedSiblings && sr.IsInOrderedContainer {
+			rerun = orderedContainerSpecs(report, sr, ordered)
+		}
+		for _, s := range rerun {
+			if !slices.ContainsFunc(specs, func(spec types.SpecReport) bool { return sameSpec(spec, s) }) {
+				specs = append(specs, s)
+			}
+		}
+	}
+	return specs
+}
+
+// orderedContainers tells whether the containers are Ordered from the files declaring them, keyed by file name,
+// since the report only tells whether a spec is in an Ordered container. The files which can't be parsed have no lines.
+type orderedContainers map[string]map[int]bool
+
+func (o orderedContainers) isOrdered(location types.CodeLocation) bool {
+
+	lines, ok := o[location.FileName]
+	if !ok {
+		lines, _ = orderedContainerLines(location.FileName)
+		o[location.FileName] = lines
+	}
+	return lines[location.LineNumber]
+}
+
+// key returns the key of the Ordered container of the spec, the outermost of its containers declared Ordered, whose
+// specs are run serially after its BeforeAll nodes. When none is declared Ordered in the files, i.e. the decorator is
+// passed through a function like the framework describes or the files aren't there, it is the innermost container.
+// The specs which are not in an Ordered container have no key.
+func (o orderedContainers) key(sr types.SpecReport) string {
+
+	if !sr.IsInOrderedContainer {
+		return ""
+	}
+	depth := min(len(sr.ContainerHierarchyTexts), len(sr.ContainerHierarchyLocations))
+	for i, location := range sr.ContainerHierarchyLocations[:depth] {
+		if o.isOrdered(location) {
+			depth = i + 1
+			break
+		}
+	}
+	// the containers generated in a loop have the same location but different texts
+	var key strings.Builder
+	for i := range depth {
+		location := sr.ContainerHierarchyLocations[i]
+		fmt.Fprintf(&key, "%s:%d %s\n", location.FileName, location.LineNumber, sr.ContainerHierarchyTexts[i])
+	}
+	return key.String()
+}
+
+// orderedContainerSpecs returns the specs of the Ordered container of the spec
+func orderedContainerSpecs(report types.Report, spec types.SpecReport, ordered orderedContainers) types.SpecReports {
+
+	key := ordered.key(spec)
+	var specs types.SpecReports
+	for _, sr := range report.SpecReports {
+		if sr.LeafNodeType == types.NodeTypeIt && ordered.key(sr) == key {
+			specs = append(specs, sr)
+		}
+	}
+	return specs
+}
+
+// sameSpec returns whether both reports are the ones of the same spec
+func sameSpec(a, b types.SpecReport) bool {
+
+	return a.FullText() == b.FullText() &&
+		a.LeafNodeLocation.FileName == b.LeafNodeLocation.FileName &&
+		a.LeafNodeLocation.LineNumber == b.LeafNodeLocation.LineNumber
+}
+
+// FocusFiles returns the `ginkgo --focus-file` filters matching the specs by the locations of their It nodes, one filter
+// per file listing the lines of its specs. Unlike their texts, which can be generated at runtime, the locations of the specs
+// are the same in every process of the suite. The other specs declared at the same lines, i.e. in a loop, are matched too.
+func FocusFiles(specs types.SpecReports) ([]string, error) {
+
+	lines := map[string][]int{}
+	for _, sr := range specs {
+		location := sr.LeafNodeLocation
+		if location.FileName == "" || strings.Contains(location.FileName, ":") {
+			return nil, fmt.Errorf("location %q of spec %q can't be used as a focus file filter", location.FileName, sr.FullText())
+		}
+		if !slices.Contains(lines[location.FileName], location.LineNumber) {
+			lines[location.FileName] = append(lines[location.FileName], location.LineNumber)
+		}
+	}
+
+	var filters []string
+	for _, file := range slices.Sorted(maps.Keys(lines)) {
+		var fileLines []string
+		for _, line := range slices.Sorted(slices.Values(lines[file])) {
+			fileLines = append(fileLines, strconv.Itoa(line))
+		}
+		filters = append(filters, "^"+regexp.QuoteMeta(file)+"$:"+strings.Join(fileLines, ","))
+	}
+	return filters, nil
+}
+
+// leafLocation returns the location of the It node of the spec, i.e. `books.go:12`
+func leafLocation(sr types.SpecReport) string {
+
+	return fmt.Sprintf("%s:%d", sr.LeafNodeLocation.FileName, sr.LeafNodeLocation.LineNumber)
+}
+
+// sharedLocations returns the locations of the It nodes declaring several specs in one of the reports, i.e. in a loop
+func sharedLocations(reports ...types.Report) map[string]bool {
+
+	shared := map[string]bool{}
+	for _, report := range reports {
+		specs := map[string]int{}
+		for _, sr := range report.SpecReports {
+			if sr.LeafNodeType == types.NodeTypeIt {
+				specs[leafLocation(sr)]++
+			}
+		}
+		for location, n := range specs {
+			shared[location] = shared[location] || n > 1
+		}
+	}
+	return shared
+}
+
+// sameSpecOfProcesses returns whether both reports, written by different processes of the suite, are the ones of the same
+// spec. The texts of the specs can be generated at runtime, so they are only compared for the specs sharing their location.
+func sameSpecOfProcesses(a, b types.SpecReport, shared map[string]bool) bool {
+
+	return leafLocation(a) == leafLocation(b) && (!shared[leafLocation(a)] || a.FullText() == b.FullText())
+}
+
+// MergeRerunReports merges the reruns into the previous report. As with `ginkgo --flake-attempts`,
+// a spec executed in the reruns passes when one of its attempts passed, the attempts are added
+// to its NumAttempts. The results of the specs which failed in the previous report are returned,
+// the specs are matched by their locations since their texts can differ between the runs.
+func MergeRerunReports(previous types.Report, reruns []types.Report) (types.Report, []RerunResult) {
+
+	shared := sharedLocations(append([]types.Report{previous}, reruns...)...)
+	merged := previous
+	merged.SpecReports = slices.Clone(previous.SpecReports)
+	merged.SuiteSucceeded = true
+	var results []RerunResult
+	for i, sr := range merged.SpecReports {
+		var attempts types.SpecReports
+		for _, rerun := range reruns {
+			for _, rr := range rerun.SpecReports {
+				if rr.LeafNodeType == sr.LeafNodeType && sameSpecOfProcesses(rr, sr, shared) && !rr.State.Is(types.SpecStateSkipped|types.SpecStatePending) {
+					attempts = append(attempts, rr)
+				}
+			}
+		}
+
+		if len(attempts) != 0 {
+			latest := attempts[len(attempts)-1]
+			if j := slices.IndexFunc(attempts, func(rr types.SpecReport) bool { return rr.State == types.SpecStatePassed }); j != -1 {
+				latest = attempts[j]
+			}
+			latest.NumAttempts = max(sr.NumAttempts, 1)
+			for _, rr := range attempts {
+				latest.NumAttempts += max(rr.NumAttempts, 1)
+			}
+			merged.SpecReports[i] = latest
+		}
+
+		if sr.LeafNodeType == types.NodeTypeIt && sr.State.Is(types.SpecStateFailureStates) {
+			result := RerunResult{Spec: sr.FullText(), States: []string{sr.State.String()}, NotRerun: len(attempts) == 0}
+			for _, rr := range attempts {
+				result.States = append(result.States, rr.State.String())
+				result.Flaky = result.Flaky || rr.State == types.SpecStatePassed
+			}
+			results = append(results, result)
+		}
+
+		if merged.SpecReports[i].State.Is(types.SpecStateFailureStates) {
+			merged.SuiteSucceeded = false
+		}
+	}
+	if len(reruns) != 0 {
+		merged.EndTime = reruns[len(reruns)-1].EndTime
+		merged.RunTime = merged.EndTime.Sub(merged.StartTime)
+	}
+
+	return merged, results
+}
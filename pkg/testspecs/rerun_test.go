@@ -0,0 +1,170 @@
+package testspecs
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+func rerunSpecReport(leaf string, line int, state types.SpecState, ordered bool) types.SpecReport {
+	sr := types.SpecReport{
+		ContainerHierarchyTexts:     []string{"[book-suite Book service E2E tests]"},
+		ContainerHierarchyLocations: []types.CodeLocation{{FileName: "books.go", LineNumber: 1}},
+		LeafNodeType:                types.NodeTypeIt,
+		LeafNodeText:                leaf,
+		LeafNodeLocation:            types.CodeLocation{FileName: "books.go", LineNumber: line},
+		State:                       state,
+		NumAttempts:                 1,
+	}
+	if ordered {
+		sr.ContainerHierarchyTexts = append(sr.ContainerHierarchyTexts, "Categorizing (book) length")
+		sr.ContainerHierarchyLocations = append(sr.ContainerHierarchyLocations, types.CodeLocation{FileName: "books.go", LineNumber: 10})
+		sr.IsInOrderedContainer = true
+	}
+	return sr
+}
+
+func rerunReport(specs ...types.SpecReport) types.Report {
+	return types.Report{SuiteDescription: "E2E", SpecReports: append(types.SpecReports{
+		{LeafNodeType: types.NodeTypeBeforeSuite, State: types.SpecStatePassed, NumAttempts: 1},
+	}, specs...)}
+}
+
+func leafTexts(specs types.SpecReports) []string {
+	var texts []string
+	for _, sr := range specs {
+		texts = append(texts, sr.LeafNodeText)
+	}
+	return texts
+}
+
+func TestFailedSpecs(t *testing.T) {
+	report := rerunReport(
+		rerunSpecReport("counts the pages", 11, types.SpecStatePassed, true),
+		rerunSpecReport("is a novel", 12, types.SpecStateFailed, true),
+		rerunSpecReport("is not a short story", 13, types.SpecStateSkipped, true),
+		rerunSpecReport("has a title", 20, types.SpecStateTimedout, false),
+		rerunSpecReport("has an author", 21, types.SpecStatePassed, false),
+	)
+
+	if got := leafTexts(FailedSpecs(report, false)); !slices.Equal(got, []string{"is a novel", "has a title"}) {
+		t.Errorf("unexpected failed specs %v", got)
+	}
+	withSiblings := FailedSpecs(report, true)
+	if got := leafTexts(withSiblings); !slices.Equal(got, []string{"counts the pages", "is a novel", "is not a short story", "has a title"}) {
+		t.Errorf("unexpected failed specs with their ordered siblings %v", got)
+	}
+
+	focusFiles, err := FocusFiles(withSiblings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(focusFiles, []string{`^books\.go$:11,12,13,20`}) {
+		t.Errorf("unexpected focus files %v", focusFiles)
+	}
+	filters, err := types.ParseFileFilters(focusFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sr := range report.SpecReports[1:] {
+		matched := filters.Matches(append(slices.Clone(sr.ContainerHierarchyLocations), sr.LeafNodeLocation))
+		if expected := sr.LeafNodeText != "has an author"; matched != expected {
+			t.Errorf("focus files %v matched %q: %t", focusFiles, sr.FullText(), matched)
+		}
+	}
+
+	invalid := rerunSpecReport("has a title", 20, types.SpecStateFailed, false)
+	invalid.LeafNodeLocation.FileName = "C:/books.go"
+	if _, err := FocusFiles(types.SpecReports{invalid}); err == nil {
+		t.Errorf("expected an error for a location which can't be used as a focus file filter")
+	}
+}
+
+func TestMergeRerunReportsGeneratedTexts(t *testing.T) {
+	// the text of the spec is generated at runtime, the specs at line 30 are generated in a loop
+	previous := rerunReport(
+		rerunSpecReport("builds symlink-component-abcd", 20, types.SpecStateFailed, false),
+		rerunSpecReport("builds docker-build", 30, types.SpecStateFailed, false),
+		rerunSpecReport("builds fbc-builder", 30, types.SpecStatePassed, false),
+		rerunSpecReport("has an author", 40, types.SpecStateFailed, false),
+	)
+	rerun := rerunReport(
+		rerunSpecReport("builds symlink-component-wxyz", 20, types.SpecStatePassed, false),
+		rerunSpecReport("builds docker-build", 30, types.SpecStateFailed, false),
+		rerunSpecReport("builds fbc-builder", 30, types.SpecStatePassed, false),
+		rerunSpecReport("has an author", 40, types.SpecStateSkipped, false),
+	)
+
+	merged, results := MergeRerunReports(previous, []types.Report{rerun})
+
+	if symlink := merged.SpecReports[1]; symlink.State != types.SpecStatePassed || symlink.NumAttempts != 2 {
+		t.Errorf("spec %q should pass after 2 attempts, got %s after %d", symlink.LeafNodeText, symlink.State, symlink.NumAttempts)
+	}
+	if fbc := merged.SpecReports[3]; fbc.LeafNodeText != "builds fbc-builder" || fbc.NumAttempts != 2 {
+		t.Errorf("the specs generated in a loop should be matched by their texts, got %q after %d", fbc.LeafNodeText, fbc.NumAttempts)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected the results of the 3 failed specs, got %+v", results)
+	}
+	if !results[0].Flaky || results[0].NotRerun {
+		t.Errorf("unexpected result %+v", results[0])
+	}
+	if results[1].Flaky || !slices.Equal(results[1].States, []string{"failed", "failed"}) {
+		t.Errorf("unexpected result %+v", results[1])
+	}
+	if !results[2].NotRerun || !slices.Equal(results[2].States, []string{"failed"}) {
+		t.Errorf("the spec skipped by the rerun should not be reported as still failing, got %+v", results[2])
+	}
+}
+
+func TestMergeRerunReports(t *testing.T) {
+	previous := rerunReport(
+		rerunSpecReport("is a novel", 12, types.SpecStateFailed, true),
+		rerunSpecReport("has a title", 20, types.SpecStateFailed, false),
+		rerunSpecReport("has an author", 21, types.SpecStatePassed, false),
+	)
+	previous.StartTime = time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	reruns := []types.Report{
+		rerunReport(
+			rerunSpecReport("is a novel", 12, types.SpecStateFailed, true),
+			rerunSpecReport("has a title", 20, types.SpecStatePassed, false),
+			rerunSpecReport("has an author", 21, types.SpecStateSkipped, false),
+		),
+		rerunReport(
+			rerunSpecReport("is a novel", 12, types.SpecStateFailed, true),
+			rerunSpecReport("has a title", 20, types.SpecStateFailed, false),
+			rerunSpecReport("has an author", 21, types.SpecStateSkipped, false),
+		),
+	}
+	reruns[1].EndTime = previous.StartTime.Add(2 * time.Hour)
+
+	merged, results := MergeRerunReports(previous, reruns)
+
+	if merged.SuiteSucceeded {
+		t.Errorf("the suite should fail since %q is still failing", "is a novel")
+	}
+	if merged.RunTime != 2*time.Hour {
+		t.Errorf("unexpected run time %s", merged.RunTime)
+	}
+	if title := merged.SpecReports[2]; title.State != types.SpecStatePassed || title.NumAttempts != 3 {
+		t.Errorf("spec %q should pass after 3 attempts, got %s after %d", title.LeafNodeText, title.State, title.NumAttempts)
+	}
+	if author := merged.SpecReports[3]; author.State != types.SpecStatePassed || author.NumAttempts != 1 {
+		t.Errorf("spec %q was not rerun, got %s after %d", author.LeafNodeText, author.State, author.NumAttempts)
+	}
+	if previous.SpecReports[2].State != types.SpecStateFailed {
+		t.Errorf("the previous report was modified")
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected the results of the 2 failed specs, got %+v", results)
+	}
+	if results[0].Flaky || !slices.Equal(results[0].States, []string{"failed", "failed", "failed"}) {
+		t.Errorf("unexpected result %+v", results[0])
+	}
+	if !results[1].Flaky || !slices.Equal(results[1].States, []string{"failed", "passed", "failed"}) {
+		t.Errorf("unexpected result %+v", results[1])
+	}
+}
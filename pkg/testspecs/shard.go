@@ -0,0 +1,249 @@
+package testspecs
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+// defaultSpecDuration is the duration of the specs when there is no history to balance the shards with
+const defaultSpecDuration = time.Minute
+
+// Shard is a set of specs run by a single CI job
+type Shard struct {
+	Index int
+	Specs types.SpecReports
+	// Duration is the expected duration of the specs based on their previous runs
+	Duration time.Duration
+	// FocusFiles are the `ginkgo --focus-file` filters selecting the specs, see FocusFiles
+	FocusFiles []string
+}
+
+// ParseShard parses the index and total of a shard, i.e. `2/4` is the second of four shards
+func ParseShard(shard string) (int, int, error) {
+
+	index, total, ok := strings.Cut(shard, "/")
+	k, err := strconv.Atoi(strings.TrimSpace(index))
+	if !ok || err != nil {
+		return 0, 0, fmt.Errorf("invalid shard %q, expected <index>/<total>", shard)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(total))
+	if err != nil || n < 1 || k < 1 || k > n {
+		return 0, 0, fmt.Errorf("invalid shard %q, expected <index>/<total> with 1 <= index <= total", shard)
+	}
+	return k, n, nil
+}
+
+// ShardSpecs splits the specs selected in the inventory, i.e. the report written by `E2E_SPECS_INVENTORY=<file> ginkgo --dry-run ./cmd`,
+// into total shards balanced by the durations of their previous runs. The specs of an Ordered container are kept in the same shard,
+// so are the specs declared at the same location since the shards select their specs by location. The specs without a previous
+// run are expected to last the median duration of the other specs. The split is deterministic, so every CI job computes the same
+// shards from the same inventory and history. An error is returned when a spec is not selected by exactly one shard.
+func ShardSpecs(inventory types.Report, history []SpecRun, total int) ([]Shard, error) {
+
+	var selected types.SpecReports
+	for _, sr := range inventory.SpecReports {
+		if sr.LeafNodeType == types.NodeTypeIt && !sr.State.Is(types.SpecStateSkipped|types.SpecStatePending) {
+			selected = append(selected, sr)
+		}
+	}
+	durations := averageDurations(history)
+
+	// the specs sharing their location or their Ordered container are a single unit
+	ordered := orderedContainers{}
+	unitOf := make([]int, len(selected))
+	firstOf := map[string]int{}
+	for i, sr := range selected {
+		unitOf[i] = i
+		for _, key := range []string{leafLocation(sr), ordered.key(sr)} {
+			j, ok := firstOf[key]
+			switch {
+			case key == "":
+			case !ok:
+				firstOf[key] = i
+			default:
+				// merge the unit of the spec into the one of the first spec with the key
+				from, to := unitOf[i], unitOf[j]
+				for k := range unitOf[:i+1] {
+					if unitOf[k] == from {
+						unitOf[k] = to
+					}
+				}
+			}
+		}
+	}
+	var units []types.SpecReports
+	indexes := map[int]int{}
+	for i, sr := range selected {
+		u, ok := indexes[unitOf[i]]
+		if !ok {
+			u = len(units)
+			indexes[unitOf[i]] = u
+			units = append(units, nil)
+		}
+		units[u] = append(units[u], sr)
+	}
+
+	weights := make([]time.Duration, len(units))
+	order := make([]int, len(units))
+	for i, unit := range units {
+		order[i] = i
+		for _, sr := range unit {
+			weights[i] += durations.of(sr.FullText())
+		}
+	}
+	// the longest units first, each to the shard expected to end the soonest
+	sort.SliceStable(order, func(a, b int) bool {
+		if weights[order[a]] != weights[order[b]] {
+			return weights[order[a]] > weights[order[b]]
+		}
+		return units[order[a]][0].FullText() < units[order[b]][0].FullText()
+	})
+
+	shards := make([]Shard, total)
+	for i := range shards {
+		shards[i].Index = i + 1
+	}
+	for _, u := range order {
+		next := 0
+		for i := range shards {
+			if shards[i].Duration < shards[next].Duration {
+				next = i
+			}
+		}
+		shards[next].Specs = append(shards[next].Specs, units[u]...)
+		shards[next].Duration += weights[u]
+	}
+
+	for i := range shards {
+		focusFiles, err := FocusFiles(shards[i].Specs)
+		if err != nil {
+			return nil, err
+		}
+		shards[i].FocusFiles = focusFiles
+	}
+	if err := verifyShards(selected, shards); err != nil {
+		return nil, err
+	}
+	return shards, nil
+}
+
+// verifyShards returns an error when one of the specs is not selected by the focus files of exactly one shard,
+// ginkgo matches the filters against the locations of the containers of the spec and of its It node
+func verifyShards(specs types.SpecReports, shards []Shard) error {
+
+	filters := make([]types.FileFilters, len(shards))
+	for i, shard := range shards {
+		var err error
+		if filters[i], err = types.ParseFileFilters(shard.FocusFiles); err != nil {
+			return fmt.Errorf("invalid focus files of shard %d: %w", shard.Index, err)
+		}
+	}
+	for _, sr := range specs {
+		locations := append(slices.Clone(sr.ContainerHierarchyLocations), sr.LeafNodeLocation)
+		var selectedBy []int
+		for i, shard := range shards {
+			if filters[i].Matches(locations) {
+				selectedBy = append(selectedBy, shard.Index)
+			}
+		}
+		if len(selectedBy) != 1 {
+			return fmt.Errorf("spec %q at %s is selected by the shards %v instead of a single one", sr.FullText(), leafLocation(sr), selectedBy)
+		}
+	}
+	return nil
+}
+
+// specDurations are the average durations of the executed runs of the specs
+type specDurations struct {
+	average map[string]time.Duration
+	median  time.Duration
+}
+
+func averageDurations(history []SpecRun) specDurations {
+
+	sums := map[string]time.Duration{}
+	counts := map[string]int{}
+	for _, run := range history {
+		if run.State == types.SpecStateSkipped.String() || run.State == types.SpecStatePending.String() {
+			continue
+		}
+		sums[run.Spec] += run.Duration
+		counts[run.Spec]++
+	}
+
+	d := specDurations{average: map[string]time.Duration{}, median: defaultSpecDuration}
+	var all []time.Duration
+	for spec, sum := range sums {
+		d.average[spec] = sum / time.Duration(counts[spec])
+		all = append(all, d.average[spec])
+	}
+	if len(all) != 0 {
+		slices.Sort(all)
+		d.median = all[len(all)/2]
+	}
+	return d
+}
+
+func (d specDurations) of(spec string) time.Duration {
+
+	if duration, ok := d.average[spec]; ok {
+		return duration
+	}
+	return d.median
+}
+
+// MergeShardReports merges the reports of the shards into a single report. Every shard reports all the specs,
+// the ones of the other shards being skipped, so a spec is taken from the shard which executed it. The specs
+// are matched by their locations since their texts can differ between the shards.
+// The suite nodes, i.e. SynchronizedBeforeSuite, ran in every shard and are all kept.
+func MergeShardReports(reports []types.Report) (types.Report, error) {
+
+	if len(reports) == 0 {
+		return types.Report{}, fmt.Errorf("no shard report to merge")
+	}
+
+	merged := reports[0]
+	merged.SpecReports = nil
+	merged.SuiteSucceeded = true
+	merged.SpecialSuiteFailureReasons = nil
+	shared := sharedLocations(reports...)
+	var specs types.SpecReports
+	for _, report := range reports {
+		if report.StartTime.Before(merged.StartTime) {
+			merged.StartTime = report.StartTime
+		}
+		if report.EndTime.After(merged.EndTime) {
+			merged.EndTime = report.EndTime
+		}
+		merged.SuiteSucceeded = merged.SuiteSucceeded && report.SuiteSucceeded
+		for _, reason := range report.SpecialSuiteFailureReasons {
+			if !slices.Contains(merged.SpecialSuiteFailureReasons, reason) {
+				merged.SpecialSuiteFailureReasons = append(merged.SpecialSuiteFailureReasons, reason)
+			}
+		}
+
+		for _, sr := range report.SpecReports {
+			if sr.LeafNodeType != types.NodeTypeIt {
+				merged.SpecReports = append(merged.SpecReports, sr)
+				continue
+			}
+			i := slices.IndexFunc(specs, func(s types.SpecReport) bool { return sameSpecOfProcesses(s, sr, shared) })
+			switch {
+			case i == -1:
+				specs = append(specs, sr)
+			case specs[i].State.Is(types.SpecStateSkipped) && !sr.State.Is(types.SpecStateSkipped):
+				specs[i] = sr
+			}
+		}
+	}
+	merged.SpecReports = append(merged.SpecReports, specs...)
+	merged.RunTime = merged.EndTime.Sub(merged.StartTime)
+
+	return merged, nil
+}
@@ -0,0 +1,249 @@
+package testspecs
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+func TestParseShard(t *testing.T) {
+	if k, n, err := ParseShard("2/4"); err != nil || k != 2 || n != 4 {
+		t.Errorf("unexpected shard %d/%d: %v", k, n, err)
+	}
+	for _, shard := range []string{"", "2", "0/4", "5/4", "a/b", "1/0"} {
+		if _, _, err := ParseShard(shard); err == nil {
+			t.Errorf("expected an error parsing %q", shard)
+		}
+	}
+}
+
+// shardInventory is the dry run report of the specs: an Ordered container of three specs,
+// four other specs and a spec filtered out by the label filter
+func shardInventory() types.Report {
+	return types.Report{SuiteDescription: "E2E", SpecReports: types.SpecReports{
+		{LeafNodeType: types.NodeTypeBeforeSuite, State: types.SpecStatePassed},
+		rerunSpecReport("counts the pages", 11, types.SpecStatePassed, true),
+		rerunSpecReport("is a novel", 12, types.SpecStatePassed, true),
+		rerunSpecReport("is not a short story", 13, types.SpecStatePassed, true),
+		rerunSpecReport("has a title", 20, types.SpecStatePassed, false),
+		rerunSpecReport("has an author", 21, types.SpecStatePassed, false),
+		rerunSpecReport("has a cover", 22, types.SpecStatePassed, false),
+		rerunSpecReport("has an index", 23, types.SpecStatePassed, false),
+		rerunSpecReport("is filtered out", 24, types.SpecStateSkipped, false),
+	}}
+}
+
+func shardHistory(durations map[string]time.Duration) []SpecRun {
+	var runs []SpecRun
+	for _, sr := range shardInventory().SpecReports {
+		if d, ok := durations[sr.LeafNodeText]; ok {
+			// the average of the runs is the duration
+			runs = append(runs,
+				SpecRun{Spec: sr.FullText(), State: "passed", Duration: d / 2},
+				SpecRun{Spec: sr.FullText(), State: "failed", Duration: d * 3 / 2},
+				SpecRun{Spec: sr.FullText(), State: "skipped"},
+			)
+		}
+	}
+	return runs
+}
+
+func TestShardSpecs(t *testing.T) {
+	history := shardHistory(map[string]time.Duration{
+		"counts the pages":     2 * time.Minute,
+		"is a novel":           4 * time.Minute,
+		"is not a short story": 2 * time.Minute,
+		"has a title":          6 * time.Minute,
+		"has an author":        4 * time.Minute,
+		"has a cover":          2 * time.Minute,
+	})
+
+	shards, err := ShardSpecs(shardInventory(), history, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shards) != 3 {
+		t.Fatalf("expected 3 shards, got %d", len(shards))
+	}
+	expected := []struct {
+		specs      []string
+		duration   time.Duration
+		focusFiles []string
+	}{
+		// the Ordered container lasts 8m, the index without history the median 4m
+		{[]string{"counts the pages", "is a novel", "is not a short story"}, 8 * time.Minute, []string{`^books\.go$:11,12,13`}},
+		{[]string{"has a title", "has a cover"}, 8 * time.Minute, []string{`^books\.go$:20,22`}},
+		{[]string{"has an author", "has an index"}, 8 * time.Minute, []string{`^books\.go$:21,23`}},
+	}
+	for i, shard := range shards {
+		if shard.Index != i+1 || !slices.Equal(leafTexts(shard.Specs), expected[i].specs) || shard.Duration != expected[i].duration ||
+			!slices.Equal(shard.FocusFiles, expected[i].focusFiles) {
+			t.Errorf("unexpected shard %d: %v lasting %s focused by %v", shard.Index, leafTexts(shard.Specs), shard.Duration, shard.FocusFiles)
+		}
+	}
+
+	if again, _ := ShardSpecs(shardInventory(), history, 3); !slices.EqualFunc(again, shards, func(a, b Shard) bool {
+		return slices.Equal(leafTexts(a.Specs), leafTexts(b.Specs))
+	}) {
+		t.Errorf("the shards are not deterministic")
+	}
+
+	// the Ordered container and three other specs last 3m, the last spec goes to the first shard
+	withoutHistory, err := ShardSpecs(shardInventory(), nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := leafTexts(withoutHistory[0].Specs); !slices.Equal(got, []string{"counts the pages", "is a novel", "is not a short story", "has an index"}) {
+		t.Errorf("unexpected first shard without history %v", got)
+	}
+	if withoutHistory[1].Duration != 3*defaultSpecDuration {
+		t.Errorf("unexpected duration of the second shard without history %s", withoutHistory[1].Duration)
+	}
+
+	if shards, err := ShardSpecs(shardInventory(), history, 9); err != nil || len(shards[8].Specs) != 0 || len(shards[8].FocusFiles) != 0 {
+		t.Errorf("expected the last shard to be empty, got %v: %v", leafTexts(shards[8].Specs), err)
+	}
+}
+
+func TestShardSpecsByLocation(t *testing.T) {
+	// the specs generated in a loop share their location, so they are selected together by the focus files
+	inventory := rerunReport(
+		rerunSpecReport("builds docker-build", 30, types.SpecStatePassed, false),
+		rerunSpecReport("has a title", 20, types.SpecStatePassed, false),
+		rerunSpecReport("builds fbc-builder", 30, types.SpecStatePassed, false),
+	)
+	shards, err := ShardSpecs(inventory, nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := leafTexts(shards[0].Specs); !slices.Equal(got, []string{"builds docker-build", "builds fbc-builder"}) {
+		t.Errorf("expected the specs at the same location in the first shard, got %v", got)
+	}
+
+	invalid := rerunSpecReport("has a title", 20, types.SpecStatePassed, false)
+	invalid.LeafNodeLocation.FileName = ""
+	if _, err := ShardSpecs(rerunReport(invalid), nil, 2); err == nil {
+		t.Errorf("expected an error for a spec which can't be selected by its location")
+	}
+
+	// a spec whose container is declared at the line of a spec of another shard is selected by both shards
+	nested := rerunSpecReport("has a subtitle", 40, types.SpecStatePassed, false)
+	nested.ContainerHierarchyLocations = append(nested.ContainerHierarchyLocations, types.CodeLocation{FileName: "books.go", LineNumber: 20})
+	err = verifyShards(types.SpecReports{nested}, []Shard{
+		{Index: 1, FocusFiles: []string{`^books\.go$:20`}},
+		{Index: 2, FocusFiles: []string{`^books\.go$:40`}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "selected by the shards [1 2]") {
+		t.Errorf("expected an error for the spec selected by both shards, got %v", err)
+	}
+	if err := verifyShards(types.SpecReports{nested}, []Shard{{Index: 1}}); err == nil {
+		t.Errorf("expected an error for the spec selected by no shard")
+	}
+}
+
+func TestShardSpecsOrderedContainers(t *testing.T) {
+	// the Ordered containers are told from the file declaring them
+	file := filepath.Join(t.TempDir(), "library.go")
+	source := `package library
+
+var _ = Describe("Library", func() {
+	Describe("Lending books", Ordered, func() {
+		It("lends a book", func() {})
+		It("returns the book", func() {})
+	})
+	Describe("Shelving books", ginkgo.Ordered, func() {
+		It("shelves a book", func() {})
+		Context("with a ladder", Ordered, func() {
+			It("shelves the top shelf", func() {})
+		})
+	})
+})
+`
+	if err := os.WriteFile(file, []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	spec := func(leaf string, line int, containers ...int) types.SpecReport {
+		sr := types.SpecReport{LeafNodeType: types.NodeTypeIt, LeafNodeText: leaf, IsInOrderedContainer: true,
+			LeafNodeLocation: types.CodeLocation{FileName: file, LineNumber: line}, State: types.SpecStatePassed}
+		for _, container := range containers {
+			sr.ContainerHierarchyTexts = append(sr.ContainerHierarchyTexts, strconv.Itoa(container))
+			sr.ContainerHierarchyLocations = append(sr.ContainerHierarchyLocations, types.CodeLocation{FileName: file, LineNumber: container})
+		}
+		return sr
+	}
+	inventory := rerunReport(
+		spec("lends a book", 5, 3, 4),
+		spec("returns the book", 6, 3, 4),
+		spec("shelves a book", 9, 3, 8),
+		spec("shelves the top shelf", 11, 3, 8, 10),
+	)
+
+	// the sibling Ordered containers of the non Ordered container are two units, the nested Ordered container is in the outer one
+	shards, err := ShardSpecs(inventory, nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := leafTexts(shards[0].Specs); !slices.Equal(got, []string{"lends a book", "returns the book"}) {
+		t.Errorf("unexpected first shard %v", got)
+	}
+	if got := leafTexts(shards[1].Specs); !slices.Equal(got, []string{"shelves a book", "shelves the top shelf"}) {
+		t.Errorf("unexpected second shard %v", got)
+	}
+
+	inventory.SpecReports[3].State = types.SpecStateFailed
+	if got := leafTexts(FailedSpecs(inventory, true)); !slices.Equal(got, []string{"shelves a book", "shelves the top shelf"}) {
+		t.Errorf("unexpected specs of the Ordered container of the failed spec %v", got)
+	}
+}
+
+func TestMergeShardReports(t *testing.T) {
+	start := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	first := shardInventory()
+	first.StartTime, first.EndTime, first.SuiteSucceeded = start, start.Add(time.Hour), true
+	second := shardInventory()
+	second.StartTime, second.EndTime, second.SuiteSucceeded = start.Add(time.Minute), start.Add(2*time.Hour), false
+	second.SpecialSuiteFailureReasons = []string{"Interrupted by Timeout"}
+	for i := range first.SpecReports[1:] {
+		if i < 4 {
+			second.SpecReports[i+1].State = types.SpecStateSkipped
+		} else {
+			first.SpecReports[i+1].State = types.SpecStateSkipped
+		}
+	}
+	second.SpecReports[6].State = types.SpecStateFailed
+
+	merged, err := MergeShardReports([]types.Report{first, second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.SuiteSucceeded || merged.RunTime != 2*time.Hour || !slices.Equal(merged.SpecialSuiteFailureReasons, []string{"Interrupted by Timeout"}) {
+		t.Errorf("unexpected merged suite %t %s %v", merged.SuiteSucceeded, merged.RunTime, merged.SpecialSuiteFailureReasons)
+	}
+	if len(merged.SpecReports) != 10 {
+		t.Fatalf("expected the 2 suite nodes and the 8 specs, got %d", len(merged.SpecReports))
+	}
+	var states []string
+	for _, sr := range merged.SpecReports[2:] {
+		states = append(states, sr.State.String())
+	}
+	if expected := []string{"passed", "passed", "passed", "passed", "passed", "failed", "passed", "skipped"}; !slices.Equal(states, expected) {
+		t.Errorf("expected the states %v, got %v", expected, states)
+	}
+
+	// the text of the spec is generated at runtime by every shard
+	first.SpecReports[1].ContainerHierarchyTexts = []string{"symlink-component-abcd"}
+	second.SpecReports[1].ContainerHierarchyTexts = []string{"symlink-component-wxyz"}
+	if merged, _ := MergeShardReports([]types.Report{first, second}); len(merged.SpecReports) != 10 || merged.SpecReports[2].State != types.SpecStatePassed {
+		t.Errorf("expected the spec with a generated text to be merged, got %d specs", len(merged.SpecReports))
+	}
+
+	if _, err := MergeShardReports(nil); err == nil {
+		t.Errorf("expected an error without reports")
+	}
+}
@@ -0,0 +1,45 @@
+package books
+
+import (
+	"github.com/konflux-ci/e2e-tests/pkg/framework"
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = framework.BookSuiteDescribe("Book service E2E tests", Label("book"), func() {
+
+	defer GinkgoRecover()
+
+	Describe("Categorizing book length", Ordered, Label("length"), func() {
+		BeforeAll(func() {
+			By("creating the library")
+		})
+
+		When("the book has more than 300 pages", Label(slowLabel), func() {
+			It("should be a novel", func() {
+				By("counting the pages: all of them")
+				Expect(true).To(BeTrue())
+			})
+		})
+
+		AfterAll(func() {})
+	})
+
+	ginkgo.Describe("Creating bookmarks in a book", ginkgo.Serial, ginkgo.Label("bookmark", "parallel"), func() {
+		BeforeEach(OncePerOrdered, func() {})
+
+		It("has no bookmarks by default", Pending, func() {})
+		PIt("can add bookmarks")
+	})
+
+	DescribeTable("Reading invalid books always errors", Label("table"),
+		func(title string, pages int) {
+			By("reading the book")
+			Expect(title).NotTo(BeEmpty())
+		},
+		Entry("Empty book", "", 0),
+		Entry("Only title", Label("title"), "title", 0),
+		FEntry("Missing pages", "title", 0),
+	)
+})
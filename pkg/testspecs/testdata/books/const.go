@@ -0,0 +1,5 @@
+package books
+
+const (
+	slowLabel = "slow"
+)
@@ -0,0 +1,5 @@
+package release
+
+const (
+	fbcReleaseLabel = "fbc-release"
+)
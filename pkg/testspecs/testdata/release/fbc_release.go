@@ -0,0 +1,16 @@
+package release
+
+import (
+	"github.com/konflux-ci/e2e-tests/pkg/framework"
+	"github.com/onsi/ginkgo/v2"
+)
+
+var _ = framework.ReleasePipelinesSuiteDescribe("FBC e2e-tests", ginkgo.Pending, ginkgo.Label("release-pipelines", fbcReleaseLabel), func() {
+
+	defer ginkgo.GinkgoRecover()
+
+	ginkgo.Describe("with FBC happy path", ginkgo.Label("fbcHappyPath"), func() {
+		ginkgo.It("verifies the fbc release pipelinerun is running and succeeds", func() {})
+		ginkgo.It("verifies release CR completed and set succeeded", func() {})
+	})
+})
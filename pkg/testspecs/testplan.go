@@ -0,0 +1,412 @@
+package testspecs
+
+import (
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+	"k8s.io/klog/v2"
+)
+
+// NoLabel groups the specs which don't have any label
+const NoLabel = "(no label)"
+
+// TestPlan is the outline of the specs of all the test suites,
+// built to be rendered as a browsable Markdown or HTML test plan
+type TestPlan struct {
+	Suites []*SuitePlan
+	// Labels are all the labels of the specs, sorted
+	Labels []string
+	// Report is the ginkgo JSON report the specs were annotated with
+	Report string
+}
+
+// SuitePlan holds the specs of a framework describe decorator
+// function, i.e. BuildSuiteDescribe, which can span several files
+type SuitePlan struct {
+	Name    string
+	Files   []string
+	Outline TestOutline
+	Specs   []*PlannedSpec
+}
+
+// PlannedSpec is a single It or Entry node of the outline
+type PlannedSpec struct {
+	// Hierarchy are the texts of the containers of the spec, starting with the framework describe
+	Hierarchy []string
+	Text      string
+	// Labels are the labels of the spec including the ones inherited from its containers
+	Labels  []string
+	Pending bool
+	Result  *SpecResult
+}
+
+// SpecResult is the outcome of a spec found in a ginkgo JSON report
+type SpecResult struct {
+	State    string
+	Duration time.Duration
+}
+
+// LabelGroup are the specs of a suite with the given label
+type LabelGroup struct {
+	Label string
+	Specs []*PlannedSpec
+}
+
+// MatrixRow holds the number of specs with the label for every suite of the plan
+type MatrixRow struct {
+	Label string
+	Cells []MatrixCell
+}
+
+// MatrixCell counts the specs of a suite with a label and their results
+type MatrixCell struct {
+	Specs   int
+	Passed  int
+	Failed  int
+	Skipped int
+}
+
+// NewTestPlan walks the Ginkgo spec files of the tests directory and
+// graphs the specs of every framework describe decorator function
+func NewTestPlan(testsDir string) (*TestPlan, error) {
+
+	gs := NewGinkgoSpecTranslator()
+	suites := map[string]*SuitePlan{}
+	err := walkGinkgoSpecFiles(testsDir, func(path string) error {
+		node, err := ExtractFrameworkDescribeNode(path)
+		if err != nil {
+			return err
+		}
+		if reflect.ValueOf(node).IsZero() {
+			return nil
+		}
+		outline, err := gs.FromFile(path)
+		if err != nil {
+			klog.Errorf("failed to map ginkgo spec %s to outline: %s", path, err)
+			return err
+		}
+
+		suite, ok := suites[node.Name]
+		if !ok {
+			suite = &SuitePlan{Name: node.Name}
+			suites[node.Name] = suite
+		}
+		suite.Files = append(suite.Files, path)
+		suite.Outline = append(suite.Outline, outline...)
+		for _, root := range outline {
+			suite.Specs = append(suite.Specs, collectPlannedSpecs(root, nil, nil, false)...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newTestPlan(suites), nil
+}
+
+// NewTestPlanFromReport graphs the specs of a ginkgo JSON report, i.e. the one written by the
+// dry run of the cmd package, `E2E_SPECS_INVENTORY=specs.json ginkgo --dry-run ./cmd`. Unlike
+// the outline of the spec files it holds the texts of the specs generated at runtime.
+// The suites are named after the texts of the top level containers.
+func NewTestPlanFromReport(reportFile string) (*TestPlan, error) {
+
+	reports, err := LoadGinkgoReports(reportFile)
+	if err != nil {
+		return nil, err
+	}
+
+	suites := map[string]*SuitePlan{}
+	for _, report := range reports {
+		for _, sr := range report.SpecReports {
+			if sr.LeafNodeType != types.NodeTypeIt || len(sr.ContainerHierarchyTexts) == 0 {
+				continue
+			}
+			name := sr.ContainerHierarchyTexts[0]
+			suite, ok := suites[name]
+			if !ok {
+				suite = &SuitePlan{Name: name}
+				suites[name] = suite
+			}
+			if file := sr.LeafNodeLocation.FileName; !slices.Contains(suite.Files, file) {
+				suite.Files = append(suite.Files, file)
+			}
+			spec := &PlannedSpec{Hierarchy: sr.ContainerHierarchyTexts, Text: sr.LeafNodeText, Pending: sr.State == types.SpecStatePending}
+			for _, l := range sr.Labels() {
+				if !slices.Contains(spec.Labels, l) {
+					spec.Labels = append(spec.Labels, l)
+				}
+			}
+			suite.Specs = append(suite.Specs, spec)
+		}
+	}
+
+	return newTestPlan(suites), nil
+}
+
+// newTestPlan returns the plan of the suites sorted by their names together with the labels of their specs
+func newTestPlan(suites map[string]*SuitePlan) *TestPlan {
+
+	plan := &TestPlan{}
+	for _, suite := range suites {
+		plan.Suites = append(plan.Suites, suite)
+		for _, spec := range suite.Specs {
+			for _, l := range spec.Labels {
+				if !slices.Contains(plan.Labels, l) {
+					plan.Labels = append(plan.Labels, l)
+				}
+			}
+		}
+	}
+	sort.Slice(plan.Suites, func(i, j int) bool { return plan.Suites[i].Name < plan.Suites[j].Name })
+	sort.Strings(plan.Labels)
+
+	return plan
+}
+
+// walkGinkgoSpecFiles calls fn for every Go file of the tests directory,
+// test files and testdata directories are skipped
+func walkGinkgoSpecFiles(testsDir string, fn func(path string) error) error {
+
+	return filepath.WalkDir(testsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != testsDir && (d.Name() == "testdata" || d.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".go" || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		return fn(path)
+	})
+}
+
+// collectPlannedSpecs returns the It and Entry nodes of the outline
+// with the labels and pending state inherited from their containers
+func collectPlannedSpecs(node TestSpecNode, hierarchy, labels []string, pending bool) []*PlannedSpec {
+
+	labels = append(slices.Clone(labels), node.Labels...)
+	pending = pending || slices.Contains(node.Decorators, "Pending") ||
+		(slices.Contains(ginkgoSpecNodes, node.Name) && strings.IndexAny(node.Name, "PX") == 0)
+
+	switch strings.TrimLeft(node.Name, "FPX") {
+	case "It", "Specify", "Entry":
+		spec := &PlannedSpec{Hierarchy: hierarchy, Text: node.Text, Pending: pending}
+		for _, l := range labels {
+			if !slices.Contains(spec.Labels, l) {
+				spec.Labels = append(spec.Labels, l)
+			}
+		}
+		return []*PlannedSpec{spec}
+	case "By":
+		return nil
+	}
+
+	var specs []*PlannedSpec
+	hierarchy = append(slices.Clone(hierarchy), node.Text)
+	for _, n := range node.Nodes {
+		specs = append(specs, collectPlannedSpecs(n, hierarchy, labels, pending)...)
+	}
+	return specs
+}
+
+// AnnotateWithReport sets the result of the specs found in the ginkgo JSON report,
+// i.e. generated by `ginkgo --json-report`
+func (tp *TestPlan) AnnotateWithReport(reportFile string) error {
+
+	reports, err := LoadGinkgoReports(reportFile)
+	if err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		for _, sr := range report.SpecReports {
+			if sr.LeafNodeType != types.NodeTypeIt || len(sr.ContainerHierarchyTexts) == 0 {
+				continue
+			}
+			for _, suite := range tp.Suites {
+				for _, spec := range suite.Specs {
+					if spec.matches(sr) {
+						spec.Result = &SpecResult{State: sr.State.String(), Duration: sr.RunTime}
+					}
+				}
+			}
+		}
+	}
+	tp.Report = reportFile
+
+	return nil
+}
+
+// LoadGinkgoReports reads the reports of a ginkgo JSON report file
+func LoadGinkgoReports(reportFile string) ([]types.Report, error) {
+
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		return nil, err
+	}
+	var reports []types.Report
+	if err := json.Unmarshal(data, &reports); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ginkgo JSON report %s: %w", reportFile, err)
+	}
+	return reports, nil
+}
+
+// matches returns whether the spec report is the one of the spec, the text of the
+// framework describe is decorated by its function, i.e. `[build-service-suite <text>]`
+func (ps *PlannedSpec) matches(sr types.SpecReport) bool {
+
+	texts := sr.ContainerHierarchyTexts
+	if len(texts) != len(ps.Hierarchy) || sr.LeafNodeText != ps.Text {
+		return false
+	}
+	if texts[0] != ps.Hierarchy[0] && !strings.HasSuffix(strings.TrimSuffix(texts[0], "]"), " "+ps.Hierarchy[0]) {
+		return false
+	}
+	return slices.Equal(texts[1:], ps.Hierarchy[1:])
+}
+
+// Path returns the texts of the containers and of the spec
+func (ps *PlannedSpec) Path() string {
+
+	return strings.Join(append(slices.Clone(ps.Hierarchy[1:]), ps.Text), " > ")
+}
+
+// LabelGroups returns the specs of the suite grouped by their labels, a spec
+// with several labels is part of each of their groups
+func (sp *SuitePlan) LabelGroups() []LabelGroup {
+
+	var groups []LabelGroup
+	index := map[string]int{}
+	for _, spec := range sp.Specs {
+		labels := spec.Labels
+		if len(labels) == 0 {
+			labels = []string{NoLabel}
+		}
+		for _, l := range labels {
+			i, ok := index[l]
+			if !ok {
+				i = len(groups)
+				index[l] = i
+				groups = append(groups, LabelGroup{Label: l})
+			}
+			groups[i].Specs = append(groups[i].Specs, spec)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Label < groups[j].Label })
+
+	return groups
+}
+
+// Matrix returns the label × suite coverage matrix of the plan
+func (tp *TestPlan) Matrix() []MatrixRow {
+
+	var rows []MatrixRow
+	for _, l := range tp.Labels {
+		row := MatrixRow{Label: l}
+		for _, suite := range tp.Suites {
+			var cell MatrixCell
+			for _, spec := range suite.Specs {
+				if !slices.Contains(spec.Labels, l) {
+					continue
+				}
+				cell.Specs++
+				if spec.Result == nil {
+					continue
+				}
+				switch spec.Result.State {
+				case types.SpecStatePassed.String():
+					cell.Passed++
+				case types.SpecStateSkipped.String(), types.SpecStatePending.String():
+					cell.Skipped++
+				default:
+					cell.Failed++
+				}
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// String renders the cell as the number of specs followed by their results, if any
+func (mc MatrixCell) String() string {
+
+	if mc.Specs == 0 {
+		return ""
+	}
+	if mc.Passed+mc.Failed+mc.Skipped == 0 {
+		return fmt.Sprint(mc.Specs)
+	}
+	return fmt.Sprintf("%d (%d passed, %d failed, %d skipped)", mc.Specs, mc.Passed, mc.Failed, mc.Skipped)
+}
+
+// RenderTestPlan renders the test plan into test-plan.md and test-plan.html in the destination directory
+func RenderTestPlan(destDir string, plan *TestPlan) error {
+
+	err := os.MkdirAll(destDir, 0775)
+	if err != nil {
+		klog.Errorf("failed to create test plan directory, %s", destDir)
+		return err
+	}
+	for destination, templatePath := range map[string]string{
+		"test-plan.md":   TestPlanMarkdownPath,
+		"test-plan.html": TestPlanHTMLPath,
+	} {
+		err = renderReportTemplate(filepath.Join(destDir, destination), templatePath, plan)
+		if err != nil {
+			klog.Errorf("failed to render the test plan %s: %s", destination, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderReportTemplate renders a report template, i.e. the test plan, HTML templates
+// are rendered with html/template so that the texts of the specs are escaped
+func renderReportTemplate(destination, templatePath string, data any) error {
+
+	tpl, err := os.ReadFile(templatePath)
+	if err != nil {
+		return err
+	}
+
+	var tmpl interface {
+		Execute(w io.Writer, data any) error
+	}
+	if filepath.Ext(destination) == ".html" {
+		tmpl, err = htmltemplate.New("report").Parse(string(tpl))
+	} else {
+		tmpl, err = template.New("report").Parse(string(tpl))
+	}
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return tmpl.Execute(f, data)
+}
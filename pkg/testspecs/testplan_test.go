@@ -0,0 +1,175 @@
+package testspecs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+func TestNewTestPlan(t *testing.T) {
+	plan, err := NewTestPlan(booksSuiteDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Suites) != 1 || plan.Suites[0].Name != "BookSuiteDescribe" {
+		t.Fatalf("expected the BookSuiteDescribe suite, got %+v", plan.Suites)
+	}
+	expectedLabels := []string{"book", "bookmark", "length", "parallel", "slow", "table", "title"}
+	if !slices.Equal(plan.Labels, expectedLabels) {
+		t.Errorf("expected labels %v, got %v", expectedLabels, plan.Labels)
+	}
+
+	specs := plan.Suites[0].Specs
+	if len(specs) != 6 {
+		t.Fatalf("expected 6 specs, got %d", len(specs))
+	}
+	novel := specs[0]
+	if novel.Path() != "Categorizing book length > the book has more than 300 pages > should be a novel" {
+		t.Errorf("unexpected path %q", novel.Path())
+	}
+	if !slices.Equal(novel.Labels, []string{"book", "length", "slow"}) {
+		t.Errorf("labels of the containers were not inherited, got %v", novel.Labels)
+	}
+	if !specs[1].Pending || !specs[2].Pending || specs[3].Pending {
+		t.Errorf("unexpected pending specs %+v %+v %+v", specs[1], specs[2], specs[3])
+	}
+	if !slices.Equal(specs[4].Labels, []string{"book", "table", "title"}) {
+		t.Errorf("labels of the entry were not inherited, got %v", specs[4].Labels)
+	}
+
+	matrix := plan.Matrix()
+	if len(matrix) != len(expectedLabels) || matrix[0].Label != "book" || matrix[0].Cells[0].Specs != 6 {
+		t.Errorf("unexpected matrix %+v", matrix)
+	}
+}
+
+func TestNewTestPlanQualifiedLabels(t *testing.T) {
+	plan, err := NewTestPlan("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Suites) != 2 || plan.Suites[1].Name != "ReleasePipelinesSuiteDescribe" {
+		t.Fatalf("expected the BookSuiteDescribe and ReleasePipelinesSuiteDescribe suites, got %+v", plan.Suites)
+	}
+	specs := plan.Suites[1].Specs
+	if len(specs) != 2 {
+		t.Fatalf("expected 2 specs, got %d", len(specs))
+	}
+	// the labels of the qualified ginkgo.Label of the framework describe are inherited, as its Pending decorator
+	if !slices.Equal(specs[0].Labels, []string{"release-pipelines", "fbc-release", "fbcHappyPath"}) || !specs[0].Pending {
+		t.Errorf("labels and pending state of the framework describe were not inherited, got %+v", specs[0])
+	}
+
+	rows := map[string][]int{}
+	for _, row := range plan.Matrix() {
+		for _, cell := range row.Cells {
+			rows[row.Label] = append(rows[row.Label], cell.Specs)
+		}
+	}
+	for label, expected := range map[string][]int{
+		"book":              {6, 0},
+		"release-pipelines": {0, 2},
+		"fbc-release":       {0, 2},
+		"fbcHappyPath":      {0, 2},
+	} {
+		if !slices.Equal(rows[label], expected) {
+			t.Errorf("expected the matrix row %s to be %v, got %v", label, expected, rows[label])
+		}
+	}
+}
+
+func TestAnnotateWithReport(t *testing.T) {
+	plan, err := NewTestPlan(booksSuiteDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports := []types.Report{{SpecReports: types.SpecReports{
+		{
+			ContainerHierarchyTexts: []string{"[book-suite Book service E2E tests]", "Categorizing book length", "the book has more than 300 pages"},
+			LeafNodeType:            types.NodeTypeIt,
+			LeafNodeText:            "should be a novel",
+			State:                   types.SpecStatePassed,
+			RunTime:                 2 * time.Second,
+		},
+		{
+			ContainerHierarchyTexts: []string{"[book-suite Book service E2E tests]", "Reading invalid books always errors"},
+			LeafNodeType:            types.NodeTypeIt,
+			LeafNodeText:            "Empty book",
+			State:                   types.SpecStateFailed,
+		},
+		{
+			LeafNodeType: types.NodeTypeBeforeSuite,
+			State:        types.SpecStatePassed,
+		},
+	}}}
+	data, err := json.Marshal(reports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+	if err := os.WriteFile(reportFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := plan.AnnotateWithReport(reportFile); err != nil {
+		t.Fatal(err)
+	}
+	specs := plan.Suites[0].Specs
+	if specs[0].Result == nil || specs[0].Result.State != "passed" || specs[0].Result.Duration != 2*time.Second {
+		t.Errorf("unexpected result of %q: %+v", specs[0].Text, specs[0].Result)
+	}
+	if specs[3].Result == nil || specs[3].Result.State != "failed" {
+		t.Errorf("unexpected result of %q: %+v", specs[3].Text, specs[3].Result)
+	}
+	if specs[1].Result != nil {
+		t.Errorf("spec %q is not in the report, got %+v", specs[1].Text, specs[1].Result)
+	}
+	if cell := plan.Matrix()[0].Cells[0]; cell.String() != "6 (1 passed, 1 failed, 0 skipped)" {
+		t.Errorf("unexpected matrix cell %q", cell.String())
+	}
+}
+
+func TestRenderTestPlan(t *testing.T) {
+	plan, err := NewTestPlan(booksSuiteDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	destDir := t.TempDir()
+	t.Chdir(filepath.Join("..", ".."))
+
+	if err := RenderTestPlan(destDir, plan); err != nil {
+		t.Fatal(err)
+	}
+
+	markdown, err := os.ReadFile(filepath.Join(destDir, "test-plan.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"| Label | BookSuiteDescribe |",
+		"| slow | 1 |",
+		"#### title\n\n- Reading invalid books always errors > Only title\n",
+		"- Creating bookmarks in a book > can add bookmarks _(pending)_",
+	} {
+		if !strings.Contains(string(markdown), expected) {
+			t.Errorf("markdown test plan does not contain %q:\n%s", expected, markdown)
+		}
+	}
+
+	html, err := os.ReadFile(filepath.Join(destDir, "test-plan.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(html), `<h3 id="BookSuiteDescribe">BookSuiteDescribe</h3>`) {
+		t.Errorf("html test plan does not contain the suite:\n%s", html)
+	}
+}
@@ -40,10 +40,18 @@ func (tst *TextSpecTranslator) FromFile(file string) (TestOutline, error) {
 	for _, part := range strings.Split(outlineStr, "\n") {
 		if !strings.Contains("\n", part) {
 			node := createTestSpecNodesFromString(part)
+			if node.Name == "" {
+				continue
+			}
 			outline = graphNodeToTestSpecOutline(outline, node)
 		}
 
 	}
+	// the first node is the framework describe decorator function
+	// so mark its children as we do for Ginkgo spec files
+	for i := range outline {
+		markInnerParentContainer(outline[i].Nodes)
+	}
 
 	return outline, nil
 }
@@ -83,27 +91,38 @@ func createTestSpecNodesFromString(part string) TestSpecNode {
 	outlineSlice := strings.Split(part, "\n")
 	var node TestSpecNode
 	for _, o := range outlineSlice {
-		        // replace the carriage return if spec outline was initially generated from Windows/GDoc
-		        o := strings.ReplaceAll(o, "\r", "")
-		        whiteSpaces := len(o) - len(strings.TrimLeft(o, " "))
-		        //skip over empty new lines
-		        if whiteSpaces == 0 && len(o) == 0 {
-		            continue
-		        }
-		
-		        node = TestSpecNode{}
-		        node.Name = strings.Trim(strings.Split(o, ":")[0], " ")
-		        txt := strings.Trim(strings.Split(o, ":")[1], " ")
-		        if strings.Contains(txt, "@") {
-		            labels := strings.Split(txt, "@")[1:]
-		            for _, l := range labels {
+		// replace the carriage return if spec outline was initially generated from Windows/GDoc
+		o := strings.ReplaceAll(o, "\r", "")
+		whiteSpaces := len(o) - len(strings.TrimLeft(o, " "))
+		//skip over empty new lines
+		if len(strings.TrimSpace(o)) == 0 {
+			continue
+		}
+
+		node = TestSpecNode{}
+		// split on the first delimiter only so the text can contain `:`
+		key, txt, _ := strings.Cut(o, ":")
+		node.Name = strings.Trim(key, " ")
+		// decorators are listed within parentheses after the name, i.e. `Describe (Ordered, Serial):`
+		if name, decorators, ok := strings.Cut(node.Name, "("); ok {
+			node.Name = strings.Trim(name, " ")
+			for _, d := range strings.Split(strings.TrimSuffix(decorators, ")"), ",") {
+				if d = strings.Trim(d, " "); d != "" {
+					node.Decorators = append(node.Decorators, d)
+				}
+			}
+		}
+		txt = strings.Trim(txt, " ")
+		if strings.Contains(txt, "@") {
+			labels := strings.Split(txt, "@")[1:]
+			for _, l := range labels {
 				noCommaL := strings.ReplaceAll(l, ",", "")
 				node.Labels = append(node.Labels, strings.TrimRight(strings.TrimLeft(noCommaL, " "), " "))
 				if strings.HasPrefix(l, "@") {
 					node.Labels = append(node.Labels, strings.TrimLeft(l[1:], " "))
 				}
 			}
-			txt = strings.Split(txt, "@")[0]
+			txt = strings.TrimRight(strings.Split(txt, "@")[0], " ")
 		}
 
 		node.Text = txt
@@ -0,0 +1,35 @@
+package testspecs
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestTextSpecTranslatorFromFile(t *testing.T) {
+	outline, err := NewGinkgoSpecTranslator().FromFile(booksSpecFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	textFile := filepath.Join(t.TempDir(), "books.outline")
+	tst := NewTextSpecTranslator()
+	if err := tst.ToFile(textFile, outline); err != nil {
+		t.Fatal(err)
+	}
+	textOutline, err := tst.FromFile(textFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := textOutline.ToString(); got != booksOutline {
+		t.Errorf("text outline does not match the outline of the Ginkgo spec file, got:\n%s", got)
+	}
+	describe := textOutline[0].Nodes[0]
+	if describe.Name != "Describe" || len(describe.Decorators) != 1 || describe.Decorators[0] != "Ordered" {
+		t.Errorf("decorators of %+v were not parsed", describe)
+	}
+	by := describe.Nodes[1].Nodes[0].Nodes[0]
+	if by.Text != "counting the pages: all of them" {
+		t.Errorf("text containing the delimiter was truncated to %q", by.Text)
+	}
+}
@@ -15,6 +15,7 @@ type TestSpecNode struct {
 	Name                 string
 	Text                 string
 	Labels               []string
+	Decorators           []string
 	Nodes                TestOutline
 	InnerParentContainer bool
 	LineSpaceLevel       int
@@ -51,13 +52,12 @@ func recursiveNodeStringBuilder(nodes TestOutline, printWidth int) string {
 			}
 			labels = strings.Join(annotate, ", ")
 		}
-		if labels != "" {
-
-			nodeString := fmt.Sprintf("\n%*s%s: %+v %+v", printWidth, "", n.Name, n.Text, labels)
-			b.WriteString(nodeString)
-		} else {
-			b.WriteString(fmt.Sprintf("\n%*s%s: %+v", printWidth, "", n.Name, n.Text))
+		name := n.Name
+		if len(n.Decorators) != 0 {
+			name = fmt.Sprintf("%s (%s)", n.Name, strings.Join(n.Decorators, ", "))
 		}
+		nodeString := strings.TrimRight(fmt.Sprintf("%s: %+v %+v", name, n.Text, labels), " ")
+		b.WriteString(fmt.Sprintf("\n%*s%s", printWidth, "", nodeString))
 
 		if len(n.Nodes) != 0 {
 			printWidth += 2
@@ -26,20 +26,64 @@ const (
 )
 
 type SbomCyclonedx struct {
-	BomFormat   string
-	SpecVersion string
-	Version     int
-	Components  []CyclonedxComponent `json:"components"`
+	BomFormat    string
+	SpecVersion  string
+	Version      int
+	Metadata     CyclonedxMetadata     `json:"metadata"`
+	Components   []CyclonedxComponent  `json:"components"`
+	Dependencies []CyclonedxDependency `json:"dependencies"`
+}
+
+type CyclonedxMetadata struct {
+	Timestamp string              `json:"timestamp"`
+	Tools     CyclonedxTools      `json:"tools"`
+	Component *CyclonedxComponent `json:"component"`
+}
+
+// CyclonedxTools supports both the legacy array form (spec < 1.5) and the object form of metadata.tools.
+type CyclonedxTools struct {
+	Components []CyclonedxComponent `json:"components"`
+	Legacy     []CyclonedxComponent `json:"-"`
+}
+
+func (t *CyclonedxTools) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, &t.Legacy)
+	}
+	type tools CyclonedxTools
+	return json.Unmarshal(data, (*tools)(t))
+}
+
+// Count returns the number of tools regardless of the form they were declared in.
+func (t *CyclonedxTools) Count() int {
+	return len(t.Components) + len(t.Legacy)
 }
 
 type CyclonedxComponent struct {
+	BomRef     string              `json:"bom-ref"`
 	Name       string              `json:"name"`
 	Purl       string              `json:"purl"`
 	Type       string              `json:"type"`
 	Version    string              `json:"version"`
+	Licenses   []CyclonedxLicense  `json:"licenses"`
 	Properties []CyclonedxProperty `json:"properties"`
 }
 
+type CyclonedxLicense struct {
+	License    *CyclonedxLicenseInfo `json:"license"`
+	Expression string                `json:"expression"`
+}
+
+type CyclonedxLicenseInfo struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type CyclonedxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
 type CyclonedxProperty struct {
 	Name  string `json:"name"`
 	Value string `json:"value"`
@@ -75,16 +119,35 @@ func (c *CyclonedxComponent) GetCreatedBy() SbomPackageCreatedBy {
 }
 
 type SbomSpdx struct {
-	SPDXID      string        `json:"SPDXID"`
-	SpdxVersion string        `json:"spdxVersion"`
-	Packages    []SpdxPackage `json:"packages"`
+	SPDXID            string             `json:"SPDXID"`
+	SpdxVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      SpdxCreationInfo   `json:"creationInfo"`
+	Packages          []SpdxPackage      `json:"packages"`
+	Relationships     []SpdxRelationship `json:"relationships"`
+}
+
+type SpdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
 }
 
 type SpdxPackage struct {
-	Name         string            `json:"name"`
-	VersionInfo  string            `json:"versionInfo"`
-	ExternalRefs []SpdxExternalRef `json:"externalRefs"`
-	Annotations  []SpdxAnnotation  `json:"annotations"`
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	ExternalRefs     []SpdxExternalRef `json:"externalRefs"`
+	Annotations      []SpdxAnnotation  `json:"annotations"`
+}
+
+type SpdxRelationship struct {
+	SpdxElementId      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSpdxElement string `json:"relatedSpdxElement"`
 }
 
 type SpdxExternalRef struct {
@@ -0,0 +1,465 @@
+package build
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	supportedCyclonedxSpecVersions = []string{"1.4", "1.5", "1.6"}
+	supportedSpdxVersions          = []string{"SPDX-2.2", "SPDX-2.3"}
+
+	purlTypePattern          = regexp.MustCompile(`^[a-zA-Z.+-][a-zA-Z0-9.+-]*$`)
+	purlQualifierKeyPattern  = regexp.MustCompile(`^[a-zA-Z.\-_][a-zA-Z0-9.\-_]*$`)
+	syftLayerIDProperty      = regexp.MustCompile(`^syft:location:\d+:layerID$`)
+	licenseExpressionPattern = regexp.MustCompile(`^[A-Za-z0-9.\-+:]+( (AND|OR|WITH|and|or|with) [A-Za-z0-9.\-+:]+)*$`)
+)
+
+// ValidateSbomDocument checks the document-level requirements of the given SBOM: supported spec version,
+// metadata about the tools which generated it, consistent component relationships, valid license fields
+// and syntactically valid purls. All found issues are returned joined in a single error.
+func ValidateSbomDocument(sbom Sbom) error {
+	var errs []error
+	switch s := sbom.(type) {
+	case *SbomCyclonedx:
+		errs = validateCyclonedx(s)
+	case *SbomSpdx:
+		errs = validateSpdx(s)
+	default:
+		return fmt.Errorf("unsupported SBOM type %T", sbom)
+	}
+
+	for _, pkg := range sbom.GetPackages() {
+		if pkg.GetPurl() == "" {
+			continue
+		}
+		if err := ValidatePurl(pkg.GetPurl()); err != nil {
+			errs = append(errs, fmt.Errorf("package %s: %w", pkg.GetName(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateCyclonedx(s *SbomCyclonedx) []error {
+	var errs []error
+	if s.BomFormat != "CycloneDX" {
+		errs = append(errs, fmt.Errorf("unexpected bomFormat '%s'", s.BomFormat))
+	}
+	if !slices.Contains(supportedCyclonedxSpecVersions, s.SpecVersion) {
+		errs = append(errs, fmt.Errorf("unsupported CycloneDX specVersion '%s', expected one of %v", s.SpecVersion, supportedCyclonedxSpecVersions))
+	}
+	if s.Metadata.Tools.Count() == 0 {
+		errs = append(errs, fmt.Errorf("metadata.tools is empty"))
+	}
+	if s.Metadata.Timestamp != "" {
+		if _, err := time.Parse(time.RFC3339, s.Metadata.Timestamp); err != nil {
+			errs = append(errs, fmt.Errorf("metadata.timestamp '%s' is not a valid RFC 3339 timestamp", s.Metadata.Timestamp))
+		}
+	}
+
+	refs := map[string]bool{}
+	if s.Metadata.Component != nil && s.Metadata.Component.BomRef != "" {
+		refs[s.Metadata.Component.BomRef] = true
+	}
+	for _, c := range s.Components {
+		if c.Name == "" {
+			errs = append(errs, fmt.Errorf("component with bom-ref '%s' has no name", c.BomRef))
+		}
+		if c.BomRef != "" {
+			if refs[c.BomRef] {
+				errs = append(errs, fmt.Errorf("duplicate bom-ref '%s'", c.BomRef))
+			}
+			refs[c.BomRef] = true
+		}
+		for _, l := range c.Licenses {
+			switch {
+			case l.Expression != "":
+				if !isValidLicenseExpression(l.Expression) {
+					errs = append(errs, fmt.Errorf("component %s has invalid license expression '%s'", c.Name, l.Expression))
+				}
+			case l.License == nil || (l.License.ID == "" && l.License.Name == ""):
+				errs = append(errs, fmt.Errorf("component %s has a license without id, name or expression", c.Name))
+			}
+		}
+	}
+	for _, d := range s.Dependencies {
+		if !refs[d.Ref] {
+			errs = append(errs, fmt.Errorf("dependency references unknown bom-ref '%s'", d.Ref))
+		}
+		for _, dependsOn := range d.DependsOn {
+			if !refs[dependsOn] {
+				errs = append(errs, fmt.Errorf("dependency '%s' depends on unknown bom-ref '%s'", d.Ref, dependsOn))
+			}
+		}
+	}
+	return errs
+}
+
+func validateSpdx(s *SbomSpdx) []error {
+	var errs []error
+	if !slices.Contains(supportedSpdxVersions, s.SpdxVersion) {
+		errs = append(errs, fmt.Errorf("unsupported spdxVersion '%s', expected one of %v", s.SpdxVersion, supportedSpdxVersions))
+	}
+	if s.SPDXID != "SPDXRef-DOCUMENT" {
+		errs = append(errs, fmt.Errorf("unexpected document SPDXID '%s'", s.SPDXID))
+	}
+	if s.DataLicense != "CC0-1.0" {
+		errs = append(errs, fmt.Errorf("dataLicense must be CC0-1.0, got '%s'", s.DataLicense))
+	}
+	if s.Name == "" {
+		errs = append(errs, fmt.Errorf("document name is empty"))
+	}
+	if s.DocumentNamespace == "" {
+		errs = append(errs, fmt.Errorf("documentNamespace is empty"))
+	}
+	if _, err := time.Parse(time.RFC3339, s.CreationInfo.Created); err != nil {
+		errs = append(errs, fmt.Errorf("creationInfo.created '%s' is not a valid RFC 3339 timestamp", s.CreationInfo.Created))
+	}
+	hasTool := false
+	for _, creator := range s.CreationInfo.Creators {
+		if strings.HasPrefix(creator, "Tool:") {
+			hasTool = true
+		}
+	}
+	if !hasTool {
+		errs = append(errs, fmt.Errorf("creationInfo.creators doesn't contain any tool"))
+	}
+
+	ids := map[string]bool{s.SPDXID: true}
+	for _, p := range s.Packages {
+		if p.SPDXID == "" {
+			errs = append(errs, fmt.Errorf("package %s has no SPDXID", p.Name))
+			continue
+		}
+		if ids[p.SPDXID] {
+			errs = append(errs, fmt.Errorf("duplicate SPDXID '%s'", p.SPDXID))
+		}
+		ids[p.SPDXID] = true
+		for field, license := range map[string]string{"licenseConcluded": p.LicenseConcluded, "licenseDeclared": p.LicenseDeclared} {
+			if license != "" && !isValidLicenseExpression(license) {
+				errs = append(errs, fmt.Errorf("package %s has invalid %s '%s'", p.Name, field, license))
+			}
+		}
+	}
+
+	describes := false
+	for _, r := range s.Relationships {
+		if r.SpdxElementId == s.SPDXID && r.RelationshipType == "DESCRIBES" {
+			describes = true
+		}
+		for _, id := range []string{r.SpdxElementId, r.RelatedSpdxElement} {
+			if !ids[id] && id != "NOASSERTION" && id != "NONE" && !strings.HasPrefix(id, "DocumentRef-") {
+				errs = append(errs, fmt.Errorf("relationship %s references unknown SPDXID '%s'", r.RelationshipType, id))
+			}
+		}
+	}
+	if len(s.Packages) > 0 && !describes {
+		errs = append(errs, fmt.Errorf("document doesn't DESCRIBE any package"))
+	}
+	return errs
+}
+
+// ValidatePurl checks that the given string is a syntactically valid package URL,
+// see https://github.com/package-url/purl-spec/blob/master/PURL-SPECIFICATION.rst
+func ValidatePurl(purl string) error {
+	rest, found := strings.CutPrefix(purl, "pkg:")
+	if !found {
+		return fmt.Errorf("purl '%s' doesn't start with 'pkg:'", purl)
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, qualifiers, hasQualifiers := strings.Cut(rest, "?")
+	if hasQualifiers {
+		for _, q := range strings.Split(qualifiers, "&") {
+			key, value, ok := strings.Cut(q, "=")
+			if !ok || !purlQualifierKeyPattern.MatchString(key) || value == "" {
+				return fmt.Errorf("purl '%s' has invalid qualifier '%s'", purl, q)
+			}
+		}
+	}
+
+	rest = strings.Trim(rest, "/")
+	purlType, path, found := strings.Cut(rest, "/")
+	if !found || !purlTypePattern.MatchString(purlType) {
+		return fmt.Errorf("purl '%s' has invalid type '%s'", purl, purlType)
+	}
+	if i := strings.LastIndex(path, "@"); i >= 0 {
+		if path[i+1:] == "" {
+			return fmt.Errorf("purl '%s' has an empty version", purl)
+		}
+		path = path[:i]
+	}
+	segments := strings.Split(path, "/")
+	name := segments[len(segments)-1]
+	if name == "" {
+		return fmt.Errorf("purl '%s' has an empty name", purl)
+	}
+	for _, segment := range segments {
+		if _, err := url.PathUnescape(segment); err != nil {
+			return fmt.Errorf("purl '%s' has invalid percent-encoding: %w", purl, err)
+		}
+	}
+	return nil
+}
+
+// SbomPackageChange describes a package present in both compared SBOMs with different versions.
+type SbomPackageChange struct {
+	Key           string
+	BaseVersion   string
+	TargetVersion string
+}
+
+// SbomDiff is the difference between a base and a target package list.
+// Added packages are only present in the target, Removed packages only in the base.
+type SbomDiff struct {
+	Added   []string
+	Removed []string
+	Changed []SbomPackageChange
+}
+
+// IsEmpty returns true when both package lists are equal.
+func (d *SbomDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func (d *SbomDiff) String() string {
+	var sb strings.Builder
+	for _, a := range d.Added {
+		fmt.Fprintf(&sb, "+ %s\n", a)
+	}
+	for _, r := range d.Removed {
+		fmt.Fprintf(&sb, "- %s\n", r)
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(&sb, "~ %s: %s -> %s\n", c.Key, c.BaseVersion, c.TargetVersion)
+	}
+	return sb.String()
+}
+
+// DiffSboms compares the packages of two SBOMs, e.g. of a hermetic and a non-hermetic build of the same source.
+// Packages are matched by their purl without version, subpath and qualifiers other than the arch, or by name when
+// they have no purl. A package listed with several versions is compared version by version.
+func DiffSboms(base, target Sbom) *SbomDiff {
+	return diffPackageVersions(sbomPackageVersions(base, packageKey), sbomPackageVersions(target, packageKey))
+}
+
+// packageVersions maps the key of a package to the set of its versions
+type packageVersions map[string]map[string]bool
+
+func (p packageVersions) add(key, version string) {
+	if p[key] == nil {
+		p[key] = map[string]bool{}
+	}
+	p[key][version] = true
+}
+
+func sbomPackageVersions(sbom Sbom, key func(SbomPackage) string) packageVersions {
+	versions := packageVersions{}
+	for _, pkg := range sbom.GetPackages() {
+		if k := key(pkg); k != "" {
+			versions.add(k, pkg.GetVersion())
+		}
+	}
+	return versions
+}
+
+func packageKey(pkg SbomPackage) string {
+	purl := pkg.GetPurl()
+	if purl == "" {
+		return pkg.GetName()
+	}
+	purl, _, _ = strings.Cut(purl, "#")
+	purl, qualifiers, _ := strings.Cut(purl, "?")
+	if i := strings.LastIndex(purl, "@"); i >= 0 {
+		purl = purl[:i]
+	}
+	if arch := purlArch(qualifiers); arch != "" {
+		purl += "?arch=" + arch
+	}
+	return purl
+}
+
+// purlArch returns the value of the arch qualifier of a purl
+func purlArch(qualifiers string) string {
+	for _, qualifier := range strings.Split(qualifiers, "&") {
+		if arch, ok := strings.CutPrefix(qualifier, "arch="); ok {
+			return arch
+		}
+	}
+	return ""
+}
+
+func diffPackageVersions(base, target packageVersions) *SbomDiff {
+	diff := &SbomDiff{}
+	for key, versions := range target {
+		baseVersions := base[key]
+		if len(versions) == 1 && len(baseVersions) == 1 {
+			if baseVersion, version := onlyVersion(baseVersions), onlyVersion(versions); baseVersion != version {
+				diff.Changed = append(diff.Changed, SbomPackageChange{Key: key, BaseVersion: baseVersion, TargetVersion: version})
+			}
+			continue
+		}
+		for version := range versions {
+			if !baseVersions[version] {
+				diff.Added = append(diff.Added, versionedKey(key, version, len(baseVersions) > 0 || len(versions) > 1))
+			}
+		}
+	}
+	for key, versions := range base {
+		targetVersions := target[key]
+		if len(versions) == 1 && len(targetVersions) == 1 {
+			continue
+		}
+		for version := range versions {
+			if !targetVersions[version] {
+				diff.Removed = append(diff.Removed, versionedKey(key, version, len(targetVersions) > 0 || len(versions) > 1))
+			}
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Key < diff.Changed[j].Key })
+	return diff
+}
+
+func onlyVersion(versions map[string]bool) string {
+	for version := range versions {
+		return version
+	}
+	return ""
+}
+
+// versionedKey returns the key of a package with its version, when several versions of the package are listed
+func versionedKey(key, version string, multiple bool) string {
+	if !multiple {
+		return key
+	}
+	return key + "@" + version
+}
+
+// InstalledRpm is an RPM package installed in a container image.
+type InstalledRpm struct {
+	Name    string
+	Version string
+	Arch    string
+}
+
+// rpmQueryFormat is the query format expected by ParseRpmQueryOutput, the version includes the epoch
+// when there is one, as in the SBOMs generated by Syft
+const rpmQueryFormat = `%{NAME}\t%|EPOCH?{%{EPOCH}:}:{}|%{VERSION}-%{RELEASE}\t%{ARCH}\n`
+
+// GetInstalledRpms extracts the given image and lists the RPMs installed in it using the rpm binary of the host.
+func GetInstalledRpms(image string) ([]InstalledRpm, error) {
+	dir, err := ExtractImage(image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract image %s: %w", image, err)
+	}
+	defer os.RemoveAll(dir)
+
+	output, err := exec.Command("rpm", "-qa", "--root", dir, "--qf", rpmQueryFormat).CombinedOutput() // #nosec G204
+	if err != nil {
+		return nil, fmt.Errorf("failed to query RPM database of image %s: %w: %s", image, err, string(output))
+	}
+	return ParseRpmQueryOutput(string(output)), nil
+}
+
+// ParseRpmQueryOutput parses the output of rpm -qa using the rpmQueryFormat query format.
+func ParseRpmQueryOutput(output string) []InstalledRpm {
+	rpms := []InstalledRpm{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) != 3 || fields[0] == "gpg-pubkey" {
+			continue
+		}
+		rpms = append(rpms, InstalledRpm{Name: fields[0], Version: fields[1], Arch: fields[2]})
+	}
+	return rpms
+}
+
+// CompareSbomWithInstalledRpms compares the RPM packages (purl type "rpm") listed in the SBOM with the RPMs
+// installed in the image, matched by name and arch. Added packages are listed in the SBOM but not installed,
+// Removed packages are installed but missing in the SBOM.
+func CompareSbomWithInstalledRpms(sbom Sbom, rpms []InstalledRpm) *SbomDiff {
+	installed := packageVersions{}
+	for _, rpm := range rpms {
+		installed.add(rpmKey(rpm.Name, rpm.Arch), rpm.Version)
+	}
+	listed := sbomPackageVersions(sbom, func(pkg SbomPackage) string {
+		purl, qualifiers, _ := strings.Cut(pkg.GetPurl(), "?")
+		if !strings.HasPrefix(purl, "pkg:rpm/") {
+			return ""
+		}
+		return rpmKey(pkg.GetName(), purlArch(qualifiers))
+	})
+	return diffPackageVersions(installed, listed)
+}
+
+func rpmKey(name, arch string) string {
+	if arch == "" {
+		return name
+	}
+	return name + "." + arch
+}
+
+// ValidateSbomLayers checks that all image layers referenced by the SBOM components exist in the image.
+// Layers are only recorded by Syft in CycloneDX SBOMs, for other SBOMs there is nothing to verify.
+func ValidateSbomLayers(sbom Sbom, image string) error {
+	layerIDs := map[string]bool{}
+	if cdx, ok := sbom.(*SbomCyclonedx); ok {
+		for _, c := range cdx.Components {
+			for _, p := range c.Properties {
+				if syftLayerIDProperty.MatchString(p.Name) {
+					layerIDs[p.Value] = true
+				}
+			}
+		}
+	}
+	if len(layerIDs) == 0 {
+		return nil
+	}
+
+	config, err := FetchImageConfig(image)
+	if err != nil {
+		return err
+	}
+	imageLayers := map[string]bool{}
+	for _, diffID := range config.RootFS.DiffIDs {
+		imageLayers[diffID.String()] = true
+	}
+
+	var errs []error
+	for layerID := range layerIDs {
+		if !imageLayers[layerID] {
+			errs = append(errs, fmt.Errorf("layer %s referenced by the SBOM is not present in image %s", layerID, image))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isValidLicenseExpression does a lightweight syntax check of an SPDX license expression, e.g. "(MIT OR Apache-2.0)"
+func isValidLicenseExpression(expression string) bool {
+	if expression == "NOASSERTION" || expression == "NONE" {
+		return true
+	}
+	depth := 0
+	for _, r := range expression {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth < 0 {
+			return false
+		}
+	}
+	stripped := strings.NewReplacer("(", "", ")", "").Replace(expression)
+	return depth == 0 && licenseExpressionPattern.MatchString(stripped)
+}
@@ -0,0 +1,147 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validCyclonedx = `{
+	"bomFormat": "CycloneDX",
+	"specVersion": "1.5",
+	"metadata": {
+		"timestamp": "2024-05-01T10:00:00Z",
+		"tools": {"components": [{"type": "application", "name": "syft"}]},
+		"component": {"bom-ref": "image", "name": "quay.io/test/image"}
+	},
+	"components": [
+		{"bom-ref": "openssl", "name": "openssl", "version": "3.0.7", "purl": "pkg:rpm/redhat/openssl@3.0.7?arch=x86_64", "licenses": [{"license": {"id": "Apache-2.0"}}]},
+		{"bom-ref": "requests", "name": "requests", "version": "2.31.0", "purl": "pkg:pypi/requests@2.31.0", "licenses": [{"expression": "(MIT OR Apache-2.0)"}]}
+	],
+	"dependencies": [{"ref": "image", "dependsOn": ["openssl", "requests"]}]
+}`
+
+const validSpdx = `{
+	"spdxVersion": "SPDX-2.3",
+	"SPDXID": "SPDXRef-DOCUMENT",
+	"dataLicense": "CC0-1.0",
+	"name": "quay.io/test/image",
+	"documentNamespace": "https://konflux-ci.dev/spdxdocs/image",
+	"creationInfo": {"created": "2024-05-01T10:00:00Z", "creators": ["Tool: syft"]},
+	"packages": [
+		{"SPDXID": "SPDXRef-image", "name": "image", "licenseConcluded": "NOASSERTION"},
+		{"SPDXID": "SPDXRef-openssl", "name": "openssl", "versionInfo": "3.0.8", "licenseDeclared": "Apache-2.0",
+			"externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:rpm/redhat/openssl@3.0.8?arch=x86_64"}]}
+	],
+	"relationships": [
+		{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-image"},
+		{"spdxElementId": "SPDXRef-image", "relationshipType": "CONTAINS", "relatedSpdxElement": "SPDXRef-openssl"}
+	]
+}`
+
+func TestValidatePurl(t *testing.T) {
+	for purl, valid := range map[string]bool{
+		"pkg:rpm/redhat/openssl@3.0.7-1.el9?arch=x86_64":    true,
+		"pkg:golang/github.com/konflux-ci/e2e-tests@v0.0.1": true,
+		"pkg:npm/%40angular/core@16.0.0":                    true,
+		"pkg:pypi/requests":                                 true,
+		"rpm/redhat/openssl@3.0.7":                          false,
+		"pkg:openssl":                                       false,
+		"pkg:rpm/redhat/@3.0.7":                             false,
+		"pkg:rpm/redhat/openssl@":                           false,
+		"pkg:rpm/redhat/openssl@3.0.7?arch":                 false,
+		"pkg:1rpm/redhat/openssl":                           false,
+	} {
+		err := ValidatePurl(purl)
+		if valid {
+			assert.NoError(t, err, purl)
+		} else {
+			assert.Error(t, err, purl)
+		}
+	}
+}
+
+func TestValidateSbomDocument(t *testing.T) {
+	for _, data := range []string{validCyclonedx, validSpdx} {
+		sbom, err := UnmarshalSbom([]byte(data))
+		assert.NoError(t, err)
+		assert.NoError(t, ValidateSbomDocument(sbom))
+	}
+
+	sbom, err := UnmarshalSbom([]byte(`{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.2",
+		"metadata": {"tools": []},
+		"components": [
+			{"bom-ref": "a", "name": "a", "purl": "a@1"},
+			{"bom-ref": "a", "name": "b", "licenses": [{"expression": "(MIT OR"}]}
+		],
+		"dependencies": [{"ref": "a", "dependsOn": ["missing"]}]
+	}`))
+	assert.NoError(t, err)
+	err = ValidateSbomDocument(sbom)
+	assert.ErrorContains(t, err, "unsupported CycloneDX specVersion")
+	assert.ErrorContains(t, err, "metadata.tools is empty")
+	assert.ErrorContains(t, err, "duplicate bom-ref 'a'")
+	assert.ErrorContains(t, err, "invalid license expression")
+	assert.ErrorContains(t, err, "unknown bom-ref 'missing'")
+	assert.ErrorContains(t, err, "doesn't start with 'pkg:'")
+}
+
+func TestCyclonedxLegacyTools(t *testing.T) {
+	sbom, err := UnmarshalSbom([]byte(`{"bomFormat": "CycloneDX", "specVersion": "1.4", "metadata": {"tools": [{"name": "syft"}]}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sbom.(*SbomCyclonedx).Metadata.Tools.Count())
+}
+
+func TestDiffSboms(t *testing.T) {
+	base, err := UnmarshalSbom([]byte(validCyclonedx))
+	assert.NoError(t, err)
+	target, err := UnmarshalSbom([]byte(validSpdx))
+	assert.NoError(t, err)
+
+	diff := DiffSboms(base, target)
+	assert.Equal(t, []string{"image"}, diff.Added)
+	assert.Equal(t, []string{"pkg:pypi/requests"}, diff.Removed)
+	assert.Equal(t, []SbomPackageChange{{Key: "pkg:rpm/redhat/openssl?arch=x86_64", BaseVersion: "3.0.7", TargetVersion: "3.0.8"}}, diff.Changed)
+	assert.True(t, DiffSboms(base, base).IsEmpty())
+}
+
+func TestDiffSbomsMultiVersionPackages(t *testing.T) {
+	base, err := UnmarshalSbom([]byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": [
+		{"name": "glibc", "version": "2.34-100", "purl": "pkg:rpm/redhat/glibc@2.34-100?arch=x86_64"},
+		{"name": "glibc", "version": "2.34-100", "purl": "pkg:rpm/redhat/glibc@2.34-100?arch=i686"},
+		{"name": "kernel-headers", "version": "5.14.0-1", "purl": "pkg:rpm/redhat/kernel-headers@5.14.0-1?arch=x86_64"},
+		{"name": "kernel-headers", "version": "5.14.0-2", "purl": "pkg:rpm/redhat/kernel-headers@5.14.0-2?arch=x86_64"}
+	]}`))
+	assert.NoError(t, err)
+	target, err := UnmarshalSbom([]byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": [
+		{"name": "glibc", "version": "2.34-101", "purl": "pkg:rpm/redhat/glibc@2.34-101?arch=x86_64"},
+		{"name": "glibc", "version": "2.34-100", "purl": "pkg:rpm/redhat/glibc@2.34-100?arch=i686"},
+		{"name": "kernel-headers", "version": "5.14.0-2", "purl": "pkg:rpm/redhat/kernel-headers@5.14.0-2?arch=x86_64"},
+		{"name": "kernel-headers", "version": "5.14.0-3", "purl": "pkg:rpm/redhat/kernel-headers@5.14.0-3?arch=x86_64"}
+	]}`))
+	assert.NoError(t, err)
+
+	diff := DiffSboms(base, target)
+	assert.Equal(t, []string{"pkg:rpm/redhat/kernel-headers?arch=x86_64@5.14.0-3"}, diff.Added)
+	assert.Equal(t, []string{"pkg:rpm/redhat/kernel-headers?arch=x86_64@5.14.0-1"}, diff.Removed)
+	assert.Equal(t, []SbomPackageChange{{Key: "pkg:rpm/redhat/glibc?arch=x86_64", BaseVersion: "2.34-100", TargetVersion: "2.34-101"}}, diff.Changed)
+}
+
+func TestCompareSbomWithInstalledRpms(t *testing.T) {
+	rpms := ParseRpmQueryOutput("openssl\t3.0.7\tx86_64\ngpg-pubkey\t1-1\t(none)\nbash\t5.1.8-6.el9\tx86_64\nopenssl\t3.0.7\ti686\n\n")
+	assert.Equal(t, []InstalledRpm{
+		{Name: "openssl", Version: "3.0.7", Arch: "x86_64"},
+		{Name: "bash", Version: "5.1.8-6.el9", Arch: "x86_64"},
+		{Name: "openssl", Version: "3.0.7", Arch: "i686"},
+	}, rpms)
+
+	sbom, err := UnmarshalSbom([]byte(validCyclonedx))
+	assert.NoError(t, err)
+	diff := CompareSbomWithInstalledRpms(sbom, rpms)
+	assert.Empty(t, diff.Added)
+	// the i686 openssl isn't collapsed with the x86_64 one listed in the SBOM
+	assert.Equal(t, []string{"bash.x86_64", "openssl.i686"}, diff.Removed)
+	assert.Empty(t, diff.Changed)
+}
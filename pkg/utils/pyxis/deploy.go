@@ -0,0 +1,134 @@
+package pyxis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// DeploymentName is the name used for the Deployment, Service and Secret of the in-cluster stand-in
+	DeploymentName = "pyxis-stand-in"
+
+	// DefaultImage contains the pyxis-stub binary, see the Dockerfile in the root of the repository
+	DefaultImage = "quay.io/redhat-user-workloads/konflux-qe-team-tenant/konflux-e2e/konflux-e2e-tests:latest"
+
+	containerPort = 8443
+	certsMountDir = "/etc/pyxis-stand-in"
+)
+
+// Deploy creates the Pyxis stand-in in the given namespace and waits for it to become available.
+// When certs is not nil the stand-in is served over mTLS using them. It returns the base URL of the Service
+// of the stand-in, which is only reachable from within the cluster.
+func Deploy(ki kubernetes.Interface, namespace string, certs *Certificates) (string, error) {
+	ctx := context.Background()
+	labels := map[string]string{"app": DeploymentName}
+	image := utils.GetEnv(constants.PYXIS_STAND_IN_IMAGE_ENV, DefaultImage)
+
+	args := []string{fmt.Sprintf("--addr=:%d", containerPort)}
+	container := corev1.Container{
+		Name:    DeploymentName,
+		Image:   image,
+		Command: []string{"/konflux-e2e/pyxis-stub"},
+		Ports:   []corev1.ContainerPort{{ContainerPort: containerPort}},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(containerPort)},
+			},
+		},
+	}
+	podSpec := corev1.PodSpec{}
+	scheme := "http"
+
+	if certs != nil {
+		scheme = "https"
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: DeploymentName, Namespace: namespace, Labels: labels},
+			Data: map[string][]byte{
+				"ca.crt":  certs.CACert,
+				"tls.crt": certs.ServerCert,
+				"tls.key": certs.ServerKey,
+			},
+		}
+		if _, err := ki.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil && !k8sErrors.IsAlreadyExists(err) {
+			return "", fmt.Errorf("failed to create secret %s/%s: %w", namespace, DeploymentName, err)
+		}
+		args = append(args,
+			"--tls-cert="+certsMountDir+"/tls.crt",
+			"--tls-key="+certsMountDir+"/tls.key",
+			"--client-ca="+certsMountDir+"/ca.crt",
+		)
+		container.VolumeMounts = []corev1.VolumeMount{{Name: "certs", MountPath: certsMountDir, ReadOnly: true}}
+		podSpec.Volumes = []corev1.Volume{{
+			Name:         "certs",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: DeploymentName}},
+		}}
+	}
+	container.Args = args
+	podSpec.Containers = []corev1.Container{container}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: DeploymentName, Namespace: namespace, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(int32(1)),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       podSpec,
+			},
+		},
+	}
+	if _, err := ki.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{}); err != nil && !k8sErrors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("failed to create deployment %s/%s: %w", namespace, DeploymentName, err)
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: DeploymentName, Namespace: namespace, Labels: labels},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports:    []corev1.ServicePort{{Port: containerPort, TargetPort: intstr.FromInt32(containerPort)}},
+		},
+	}
+	if _, err := ki.CoreV1().Services(namespace).Create(ctx, service, metav1.CreateOptions{}); err != nil && !k8sErrors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("failed to create service %s/%s: %w", namespace, DeploymentName, err)
+	}
+
+	err := wait.PollUntilContextTimeout(ctx, 5*time.Second, 5*time.Minute, true, func(ctx context.Context) (bool, error) {
+		d, err := ki.AppsV1().Deployments(namespace).Get(ctx, DeploymentName, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		return d.Status.AvailableReplicas > 0, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("deployment %s/%s didn't become available: %w", namespace, DeploymentName, err)
+	}
+
+	return fmt.Sprintf("%s://%s.%s.svc:%d", scheme, DeploymentName, namespace, containerPort), nil
+}
+
+// Undeploy removes the Pyxis stand-in from the given namespace.
+func Undeploy(ki kubernetes.Interface, namespace string) error {
+	ctx := context.Background()
+	if err := ki.AppsV1().Deployments(namespace).Delete(ctx, DeploymentName, metav1.DeleteOptions{}); err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+	if err := ki.CoreV1().Services(namespace).Delete(ctx, DeploymentName, metav1.DeleteOptions{}); err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+	if err := ki.CoreV1().Secrets(namespace).Delete(ctx, DeploymentName, metav1.DeleteOptions{}); err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,418 @@
+/*
+Package pyxis provides a local stand-in for the Pyxis API used by the release pipelines.
+
+Only the subset of endpoints the release-service-catalog tasks and the e2e tests rely on is
+implemented: container images, content manifests and their SBOM components. All data is kept
+in memory, so the server can be started in-process (see Server.Start) or deployed into the
+test cluster (see Deploy).
+
+The release pipelines only accept the named Pyxis environments (production, stage and their
+-internal variants), so they can't be pointed at the stand-in: it serves the Pyxis clients of the
+e2e tests, seeded with the images they expect, without Pyxis stage.
+*/
+
+package pyxis
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ImagesPath           = "/v1/images"
+	ContentManifestsPath = "/v1/content-manifests"
+
+	defaultPageSize = 100
+)
+
+// Options configures the Pyxis stand-in.
+// When ServerCert and ServerKey are set the server is served over TLS, additionally setting ClientCA
+// enables mTLS the same way the real Pyxis stage endpoint requires a client certificate.
+type Options struct {
+	ServerCert []byte
+	ServerKey  []byte
+	ClientCA   []byte
+}
+
+// Server is an in-memory implementation of the Pyxis API.
+type Server struct {
+	options Options
+
+	mu               sync.RWMutex
+	images           map[string]map[string]any
+	contentManifests map[string]map[string]any
+	components       map[string][]map[string]any
+
+	httpServer *http.Server
+	listener   net.Listener
+}
+
+// NewServer returns a new Pyxis stand-in configured with the given options.
+func NewServer(options Options) *Server {
+	return &Server{
+		options:          options,
+		images:           map[string]map[string]any{},
+		contentManifests: map[string]map[string]any{},
+		components:       map[string][]map[string]any{},
+	}
+}
+
+// Handler returns the http.Handler serving the Pyxis API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ping", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET "+ImagesPath, s.listImages)
+	mux.HandleFunc("POST "+ImagesPath, s.createImage)
+	mux.HandleFunc("GET "+ImagesPath+"/id/{id}", s.getImage)
+	mux.HandleFunc("PATCH "+ImagesPath+"/id/{id}", s.updateImage)
+	mux.HandleFunc("POST "+ContentManifestsPath, s.createContentManifest)
+	mux.HandleFunc("GET "+ContentManifestsPath+"/id/{id}", s.getContentManifest)
+	mux.HandleFunc("GET "+ContentManifestsPath+"/id/{id}/components", s.listComponents)
+	mux.HandleFunc("POST "+ContentManifestsPath+"/id/{id}/components", s.addComponents)
+	return mux
+}
+
+// TLSConfig returns the TLS configuration of the server, or nil when it should be served over plain HTTP.
+func (s *Server) TLSConfig() (*tls.Config, error) {
+	if len(s.options.ServerCert) == 0 || len(s.options.ServerKey) == 0 {
+		return nil, nil
+	}
+	cert, err := tls.X509KeyPair(s.options.ServerCert, s.options.ServerKey)
+	if err != nil {
+		return nil, fmt.Errorf("error creating TLS certificate and key: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if len(s.options.ClientCA) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(s.options.ClientCA) {
+			return nil, fmt.Errorf("failed to parse client CA certificate")
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// Start starts serving the Pyxis API on the given address (e.g. "127.0.0.1:0") in the background.
+func (s *Server) Start(addr string) error {
+	tlsConfig, err := s.TLSConfig()
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	s.listener = listener
+	s.httpServer = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		_ = s.httpServer.Serve(listener)
+	}()
+	return nil
+}
+
+// URL returns the base URL of a started server.
+func (s *Server) URL() string {
+	if s.listener == nil {
+		return ""
+	}
+	scheme := "http"
+	if len(s.options.ServerCert) > 0 {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, s.listener.Addr().String())
+}
+
+// ImagesAPIEndpoint returns the endpoint accepted by ReleaseController.GetPyxisImageByImageID.
+func (s *Server) ImagesAPIEndpoint() string {
+	return s.URL() + ImagesPath + "/id/"
+}
+
+// Close stops a started server.
+func (s *Server) Close() error {
+	if s.httpServer == nil {
+		return nil
+	}
+	return s.httpServer.Close()
+}
+
+// AddImage stores the given image and returns its ID.
+func (s *Server) AddImage(image map[string]any) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return addObject(s.images, image, "containerImage")
+}
+
+// AddContentManifest stores the given content manifest and returns its ID.
+func (s *Server) AddContentManifest(manifest map[string]any) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return addObject(s.contentManifests, manifest, "contentManifest")
+}
+
+// AddComponents appends SBOM components to the content manifest with the given ID.
+func (s *Server) AddComponents(manifestID string, components ...map[string]any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.contentManifests[manifestID]; !ok {
+		return fmt.Errorf("content manifest %s not found", manifestID)
+	}
+	for _, c := range components {
+		if _, ok := c["_id"]; !ok {
+			c["_id"] = newObjectID()
+		}
+		s.components[manifestID] = append(s.components[manifestID], c)
+	}
+	return nil
+}
+
+// Images returns all stored images ordered by their creation date.
+func (s *Server) Images() []map[string]any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sortedObjects(s.images)
+}
+
+func addObject(store map[string]map[string]any, object map[string]any, objectType string) string {
+	id, _ := object["_id"].(string)
+	if id == "" {
+		id = newObjectID()
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	object["_id"] = id
+	object["object_type"] = objectType
+	if _, ok := object["creation_date"]; !ok {
+		object["creation_date"] = now
+	}
+	object["last_update_date"] = now
+	store[id] = object
+	return id
+}
+
+// newObjectID returns a random ID in the same format as the MongoDB ObjectIDs used by Pyxis.
+func newObjectID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func sortedObjects(store map[string]map[string]any) []map[string]any {
+	ret := make([]map[string]any, 0, len(store))
+	for _, o := range store {
+		ret = append(ret, o)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return fmt.Sprint(ret[i]["creation_date"], ret[i]["_id"]) < fmt.Sprint(ret[j]["creation_date"], ret[j]["_id"])
+	})
+	return ret
+}
+
+func (s *Server) listImages(w http.ResponseWriter, r *http.Request) {
+	filters, err := parseFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matched := []map[string]any{}
+	for _, image := range sortedObjects(s.images) {
+		if matchesFilter(image, filters) {
+			matched = append(matched, image)
+		}
+	}
+	writePage(w, r, matched)
+}
+
+func (s *Server) createImage(w http.ResponseWriter, r *http.Request) {
+	image := map[string]any{}
+	if err := json.NewDecoder(r.Body).Decode(&image); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid image: %v", err))
+		return
+	}
+	id := s.AddImage(image)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	writeJSON(w, http.StatusCreated, s.images[id])
+}
+
+func (s *Server) getImage(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	image, ok := s.images[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "image not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, image)
+}
+
+func (s *Server) updateImage(w http.ResponseWriter, r *http.Request) {
+	patch := map[string]any{}
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid image: %v", err))
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	image, ok := s.images[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "image not found")
+		return
+	}
+	for k, v := range patch {
+		if k != "_id" {
+			image[k] = v
+		}
+	}
+	image["last_update_date"] = time.Now().UTC().Format(time.RFC3339)
+	writeJSON(w, http.StatusOK, image)
+}
+
+func (s *Server) createContentManifest(w http.ResponseWriter, r *http.Request) {
+	manifest := map[string]any{}
+	if err := json.NewDecoder(r.Body).Decode(&manifest); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid content manifest: %v", err))
+		return
+	}
+	id := s.AddContentManifest(manifest)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	writeJSON(w, http.StatusCreated, s.contentManifests[id])
+}
+
+func (s *Server) getContentManifest(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	manifest, ok := s.contentManifests[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "content manifest not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, manifest)
+}
+
+func (s *Server) listComponents(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.contentManifests[r.PathValue("id")]
+	components := append([]map[string]any{}, s.components[r.PathValue("id")]...)
+	if !ok {
+		writeError(w, http.StatusNotFound, "content manifest not found")
+		return
+	}
+	writePage(w, r, components)
+}
+
+func (s *Server) addComponents(w http.ResponseWriter, r *http.Request) {
+	components := []map[string]any{}
+	if err := json.NewDecoder(r.Body).Decode(&components); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid components: %v", err))
+		return
+	}
+	if err := s.AddComponents(r.PathValue("id"), components...); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, components)
+}
+
+// parseFilter parses the subset of the Pyxis RSQL filter syntax used by the release tasks,
+// i.e. equality clauses joined by ";" or ";and;" such as "docker_image_digest==sha256:abc;architecture==amd64".
+func parseFilter(filter string) (map[string]string, error) {
+	filters := map[string]string{}
+	if filter == "" {
+		return filters, nil
+	}
+	for _, clause := range strings.Split(strings.ReplaceAll(filter, ";and;", ";"), ";") {
+		if clause == "" {
+			continue
+		}
+		key, value, found := strings.Cut(clause, "==")
+		if !found {
+			return nil, fmt.Errorf("unsupported filter clause '%s'", clause)
+		}
+		filters[key] = value
+	}
+	return filters, nil
+}
+
+// matchesFilter checks the filters against the object, nested fields are addressed with dots
+// and lists match when any of their items matches, e.g. "repositories.repository==foo/bar".
+func matchesFilter(object map[string]any, filters map[string]string) bool {
+	for key, value := range filters {
+		if !fieldMatches(object, strings.Split(key, "."), value) {
+			return false
+		}
+	}
+	return true
+}
+
+func fieldMatches(value any, path []string, expected string) bool {
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			if fieldMatches(item, path, expected) {
+				return true
+			}
+		}
+		return false
+	case map[string]any:
+		if len(path) == 0 {
+			return false
+		}
+		return fieldMatches(v[path[0]], path[1:], expected)
+	default:
+		return len(path) == 0 && fmt.Sprint(v) == expected
+	}
+}
+
+func writePage(w http.ResponseWriter, r *http.Request, data []map[string]any) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if err != nil || pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	start := min(page*pageSize, len(data))
+	end := min(start+pageSize, len(data))
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data":      data[start:end],
+		"page":      page,
+		"page_size": pageSize,
+		"total":     len(data),
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, detail string) {
+	writeJSON(w, status, map[string]any{"status": status, "title": http.StatusText(status), "detail": detail})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,109 @@
+package pyxis
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/konflux-ci/e2e-tests/pkg/clients/release"
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImagesEndpoints(t *testing.T) {
+	server := NewServer(Options{})
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	body, _ := json.Marshal(map[string]any{
+		"docker_image_digest": "sha256:abc",
+		"architecture":        "amd64",
+		"repositories":        []map[string]any{{"repository": "rhtap/konflux-release-e2e"}},
+	})
+	resp, err := http.Post(ts.URL+ImagesPath, "application/json", bytes.NewReader(body))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	created := map[string]any{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	assert.NotEmpty(t, created["_id"])
+
+	resp, err = http.Get(ts.URL + ImagesPath + "/id/" + created["_id"].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(ts.URL + ImagesPath + "/id/missing")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	for filter, expected := range map[string]int{
+		"docker_image_digest==sha256:abc;and;architecture==amd64": 1,
+		"repositories.repository==rhtap/konflux-release-e2e":      1,
+		"docker_image_digest==sha256:def":                         0,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+ImagesPath, nil)
+		q := req.URL.Query()
+		q.Set("filter", filter)
+		req.URL.RawQuery = q.Encode()
+		resp, err = http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		page := struct {
+			Data  []map[string]any `json:"data"`
+			Total int              `json:"total"`
+		}{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		assert.Equal(t, expected, page.Total, filter)
+	}
+}
+
+func TestContentManifestComponents(t *testing.T) {
+	server := NewServer(Options{})
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	manifestID := server.AddContentManifest(map[string]any{"image": "quay.io/test/image"})
+
+	body, _ := json.Marshal([]map[string]any{{"name": "openssl", "purl": "pkg:rpm/redhat/openssl@3.0.7"}})
+	resp, err := http.Post(ts.URL+ContentManifestsPath+"/id/"+manifestID+"/components", "application/json", bytes.NewReader(body))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, err = http.Get(ts.URL + ContentManifestsPath + "/id/" + manifestID + "/components")
+	assert.NoError(t, err)
+	page := struct {
+		Data []map[string]any `json:"data"`
+	}{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, "openssl", page.Data[0]["name"])
+
+	resp, err = http.Get(ts.URL + ContentManifestsPath + "/id/missing/components")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestGetPyxisImageByImageIDWithMTLS(t *testing.T) {
+	certs, err := GenerateCertificates()
+	assert.NoError(t, err)
+
+	server := NewServer(certs.ServerOptions())
+	assert.NoError(t, server.Start("127.0.0.1:0"))
+	defer server.Close()
+
+	imageID := server.AddImage(map[string]any{"image_id": "sha256:abc", "architecture": "amd64"})
+	t.Setenv(constants.PYXIS_STAND_IN_CA_CERT_ENV, base64.StdEncoding.EncodeToString(certs.CACert))
+
+	rc := &release.ReleaseController{}
+	body, err := rc.GetPyxisImageByImageID(server.ImagesAPIEndpoint(), imageID, certs.ClientCert, certs.ClientKey)
+	assert.NoError(t, err)
+	image := release.Image{}
+	assert.NoError(t, json.Unmarshal(body, &image))
+	assert.Equal(t, imageID, image.ID)
+	assert.Equal(t, "amd64", image.Architecture)
+
+	// The client certificate is required
+	_, err = rc.GetPyxisImageByImageID(server.ImagesAPIEndpoint(), imageID, nil, nil)
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -663,26 +664,7 @@ var _ = framework.BuildSuiteDescribe("Build templates E2E test", ginkgo.Label("b
 							ginkgo.Skip("Hermetic build is not enabled, skipping the test")
 						}
 
-						pr, err := f.AsKubeAdmin.HasController.GetComponentPipelineRun(componentName, applicationName, testNamespace, "")
-						gomega.Expect(err).ShouldNot(gomega.HaveOccurred())
-						taskRun, err := f.AsKubeAdmin.TektonController.GetTaskRunFromPipelineRun(f.AsKubeAdmin.CommonController.KubeRest(), pr, "build-container")
-						gomega.Expect(err).NotTo(gomega.HaveOccurred())
-
-						var sbomBlobUrl string
-
-						for _, r := range taskRun.Status.Results {
-							if r.Name == "SBOM_BLOB_URL" {
-								sbomBlobUrl = r.Value.StringVal
-							}
-						}
-						gomega.Expect(sbomBlobUrl).NotTo(gomega.BeEmpty())
-
-						imageRef, err := reference.Parse(sbomBlobUrl)
-						gomega.Expect(err).NotTo(gomega.HaveOccurred())
-
-						c := ociregistry.NewOciRegistryV2Client(imageRef.Registry)
-
-						sbom, err := build.FetchSbomFromRegistry(c, imageRef.Namespace, imageRef.Name, imageRef.ID)
+						sbom, err := getComponentSbom(f.AsKubeAdmin, componentName, applicationName, testNamespace)
 						gomega.Expect(err).NotTo(gomega.HaveOccurred())
 
 						hasHermetoPackages := false
@@ -694,6 +676,31 @@ var _ = framework.BuildSuiteDescribe("Build templates E2E test", ginkgo.Label("b
 						}
 						gomega.Expect(hasHermetoPackages).To(gomega.BeTrue(), "no hermeto packages found")
 					})
+
+					ginkgo.It("should have a valid SBOM matching the built image", ginkgo.Label(buildTemplatesTestLabel), func() {
+						sbom, err := getComponentSbom(f.AsKubeAdmin, componentName, applicationName, testNamespace)
+						gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+						gomega.Expect(build.ValidateSbomDocument(sbom)).To(gomega.Succeed())
+						gomega.Expect(build.ValidateSbomLayers(sbom, imageWithDigest)).To(gomega.Succeed())
+					})
+
+					ginkgo.It("should list the RPMs installed in the built image in the SBOM", ginkgo.Label(buildTemplatesTestLabel), func() {
+						// the RPM database of the image is queried with the rpm binary of the host
+						if _, err := exec.LookPath("rpm"); err != nil {
+							ginkgo.Skip("the rpm binary is not available, skipping the test")
+						}
+
+						sbom, err := getComponentSbom(f.AsKubeAdmin, componentName, applicationName, testNamespace)
+						gomega.Expect(err).NotTo(gomega.HaveOccurred())
+						rpms, err := build.GetInstalledRpms(imageWithDigest)
+						gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+						// the SBOM can list more RPMs than installed, i.e. the prefetched ones, but all the installed ones must be listed
+						diff := build.CompareSbomWithInstalledRpms(sbom, rpms)
+						gomega.Expect(diff.Removed).To(gomega.BeEmpty(), "installed RPMs missing in the SBOM:\n%s", diff)
+						gomega.Expect(diff.Changed).To(gomega.BeEmpty(), "RPMs listed with a different version in the SBOM:\n%s", diff)
+					})
 				})
 
 				// Skipping due to https://redhat.atlassian.net/browse/KONFLUX-12708
@@ -851,6 +858,35 @@ func getImageWithDigest(c *framework.ControllerHub, componentName, applicationNa
 	return fmt.Sprintf("%s@%s", url, digest), nil
 }
 
+// getComponentSbom fetches the SBOM of the image built by the build-container task of the component's PipelineRun.
+func getComponentSbom(c *framework.ControllerHub, componentName, applicationName, namespace string) (build.Sbom, error) {
+	pr, err := c.HasController.GetComponentPipelineRun(componentName, applicationName, namespace, "")
+	if err != nil {
+		return nil, err
+	}
+	taskRun, err := c.TektonController.GetTaskRunFromPipelineRun(c.CommonController.KubeRest(), pr, "build-container")
+	if err != nil {
+		return nil, err
+	}
+
+	var sbomBlobUrl string
+	for _, r := range taskRun.Status.Results {
+		if r.Name == "SBOM_BLOB_URL" {
+			sbomBlobUrl = r.Value.StringVal
+		}
+	}
+	if sbomBlobUrl == "" {
+		return nil, fmt.Errorf("SBOM_BLOB_URL result not found in TaskRun %s/%s", taskRun.GetNamespace(), taskRun.GetName())
+	}
+
+	imageRef, err := reference.Parse(sbomBlobUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return build.FetchSbomFromRegistry(ociregistry.NewOciRegistryV2Client(imageRef.Registry), imageRef.Namespace, imageRef.Name, imageRef.ID)
+}
+
 // this function takes a bundle and prefetchInput value as inputs and creates a bundle with param hermetic=true
 // and then push the bundle to quay using format: quay.io/<QUAY_E2E_ORGANIZATION>/test-images:<generated_tag>
 func enableHermeticBuildInPipelineBundle(customDockerBuildBundle string, pipelineBundleName constants.BuildPipelineType, prefetchInput string) (string, error) {
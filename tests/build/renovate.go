@@ -3,7 +3,6 @@ package build
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/devfile/library/v2/pkg/util"
@@ -80,10 +79,10 @@ var _ = framework.BuildSuiteDescribe("Build service E2E tests", Label("build-ser
 				branchString := util.GenerateRandomString(4)
 				ParentComponentDef.componentBranch = fmt.Sprintf("multi-component-parent-base-%s", branchString)
 				ChildComponentDef.componentBranch = fmt.Sprintf("multi-component-child-base-%s", branchString)
+				gitClient, err = f.AsKubeAdmin.HasController.GitClient(gitProvider)
+				Expect(err).ShouldNot(HaveOccurred())
 				switch gitProvider {
 				case git.GitHubProvider:
-					gitClient = git.NewGitHubClient(f.AsKubeAdmin.CommonController.Github)
-
 					ParentComponentDef.gitRepo = fmt.Sprintf(githubUrlFormat, githubOrg, ParentComponentDef.repoName)
 					parentRepository = ParentComponentDef.repoName
 
@@ -100,8 +99,6 @@ var _ = framework.BuildSuiteDescribe("Build service E2E tests", Label("build-ser
 					Expect(err).ShouldNot(HaveOccurred())
 
 				case git.GitLabProvider:
-					gitClient = git.NewGitlabClient(f.AsKubeAdmin.CommonController.Gitlab)
-
 					parentRepository = fmt.Sprintf("%s/%s", gitlabOrg, ParentComponentDef.repoName)
 					ParentComponentDef.gitRepo = fmt.Sprintf(gitlabUrlFormat, parentRepository)
 
@@ -118,8 +115,6 @@ var _ = framework.BuildSuiteDescribe("Build service E2E tests", Label("build-ser
 					componentDependenciesChildRepository = childRepository
 
 				case git.ForgejoProvider:
-					gitClient = git.NewForgejoClient(f.AsKubeAdmin.CommonController.Forgejo)
-
 					parentRepository = fmt.Sprintf("%s/%s", forgejoOrg, ParentComponentDef.repoName)
 					ParentComponentDef.gitRepo = fmt.Sprintf(forgejoUrlFormat, parentRepository)
 
@@ -247,13 +242,12 @@ var _ = framework.BuildSuiteDescribe("Build service E2E tests", Label("build-ser
 								},
 							},
 						}
-						//make the parent repo nudge the child repo
-						if comp.repoName == targetParentRepoName {
-							componentObj.BuildNudgesRef = []string{ChildComponentDef.componentName}
-						}
 						comp.component, err = f.AsKubeAdmin.HasController.CreateComponentCheckImageRepository(componentObj, testNamespace, "", "", applicationName, true, utils.MergeMaps(utils.MergeMaps(utils.MergeMaps(constants.ComponentPaCRequestAnnotation, constants.ImageControllerAnnotationRequestPublicRepo), buildPipelineAnnotation), gitProviderAnnotation))
 						Expect(err).ShouldNot(HaveOccurred())
 					}
+					//make the parent repo nudge the child repo
+					Expect(f.AsKubeAdmin.HasController.AddComponentNudges(ParentComponentDef.componentName, testNamespace, ChildComponentDef.componentName)).To(Succeed())
+					Expect(f.AsKubeAdmin.HasController.WaitForComponentNudgedBy(ChildComponentDef.componentName, ParentComponentDef.componentName, testNamespace, 2*time.Minute)).To(Succeed())
 				})
 				// Initial pipeline run, we need this so we have an initial image that we can then update
 				It(fmt.Sprintf("triggers a PipelineRun for parent component %s", ParentComponentDef.componentName), func() {
@@ -410,21 +404,13 @@ var _ = framework.BuildSuiteDescribe("Build service E2E tests", Label("build-ser
 					Expect(parentPostPacMergeDigest).ShouldNot(BeEmpty())
 				})
 				It(fmt.Sprintf("should lead to a nudge PR creation for child component %s", ChildComponentDef.componentName), func() {
-					timeout = time.Minute * 20
-					interval := time.Second * 10
-
-					Eventually(func() bool {
-						prs, err := git.ListPullRequestsWithRetry(gitClient, componentDependenciesChildRepository)
-						Expect(err).ShouldNot(HaveOccurred())
-
-						for _, pr := range prs {
-							if strings.Contains(pr.SourceBranch, ParentComponentDef.componentName) {
-								prNumber = pr.Number
-								return true
-							}
-						}
-						return false
-					}, timeout, interval).Should(BeTrue(), fmt.Sprintf("timed out when waiting for component nudge PR to be created in %s repository", targetChildRepoName))
+					nudgedFiles := map[string]string{
+						"Dockerfile.tmp": parentImageNameWithNoDigest,
+						"manifest.yaml":  distributionRepository,
+					}
+					nudgePR, err := f.AsKubeAdmin.HasController.WaitForNudgePullRequest(gitClient, componentDependenciesChildRepository, ParentComponentDef.componentName, parentPostPacMergeDigest, nudgedFiles, 20*time.Minute)
+					Expect(err).ShouldNot(HaveOccurred(), fmt.Sprintf("timed out when waiting for component nudge PR to be created in %s repository", targetChildRepoName))
+					prNumber = nudgePR.Number
 				})
 				It(fmt.Sprintf("merging the PR should be successful for child component %s", ChildComponentDef.componentName), func() {
 					Eventually(func() error {
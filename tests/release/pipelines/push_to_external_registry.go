@@ -173,16 +173,7 @@ var _ = framework.ReleasePipelinesSuiteDescribe("Push to external registry", gin
 		})
 
 		ginkgo.It("verifies that a Release is marked as succeeded.", func() {
-			gomega.Eventually(func() error {
-				releaseCR, err = fw.AsKubeAdmin.ReleaseController.GetFirstReleaseInNamespace(devNamespace)
-				if err != nil {
-					return err
-				}
-				if !releaseCR.IsReleased() {
-					return fmt.Errorf("release %s/%s is not marked as finished yet", releaseCR.GetNamespace(), releaseCR.GetName())
-				}
-				return nil
-			}, releasecommon.ReleaseCreationTimeout, releasecommon.DefaultInterval).Should(gomega.Succeed())
+			gomega.Expect(releasecommon.WaitForReleaseToSucceed(fw.AsKubeAdmin, releaseCR, releasecommon.ReleaseCreationTimeout)).To(gomega.Succeed())
 		})
 	})
 })
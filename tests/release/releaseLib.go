@@ -47,9 +47,17 @@ func NewFramework(workspace string) *framework.Framework {
 		ticker.Stop()
 		fmt.Println("Stopped executing every 3 minutes.")
 	})
+	// a pooled user is kept by renewing its lease, creating the framework again would lease it from a goroutine
+	pooled := fw.UserLease != nil
 	// Run a goroutine to handle the ticker ticks
 	go func() {
 		for range ticker.C {
+			if pooled {
+				if err := fw.RenewUser(); err != nil {
+					ginkgo.GinkgoWriter.Printf("failed to renew the lease of the sandbox user %s: %v\n", fw.UserName, err)
+				}
+				continue
+			}
 			fw, err = framework.NewFrameworkWithTimeout(
 				workspace,
 				time.Minute*60,
@@ -282,6 +290,27 @@ func CheckReleaseStatus(releaseCR *releaseApi.Release) error {
 	return nil
 }
 
+// WaitForReleaseToSucceed follows the Release through its phases until it finishes. When the Release doesn't succeed,
+// its phases, the logs of its failed PipelineRuns and its ReleasePlanAdmission are stored as artifacts.
+func WaitForReleaseToSucceed(fw *framework.ControllerHub, releaseCR *releaseApi.Release, timeout time.Duration) error {
+	result, err := fw.ReleaseController.TrackRelease(releaseCR.GetName(), releaseCR.GetNamespace(), timeout)
+	if result == nil {
+		return err
+	}
+	if !result.Succeeded {
+		if storeErr := fw.ReleaseController.StoreReleaseTrackingResult(result); storeErr != nil {
+			ginkgo.GinkgoWriter.Printf("failed to store the tracking result of Release %s/%s: %v\n", releaseCR.GetNamespace(), releaseCR.GetName(), storeErr)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !result.Succeeded {
+		return fmt.Errorf("release %s/%s failed in phase %s:\n%s", releaseCR.GetNamespace(), releaseCR.GetName(), result.FailedPhase, result)
+	}
+	return nil
+}
+
 // CreateOpaqueSecret creates a k8s Secret in a workspace if it doesn't exist
 // and updates it if a Secret with the same name exists. It populates the
 // Secret data fields based on the mapping of fields to environment variables